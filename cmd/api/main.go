@@ -11,10 +11,12 @@ import (
 
 	"github.com/baharkarakas/insider-backend/internal/api"
 	"github.com/baharkarakas/insider-backend/internal/config"
+	"github.com/baharkarakas/insider-backend/internal/currency"
 	"github.com/baharkarakas/insider-backend/internal/db"
 	"github.com/baharkarakas/insider-backend/internal/logger"
 	"github.com/baharkarakas/insider-backend/internal/metrics"
 	"github.com/baharkarakas/insider-backend/internal/repository/postgres"
+	"github.com/baharkarakas/insider-backend/internal/risk"
 	"github.com/baharkarakas/insider-backend/internal/services"
 	"github.com/baharkarakas/insider-backend/internal/worker"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,6 +29,10 @@ func main() {
 	cfg := config.Load()
 	log := logger.New(cfg.Env)
 	slog.SetDefault(log)
+	if _, err := currency.Lookup(cfg.DefaultCurrency); err != nil {
+		log.Error("config", "DEFAULT_CURRENCY", cfg.DefaultCurrency, "err", err)
+		os.Exit(1)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -51,23 +57,72 @@ if os.Getenv("APP_MIGRATE") == "true" {
 
 	// cmd/api/main.go 
 repos := postgres.NewRepositories(dbPool)
-wp := worker.NewPool(4)
-defer wp.Stop()
-
-userSvc := services.NewUserService(repos.Users, cfg)
-balanceSvc := services.NewBalanceService(repos.Balances)
+jobs := worker.NewQueue(repos.Jobs, 4)
+
+userSvc := services.NewUserService(repos.Users, repos.AuditLogs, cfg)
+balanceSvc := services.NewBalanceService(repos.Balances, repos.Wallets)
+ledgerSvc := services.NewLedgerService(repos.Ledger)
+idemSvc := services.NewIdempotencyService(repos.Idempotency, cfg.IdempotencyTTL, cfg.IdempotencyLease)
+fxSvc := services.NewFXService(repos.FXRates)
+limitSvc := services.NewLimitService(repos.Limits, repos.Users)
+feeSvc := services.NewFeeService(repos.Fees, repos.Users)
+riskEngine, err := risk.NewEngine(repos.RiskSignals, cfg.RiskRulesFile)
+if err != nil {
+	log.Error("risk rules", "err", err)
+	os.Exit(1)
+}
+go riskEngine.Watch(ctx, cfg.RiskReloadInterval)
 txnSvc := services.NewTransactionService(
     repos.Transactions,
     repos.Balances,
     repos.AuditLogs,
     repos.Users,   
-    wp,
+    repos.Ledger,
+    repos.Holds,
+    repos.FXRates,
+    repos.Conversions,
+    repos.Batches,
+    repos.Wallets,
+    limitSvc,
+    feeSvc,
+    jobs,
+    cfg.ApprovalThreshold,
+    riskEngine,
+    repos.RiskCases,
+    repos.Disputes,
 )
+schedSvc := services.NewScheduleService(repos.Schedules, repos.Users, txnSvc)
+reconSvc := services.NewReconciliationService(repos.Recon, txnSvc)
+stmtSvc := services.NewStatementService(repos.Transactions, repos.Statements)
+creditSvc := services.NewCreditLineService(repos.Balances, repos.Users, txnSvc)
+savingsSvc := services.NewSavingsService(repos.Savings, txnSvc)
+requestSvc := services.NewPaymentRequestService(repos.Requests, repos.Users, txnSvc)
+walletSvc := services.NewWalletService(repos.Wallets)
+orgSvc := services.NewOrgService(repos.Orgs, repos.Users, txnSvc, balanceSvc, repos.AuditLogs)
+jobs.Start(ctx)
+defer jobs.Stop()
+
+go runEvery(ctx, time.Hour, "purge idempotency keys", func() error {
+	_, err := idemSvc.PurgeExpired()
+	return err
+})
+go runEvery(ctx, time.Minute, "expire holds", func() error {
+	_, err := txnSvc.ExpireHolds()
+	return err
+})
+// scheduler: standing orders
+go runEvery(ctx, cfg.SchedulerInterval, "scheduler", schedSvc.RunDue)
+go runEvery(ctx, cfg.ReconcileInterval, "reconcile", func() error {
+	return reconSvc.RunScheduled(cfg.ReconcileAdjust)
+})
+go runEvery(ctx, cfg.StatementInterval, "statements", stmtSvc.CloseMonths)
+go runEvery(ctx, cfg.InterestInterval, "overdraft interest", creditSvc.AccrueInterest)
+go runEvery(ctx, cfg.InterestInterval, "savings interest", savingsSvc.Run)
 
 
 
 	metrics.Init()
-	r := api.NewRouter(cfg, userSvc, balanceSvc, txnSvc)
+	r := api.NewRouter(cfg, userSvc, balanceSvc, txnSvc, ledgerSvc, idemSvc, schedSvc, fxSvc, limitSvc, feeSvc, creditSvc, savingsSvc, requestSvc, riskEngine, walletSvc, orgSvc, reconSvc, stmtSvc)
 
 	srv := &http.Server{
 		Addr:              ":" + cfg.HTTPPort,
@@ -100,3 +155,19 @@ log.Info("env check",
 
 
 }
+
+// runEvery calls fn every d until ctx is cancelled. Errors are logged, not fatal.
+func runEvery(ctx context.Context, d time.Duration, name string, fn func() error) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := fn(); err != nil {
+				slog.Error(name, "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Command reconcile folds settled transactions per (user, currency), compares the
+// result with the stored balances and writes a drift report. With -adjust it also
+// books adjustments that bring drifting balances back in line.
+//
+// Exit status is 0 when nothing drifted, 2 when drift was found, 1 on error.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/baharkarakas/insider-backend/internal/config"
+	"github.com/baharkarakas/insider-backend/internal/db"
+	"github.com/baharkarakas/insider-backend/internal/logger"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/repository/postgres"
+	"github.com/baharkarakas/insider-backend/internal/services"
+	"github.com/baharkarakas/insider-backend/internal/worker"
+)
+
+func main() {
+	adjust := flag.Bool("adjust", false, "book adjustments that correct drifting balances")
+	limit := flag.Int("limit", 1000, "maximum number of drifts to print")
+	flag.Parse()
+
+	cfg := config.Load()
+	log := logger.New(cfg.Env)
+	slog.SetDefault(log)
+
+	ctx := context.Background()
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Error("db connect", "err", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	repos := postgres.NewRepositories(pool)
+	// the queue is never started: reconcile only books completed adjustments
+	jobs := worker.NewQueue(repos.Jobs, 0)
+	limitSvc := services.NewLimitService(repos.Limits, repos.Users)
+	feeSvc := services.NewFeeService(repos.Fees, repos.Users)
+	txnSvc := services.NewTransactionService(repos.Transactions, repos.Balances, repos.AuditLogs, repos.Users,
+		repos.Ledger, repos.Holds, repos.FXRates, repos.Conversions, repos.Batches, repos.Wallets, limitSvc, feeSvc, jobs, cfg.ApprovalThreshold, nil, repos.RiskCases, repos.Disputes)
+	recon := services.NewReconciliationService(repos.Recon, txnSvc)
+
+	run, err := recon.Run(*adjust)
+	if errors.Is(err, repository.ErrRunInProgress) {
+		log.Error("reconcile", "err", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Error("reconcile", "run_id", run.ID, "err", err)
+		os.Exit(1)
+	}
+
+	_, drifts, err := recon.Drifts(run.ID, *limit, 0)
+	if err != nil {
+		log.Error("list drifts", "run_id", run.ID, "err", err)
+		os.Exit(1)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	_ = enc.Encode(run)
+	for _, d := range drifts {
+		_ = enc.Encode(d)
+	}
+	if run.DriftsFound > 0 {
+		os.Exit(2)
+	}
+}
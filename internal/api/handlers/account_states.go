@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// AccountStateHandler serves PUT /api/v1/admin/users/{id}/state. Admin only; enforced by the router.
+type AccountStateHandler struct {
+	Users *services.UserService
+}
+
+func NewAccountStateHandler(us *services.UserService) *AccountStateHandler {
+	return &AccountStateHandler{Users: us}
+}
+
+// Set freezes, closes or reactivates an account; the response is the user.
+func (h *AccountStateHandler) Set(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in models.AccountStateChange
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	u, err := h.Users.SetState(chi.URLParam(r, "id"), uid, in)
+	if errors.Is(err, services.ErrUserNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "account_state_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, u)
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/baharkarakas/insider-backend/internal/auth"
+	"github.com/baharkarakas/insider-backend/internal/models"
 	"github.com/baharkarakas/insider-backend/internal/services"
 )
 
@@ -53,6 +55,12 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid credentials"})
 			return
 		}
+		// frozen accounts may still sign in and see their money; closed ones may not
+		if u.State == models.AccountClosed {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "account closed"})
+			return
+		}
 		access, refresh, exp, err := h.TM.GeneratePair(u.ID, u.Role)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -111,6 +119,24 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid refresh token"})
 		return
 	}
+	// closing an account must also end the sessions it already has; dev tokens
+	// may name users that don't exist
+	u, err := h.Users.GetByID(claims.UserID)
+	switch {
+	case errors.Is(err, services.ErrUserNotFound) && h.AppEnv == "dev":
+	case errors.Is(err, services.ErrUserNotFound):
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid refresh token"})
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "user lookup failed"})
+		return
+	case u.State == models.AccountClosed:
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "account closed"})
+		return
+	}
 	access, refresh, exp, err := h.TM.GeneratePair(claims.UserID, claims.Role)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
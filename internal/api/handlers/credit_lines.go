@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// CreditLineHandler serves PUT /api/v1/admin/users/{id}/credit-line. Admin only; enforced by the router.
+type CreditLineHandler struct {
+	CreditLines     *services.CreditLineService
+	DefaultCurrency string
+}
+
+func NewCreditLineHandler(cs *services.CreditLineService, defaultCurrency string) *CreditLineHandler {
+	return &CreditLineHandler{CreditLines: cs, DefaultCurrency: defaultCurrency}
+}
+
+// Set grants or changes a user's credit line; a credit_limit of 0 withdraws it.
+func (h *CreditLineHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Currency         string `json:"currency"`
+		CreditLimit      int64  `json:"credit_limit"`
+		OverdraftRateBPS int64  `json:"overdraft_rate_bps"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if in.Currency == "" {
+		in.Currency = h.DefaultCurrency
+	}
+	b, err := h.CreditLines.Set(chi.URLParam(r, "id"), in.Currency, in.CreditLimit, in.OverdraftRateBPS)
+	if errors.Is(err, services.ErrUserNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "credit_line_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, b)
+}
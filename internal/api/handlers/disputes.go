@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// DisputeHandler serves /api/v1/disputes, where the two parties of a transfer
+// dispute it, and /api/v1/admin/disputes, where admins resolve disputes.
+type DisputeHandler struct {
+	Txns *services.TransactionService
+	// TxnError writes the error of a failed dispute step, the same way the transfer endpoints do.
+	TxnError func(w http.ResponseWriter, fallback string, err error)
+}
+
+func NewDisputeHandler(ts *services.TransactionService, txnError func(http.ResponseWriter, string, error)) *DisputeHandler {
+	return &DisputeHandler{Txns: ts, TxnError: txnError}
+}
+
+// Routes mounts the parties' endpoints under a protected router.
+func (h *DisputeHandler) Routes(r chi.Router) {
+	r.Post("/", h.Open)
+	r.Get("/", h.List)
+	r.Get("/{id}", h.Get)
+	r.Post("/{id}/respond", h.Respond)
+	r.Post("/{id}/withdraw", h.Withdraw)
+}
+
+// AdminRoutes mounts the admin queue. Admin only; enforced by the router.
+func (h *DisputeHandler) AdminRoutes(r chi.Router) {
+	r.Get("/", h.Queue)
+	r.Get("/{id}", h.AdminGet)
+	r.Post("/{id}/resolve", h.Resolve)
+}
+
+// Open disputes a completed transfer the caller sent.
+func (h *DisputeHandler) Open(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		TransactionID string `json:"transaction_id"`
+		models.DisputeClaim
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if _, err := uuid.Parse(in.TransactionID); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "transaction_id must be a valid UUID", nil)
+		return
+	}
+	if e := validate.Required("reason", in.Reason); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	d, err := h.Txns.OpenDispute(in.TransactionID, uid, in.DisputeClaim)
+	if err != nil {
+		h.TxnError(w, "dispute_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, d)
+}
+
+// List returns the disputes the caller filed or received, newest first.
+func (h *DisputeHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	limit, offset := pageParams(r)
+	out, err := h.Txns.Disputes(uid, limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *DisputeHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	d, err := h.Txns.Dispute(chi.URLParam(r, "id"), uid)
+	if err != nil {
+		h.TxnError(w, "dispute_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, d)
+}
+
+// Respond gives the recipient's side; allowed once, while the dispute is open.
+func (h *DisputeHandler) Respond(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		Response string `json:"response"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if e := validate.Required("response", in.Response); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	d, err := h.Txns.RespondDispute(chi.URLParam(r, "id"), uid, in.Response)
+	if err != nil {
+		h.TxnError(w, "respond_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, d)
+}
+
+// Withdraw drops the sender's dispute before an admin resolves it.
+func (h *DisputeHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	d, err := h.Txns.WithdrawDispute(chi.URLParam(r, "id"), uid)
+	if err != nil {
+		h.TxnError(w, "withdraw_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, d)
+}
+
+// Queue lists disputes oldest first, ?status=active (default: open or responded)
+// | open | responded | withdrawn | charged_back | rejected | all.
+func (h *DisputeHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	var statuses []models.DisputeStatus
+	switch status := r.URL.Query().Get("status"); status {
+	case "", "active":
+		statuses = []models.DisputeStatus{models.DisputeOpen, models.DisputeResponded}
+	case "all":
+	default:
+		if !models.DisputeStatus(status).Valid() {
+			e := validate.OneOf("status", status, "active", string(models.DisputeOpen), string(models.DisputeResponded),
+				string(models.DisputeWithdrawn), string(models.DisputeChargedBack), string(models.DisputeRejected), "all")
+			httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", validate.Errs{*e})
+			return
+		}
+		statuses = []models.DisputeStatus{models.DisputeStatus(status)}
+	}
+	limit, offset := pageParams(r)
+	out, err := h.Txns.DisputeQueue(statuses, limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *DisputeHandler) AdminGet(w http.ResponseWriter, r *http.Request) {
+	d, err := h.Txns.DisputeByID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.TxnError(w, "dispute_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, d)
+}
+
+// Resolve rules for the sender (charge back) or the recipient (release the
+// hold); a note is required.
+func (h *DisputeHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		Outcome string `json:"outcome"`
+		Note    string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	var verr validate.Errs
+	if e := validate.OneOf("outcome", in.Outcome, string(models.RuleForSender), string(models.RuleForRecipient)); e != nil {
+		verr = append(verr, *e)
+	}
+	if e := validate.Required("note", in.Note); e != nil {
+		verr = append(verr, *e)
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+		return
+	}
+	d, err := h.Txns.ResolveDispute(chi.URLParam(r, "id"), uid, models.DisputeOutcome(in.Outcome), in.Note)
+	if err != nil {
+		h.TxnError(w, "resolve_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, d)
+}
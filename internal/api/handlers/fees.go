@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// FeeHandler serves the fee quote and /api/v1/admin/fees. The admin routes are
+// admin only; enforced by the router.
+type FeeHandler struct {
+	Fees            *services.FeeService
+	DefaultCurrency string
+}
+
+func NewFeeHandler(fs *services.FeeService, defaultCurrency string) *FeeHandler {
+	return &FeeHandler{Fees: fs, DefaultCurrency: defaultCurrency}
+}
+
+// Routes mounts the admin rule endpoints.
+func (h *FeeHandler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+}
+
+// Quote serves GET /transactions/fees/quote?type=transfer|debit&amount=&currency=.
+func (h *FeeHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserID(r.Context())
+	if !ok || uid == "" {
+		httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+		return
+	}
+	q := r.URL.Query()
+	var verr validate.Errs
+	typ := q.Get("type")
+	if e := validate.OneOf("type", typ, string(models.TxnTransfer), string(models.TxnDebit)); e != nil {
+		verr = append(verr, *e)
+	}
+	amount, err := strconv.ParseInt(q.Get("amount"), 10, 64)
+	if err != nil {
+		verr = append(verr, validate.ErrField{Field: "amount", Msg: "must be an integer"})
+	} else if e := validate.MinInt("amount", amount, 1); e != nil {
+		verr = append(verr, *e)
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", verr)
+		return
+	}
+	cur := q.Get("currency")
+	if cur == "" {
+		cur = h.DefaultCurrency
+	}
+	out, err := h.Fees.Quote(uid, models.TransactionType(typ), amount, cur)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "quote_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *FeeHandler) List(w http.ResponseWriter, r *http.Request) {
+	out, err := h.Fees.List()
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *FeeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in models.FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Fees.Create(in)
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, out)
+}
+
+// Update replaces the pricing; omitted fields are reset.
+func (h *FeeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var in models.FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Fees.Update(chi.URLParam(r, "id"), in)
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *FeeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.Fees.Delete(chi.URLParam(r, "id")); err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeFeeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrFeeRuleNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	httpx.WriteError(w, http.StatusBadRequest, "fee_rule_failed", err.Error(), nil)
+}
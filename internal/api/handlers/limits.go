@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// LimitHandler serves /api/v1/admin/limits. Admin only; enforced by the router.
+type LimitHandler struct {
+	Limits *services.LimitService
+}
+
+func NewLimitHandler(ls *services.LimitService) *LimitHandler {
+	return &LimitHandler{Limits: ls}
+}
+
+func (h *LimitHandler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+}
+
+func (h *LimitHandler) List(w http.ResponseWriter, r *http.Request) {
+	out, err := h.Limits.List()
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *LimitHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in models.LimitPolicy
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Limits.Create(in)
+	if err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, out)
+}
+
+// Update replaces the limits; omitted fields are removed.
+func (h *LimitHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var in models.LimitPolicy
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Limits.Update(chi.URLParam(r, "id"), in)
+	if err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *LimitHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.Limits.Delete(chi.URLParam(r, "id")); err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetTier serves PUT /admin/users/{id}/tier.
+func (h *LimitHandler) SetTier(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Tier string `json:"tier"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	u, err := h.Limits.SetTier(chi.URLParam(r, "id"), in.Tier)
+	if err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, u)
+}
+
+func writeLimitError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrLimitPolicyNotFound) || errors.Is(err, services.ErrUserNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	httpx.WriteError(w, http.StatusBadRequest, "limit_policy_failed", err.Error(), nil)
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// OrgHandler serves /api/v1/orgs: organizations, their members and money moved
+// on their behalf. Every /{id} route checks the caller's membership and role.
+type OrgHandler struct {
+	Orgs            *services.OrgService
+	DefaultCurrency string
+	// TxnError writes the error of a failed transfer, the same way the transfer endpoints do.
+	TxnError func(w http.ResponseWriter, fallback string, err error)
+	// Idem is the router's Idempotency-Key middleware for an endpoint name.
+	Idem func(endpoint string) func(http.Handler) http.Handler
+	// HistoryFilter reads the /transactions/history filters from a query.
+	HistoryFilter func(q url.Values) (models.TransactionFilter, validate.Errs)
+}
+
+func NewOrgHandler(ogs *services.OrgService, defaultCurrency string,
+	txnError func(http.ResponseWriter, string, error),
+	idem func(string) func(http.Handler) http.Handler,
+	historyFilter func(url.Values) (models.TransactionFilter, validate.Errs)) *OrgHandler {
+	return &OrgHandler{Orgs: ogs, DefaultCurrency: defaultCurrency, TxnError: txnError, Idem: idem, HistoryFilter: historyFilter}
+}
+
+// Routes mounts the handler under a protected router.
+func (h *OrgHandler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Route("/{id}", func(r chi.Router) {
+		r.With(h.require(models.OrgView)).Get("/", h.Get)
+		r.With(h.require(models.OrgView)).Get("/members", h.Members)
+		r.With(h.require(models.OrgManageMembers)).Put("/members/{userID}", h.SetMember)
+		r.With(h.require(models.OrgManageMembers)).Delete("/members/{userID}", h.RemoveMember)
+		r.With(h.require(models.OrgView)).Get("/balances", h.Balance)
+		r.With(h.require(models.OrgView)).Get("/transactions", h.History)
+		r.With(h.require(models.OrgTransfer), h.Idem("orgs.transfer")).Post("/transfers", h.Transfer)
+		r.With(h.require(models.OrgApprove)).Post(`/approvals/{txID:[0-9a-fA-F-]{36}}/approve`, h.Approve)
+		r.With(h.require(models.OrgApprove)).Post(`/approvals/{txID:[0-9a-fA-F-]{36}}/reject`, h.Reject)
+	})
+}
+
+type orgMemberKey struct{}
+
+// require lets the request through if the caller's role in organization {id}
+// allows a, and puts their membership in the context for the handler.
+func (h *OrgHandler) require(a models.OrgAction) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid, ok := middleware.UserID(r.Context())
+			if !ok || uid == "" {
+				httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+				return
+			}
+			orgID := chi.URLParam(r, "id")
+			if _, err := uuid.Parse(orgID); err != nil {
+				httpx.WriteError(w, http.StatusNotFound, "not_found", services.ErrOrgNotFound.Error(), nil)
+				return
+			}
+			m, err := h.Orgs.Authorize(orgID, uid, a)
+			if err != nil {
+				writeOrgError(w, "internal_error", err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), orgMemberKey{}, m)))
+		})
+	}
+}
+
+func member(r *http.Request) models.OrgMember {
+	m, _ := r.Context().Value(orgMemberKey{}).(models.OrgMember)
+	return m
+}
+
+func writeOrgError(w http.ResponseWriter, fallback string, err error) {
+	switch {
+	case errors.Is(err, services.ErrOrgNotFound), errors.Is(err, services.ErrNotAMember),
+		errors.Is(err, services.ErrUserNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+	case errors.Is(err, services.ErrOrgForbidden):
+		httpx.WriteError(w, http.StatusForbidden, "forbidden", err.Error(), nil)
+	case errors.Is(err, repository.ErrDuplicateOrgName):
+		httpx.WriteError(w, http.StatusConflict, "duplicate_organization", err.Error(), nil)
+	case errors.Is(err, repository.ErrLastOwner):
+		httpx.WriteError(w, http.StatusConflict, "last_owner", err.Error(), nil)
+	case fallback == "internal_error":
+		httpx.WriteError(w, http.StatusInternalServerError, fallback, err.Error(), nil)
+	default:
+		httpx.WriteError(w, http.StatusBadRequest, fallback, err.Error(), nil)
+	}
+}
+
+// List returns the organizations the caller belongs to, with their role in each.
+func (h *OrgHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Orgs.Mine(uid)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Create opens an organization with the caller as its owner.
+func (h *OrgHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if e := validate.Required("name", in.Name); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	o, err := h.Orgs.Create(uid, in.Name)
+	if err != nil {
+		writeOrgError(w, "organization_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, o)
+}
+
+func (h *OrgHandler) Get(w http.ResponseWriter, r *http.Request) {
+	o, err := h.Orgs.Get(chi.URLParam(r, "id"), member(r))
+	if err != nil {
+		writeOrgError(w, "internal_error", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, o)
+}
+
+func (h *OrgHandler) Members(w http.ResponseWriter, r *http.Request) {
+	out, err := h.Orgs.Members(chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// SetMember adds a user with a role or changes their role (owners only).
+func (h *OrgHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	if _, err := uuid.Parse(userID); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "userID must be a valid UUID", nil)
+		return
+	}
+	var in struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	roles := []string{string(models.OrgOwner), string(models.OrgApprover), string(models.OrgViewer)}
+	if e := validate.OneOf("role", in.Role, roles...); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	m, err := h.Orgs.SetMember(chi.URLParam(r, "id"), member(r), userID, models.OrgRole(in.Role))
+	if err != nil {
+		writeOrgError(w, "member_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, m)
+}
+
+// RemoveMember takes a user out of the organization (owners only); an owner may
+// remove themselves as long as another owner remains.
+func (h *OrgHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.Orgs.RemoveMember(chi.URLParam(r, "id"), member(r), chi.URLParam(r, "userID")); err != nil {
+		writeOrgError(w, "member_failed", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Balance returns the organization's balance per wallet; ?currency= picks the currency.
+func (h *OrgHandler) Balance(w http.ResponseWriter, r *http.Request) {
+	cur := r.URL.Query().Get("currency")
+	if cur == "" {
+		cur = h.DefaultCurrency
+	}
+	out, err := h.Orgs.Balance(chi.URLParam(r, "id"), cur)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "balance_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// History pages through the organization's transactions, with the same filters
+// and cursor as /transactions/history.
+func (h *OrgHandler) History(w http.ResponseWriter, r *http.Request) {
+	limit := min(httpx.ParseInt(r.URL.Query().Get("limit"), 50, 1), 200)
+	f, verr := h.HistoryFilter(r.URL.Query())
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", verr)
+		return
+	}
+	page, err := h.Orgs.History(chi.URLParam(r, "id"), f, r.URL.Query().Get("cursor"), limit)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_cursor", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, page)
+}
+
+// Transfer sends money from the organization to a user (owners and approvers).
+func (h *OrgHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "id")
+	var in struct {
+		ToUserID   string `json:"to_user_id"`
+		ToWalletID string `json:"to_wallet_id"` // recipient's main wallet if omitted
+		Amount     int64  `json:"amount"`
+		Currency   string `json:"currency"`
+		models.TxnDetails
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if _, err := uuid.Parse(in.ToUserID); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "to_user_id must be a valid UUID", nil)
+		return
+	}
+	if e := validate.MinInt("amount", in.Amount, 1); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	if in.Currency == "" {
+		in.Currency = h.DefaultCurrency
+	}
+	tx, err := h.Orgs.Transfer(orgID, member(r), in.ToUserID, in.ToWalletID, in.Amount, in.Currency, in.TxnDetails)
+	if errors.Is(err, services.ErrRecipientNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "recipient_not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		h.TxnError(w, "transfer_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusAccepted, tx)
+}
+
+// Approve executes a transfer of the organization awaiting approval (owners and
+// approvers, not the member who sent it). The waiting ones are listed by
+// /transactions?status=awaiting_approval.
+func (h *OrgHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	tx, err := h.Orgs.Approve(chi.URLParam(r, "id"), member(r), chi.URLParam(r, "txID"))
+	if err != nil {
+		h.TxnError(w, "approve_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, tx)
+}
+
+// Reject turns down a transfer of the organization awaiting approval (owners and approvers).
+func (h *OrgHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if e := validate.Required("reason", in.Reason); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	tx, err := h.Orgs.Reject(chi.URLParam(r, "id"), member(r), chi.URLParam(r, "txID"), in.Reason)
+	if err != nil {
+		h.TxnError(w, "reject_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, tx)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// PaymentRequestHandler serves /api/v1/payment-requests (request-to-pay).
+type PaymentRequestHandler struct {
+	Requests        *services.PaymentRequestService
+	DefaultCurrency string
+	// TxnError writes the error of a failed payment, the same way the transfer endpoints do.
+	TxnError func(w http.ResponseWriter, fallback string, err error)
+}
+
+func NewPaymentRequestHandler(prs *services.PaymentRequestService, defaultCurrency string,
+	txnError func(http.ResponseWriter, string, error)) *PaymentRequestHandler {
+	return &PaymentRequestHandler{Requests: prs, DefaultCurrency: defaultCurrency, TxnError: txnError}
+}
+
+// Routes mounts the handler under a protected router.
+func (h *PaymentRequestHandler) Routes(r chi.Router) {
+	r.Post("/", h.Create)
+	r.Get("/", h.List)
+	r.Get("/{id}", h.Get)
+	r.Post("/{id}/accept", h.Accept)
+	r.Post("/{id}/decline", h.Decline)
+	r.Post("/{id}/cancel", h.Cancel)
+}
+
+func (h *PaymentRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserID(r.Context())
+	if !ok || uid == "" {
+		httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+		return
+	}
+	var in struct {
+		PayerID   string     `json:"payer_id"`
+		Amount    int64      `json:"amount"`
+		Currency  string     `json:"currency,omitempty"`
+		Note      *string    `json:"note,omitempty"`
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	var verr validate.Errs
+	if e := validate.Required("payer_id", in.PayerID); e != nil {
+		verr = append(verr, *e)
+	} else if _, err := uuid.Parse(in.PayerID); err != nil {
+		verr = append(verr, validate.ErrField{Field: "payer_id", Msg: "must be a valid UUID"})
+	}
+	if e := validate.MinInt("amount", in.Amount, 1); e != nil {
+		verr = append(verr, *e)
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+		return
+	}
+	p := models.PaymentRequest{
+		RequesterID: uid,
+		PayerID:     in.PayerID,
+		Amount:      in.Amount,
+		Currency:    in.Currency,
+		Note:        in.Note,
+		ExpiresAt:   in.ExpiresAt,
+	}
+	if p.Currency == "" {
+		p.Currency = h.DefaultCurrency
+	}
+	out, err := h.Requests.Create(p)
+	if errors.Is(err, services.ErrUserNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "payer_not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "payment_request_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, out)
+}
+
+// List serves ?direction=incoming|outgoing (default both) &status= &limit= &offset=.
+func (h *PaymentRequestHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	q := r.URL.Query()
+	var verr validate.Errs
+	direction := q.Get("direction")
+	if direction != "" {
+		if e := validate.OneOf("direction", direction, models.RequestsIncoming, models.RequestsOutgoing); e != nil {
+			verr = append(verr, *e)
+		}
+	}
+	status := q.Get("status")
+	if status != "" {
+		if e := validate.OneOf("status", status,
+			string(models.RequestPending), string(models.RequestPaid), string(models.RequestDeclined),
+			string(models.RequestCancelled), string(models.RequestRejected), string(models.RequestExpired)); e != nil {
+			verr = append(verr, *e)
+		}
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", verr)
+		return
+	}
+	limit, offset := pageParams(r)
+	out, err := h.Requests.List(uid, direction, models.PaymentRequestStatus(status), limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *PaymentRequestHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Requests.Get(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Accept pays the request; the response links the transfer through transaction_id.
+func (h *PaymentRequestHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Requests.Accept(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *PaymentRequestHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Requests.Decline(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *PaymentRequestHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Requests.Cancel(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *PaymentRequestHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrPaymentRequestNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+	case errors.Is(err, services.ErrPaymentRequestClosed):
+		httpx.WriteError(w, http.StatusConflict, "invalid_state", err.Error(), nil)
+	case errors.Is(err, services.ErrPaymentUnderway):
+		httpx.WriteError(w, http.StatusConflict, "payment_underway", err.Error(), nil)
+	default:
+		h.TxnError(w, "payment_request_failed", err)
+	}
+}
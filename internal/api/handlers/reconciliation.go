@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// ReconciliationHandler serves /api/v1/admin/reconciliation. Admin only; enforced by the router.
+type ReconciliationHandler struct {
+	Recon *services.ReconciliationService
+}
+
+func NewReconciliationHandler(rs *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{Recon: rs}
+}
+
+func (h *ReconciliationHandler) Routes(r chi.Router) {
+	r.Get("/runs", h.Runs)
+	r.Post("/runs", h.Run)
+	r.Get("/drifts", h.Drifts)
+}
+
+func (h *ReconciliationHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	out, err := h.Recon.Runs(limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Run reconciles synchronously. The body is optional; {"adjust": true} books
+// correcting adjustments for every drift found.
+func (h *ReconciliationHandler) Run(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Adjust bool `json:"adjust"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Recon.Run(in.Adjust)
+	if errors.Is(err, repo.ErrRunInProgress) {
+		httpx.WriteError(w, http.StatusConflict, "run_in_progress", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "reconcile_failed", err.Error(), out)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Drifts lists the discrepancies of ?run_id=, defaulting to the latest finished run.
+func (h *ReconciliationHandler) Drifts(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	run, drifts, err := h.Recon.Drifts(r.URL.Query().Get("run_id"), limit, offset)
+	if errors.Is(err, services.ErrReconRunNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"run": run, "drifts": drifts})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/risk"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// RiskHandler serves /api/v1/admin/risk: the queue of transactions held by risk
+// screening and the rules in force. Admin only; enforced by the router.
+type RiskHandler struct {
+	Txns   *services.TransactionService
+	Engine *risk.Engine
+	// TxnError writes the error of a failed release, the same way the transfer endpoints do.
+	TxnError func(w http.ResponseWriter, fallback string, err error)
+}
+
+func NewRiskHandler(ts *services.TransactionService, e *risk.Engine, txnError func(http.ResponseWriter, string, error)) *RiskHandler {
+	return &RiskHandler{Txns: ts, Engine: e, TxnError: txnError}
+}
+
+func (h *RiskHandler) Routes(r chi.Router) {
+	r.Get("/", h.List)
+	r.Get("/rules", h.Rules)
+	r.Post("/rules/reload", h.Reload)
+	r.Get("/{id}", h.Get)
+	r.Post("/{id}/release", h.Release)
+	r.Post("/{id}/reject", h.Reject)
+}
+
+// List serves the review queue, ?status=open (default) | released | rejected | all.
+func (h *RiskHandler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = string(models.CaseOpen)
+	case "all":
+		status = ""
+	default:
+		if e := validate.OneOf("status", status,
+			string(models.CaseOpen), string(models.CaseReleased), string(models.CaseRejected), "all"); e != nil {
+			httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", validate.Errs{*e})
+			return
+		}
+	}
+	limit, offset := pageParams(r)
+	out, err := h.Txns.RiskCases(models.RiskCaseStatus(status), limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *RiskHandler) Get(w http.ResponseWriter, r *http.Request) {
+	out, err := h.Txns.RiskCase(chi.URLParam(r, "id"))
+	if err != nil {
+		h.TxnError(w, "risk_case_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+type riskDecisionReq struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// Release lets the held transaction go ahead; the response is the transaction.
+func (h *RiskHandler) Release(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in riskDecisionReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+			return
+		}
+	}
+	tx, err := h.Txns.ReleaseCase(chi.URLParam(r, "id"), uid, in.Note)
+	if err != nil {
+		h.TxnError(w, "release_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, tx)
+}
+
+// Reject stops the held transaction; a note is required.
+func (h *RiskHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in riskDecisionReq
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	note := ""
+	if in.Note != nil {
+		note = *in.Note
+	}
+	if e := validate.Required("note", note); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	tx, err := h.Txns.RejectCase(chi.URLParam(r, "id"), uid, in.Note)
+	if err != nil {
+		h.TxnError(w, "reject_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, tx)
+}
+
+func (h *RiskHandler) Rules(w http.ResponseWriter, r *http.Request) {
+	httpx.WriteJSON(w, http.StatusOK, h.Engine.Rules())
+}
+
+// Reload rereads the rules file now instead of waiting for the watcher.
+func (h *RiskHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := h.Engine.Reload(); err != nil {
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "risk_rules_invalid", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, h.Engine.Rules())
+}
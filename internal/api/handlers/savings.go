@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// SavingsHandler serves GET /api/v1/savings/accruals and /api/v1/admin/interest-rates.
+// The admin routes are admin only; enforced by the router.
+type SavingsHandler struct {
+	Savings *services.SavingsService
+}
+
+func NewSavingsHandler(ss *services.SavingsService) *SavingsHandler {
+	return &SavingsHandler{Savings: ss}
+}
+
+// Routes mounts the admin rate endpoints.
+func (h *SavingsHandler) Routes(r chi.Router) {
+	r.Get("/", h.Rates)
+	r.Put("/{currency}", h.SetRates)
+}
+
+// Accruals lists the caller's daily interest accruals, optionally for one ?currency=.
+func (h *SavingsHandler) Accruals(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	limit, offset := pageParams(r)
+	out, err := h.Savings.Accruals(uid, r.URL.Query().Get("currency"), limit, offset)
+	if errors.Is(err, currency.ErrUnknown) {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *SavingsHandler) Rates(w http.ResponseWriter, r *http.Request) {
+	out, err := h.Savings.Rates()
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// SetRates replaces every band of the currency: {"tiers":[{"min_balance","apy_bps"}]}.
+func (h *SavingsHandler) SetRates(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Tiers []models.InterestTier `json:"tiers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Savings.SetRates(chi.URLParam(r, "currency"), in.Tiers)
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "interest_rates_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
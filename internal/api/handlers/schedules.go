@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// ScheduleHandler serves /api/v1/schedules (standing orders).
+type ScheduleHandler struct {
+	Schedules       *services.ScheduleService
+	DefaultCurrency string
+}
+
+func NewScheduleHandler(ss *services.ScheduleService, defaultCurrency string) *ScheduleHandler {
+	return &ScheduleHandler{Schedules: ss, DefaultCurrency: defaultCurrency}
+}
+
+// Routes mounts the handler under a protected router.
+func (h *ScheduleHandler) Routes(r chi.Router) {
+	r.Post("/", h.Create)
+	r.Get("/", h.List)
+	r.Get("/{id}", h.Get)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Cancel)
+	r.Get("/{id}/runs", h.Runs)
+}
+
+type createScheduleReq struct {
+	ToUserID      string     `json:"to_user_id"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency,omitempty"`
+	Frequency     string     `json:"frequency"`
+	Cron          *string    `json:"cron,omitempty"`
+	StartAt       *time.Time `json:"start_at,omitempty"`
+	EndAt         *time.Time `json:"end_at,omitempty"`
+	MaxRetries    *int       `json:"max_retries,omitempty"`
+	RetryInterval *int       `json:"retry_interval,omitempty"` // seconds
+}
+
+func (h *ScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserID(r.Context())
+	if !ok || uid == "" {
+		httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+		return
+	}
+	var in createScheduleReq
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	var verr validate.Errs
+	if e := validate.Required("to_user_id", in.ToUserID); e != nil {
+		verr = append(verr, *e)
+	}
+	if e := validate.Required("frequency", in.Frequency); e != nil {
+		verr = append(verr, *e)
+	}
+	if e := validate.MinInt("amount", in.Amount, 1); e != nil {
+		verr = append(verr, *e)
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+		return
+	}
+	if _, err := uuid.Parse(in.ToUserID); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "to_user_id must be a valid UUID", nil)
+		return
+	}
+
+	sc := models.Schedule{
+		UserID:        uid,
+		ToUserID:      in.ToUserID,
+		Amount:        in.Amount,
+		Currency:      in.Currency,
+		Frequency:     models.ScheduleFrequency(in.Frequency),
+		CronExpr:      in.Cron,
+		StartAt:       time.Now().UTC(),
+		EndAt:         in.EndAt,
+		MaxRetries:    3,
+		RetryInterval: 3600,
+	}
+	if sc.Currency == "" {
+		sc.Currency = h.DefaultCurrency
+	}
+	if in.StartAt != nil {
+		sc.StartAt = *in.StartAt
+	}
+	if in.MaxRetries != nil {
+		sc.MaxRetries = *in.MaxRetries
+	}
+	if in.RetryInterval != nil {
+		sc.RetryInterval = *in.RetryInterval
+	}
+
+	out, err := h.Schedules.Create(sc)
+	if errors.Is(err, services.ErrRecipientNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "recipient_not_found", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "schedule_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, out)
+}
+
+func (h *ScheduleHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	limit, offset := pageParams(r)
+	out, err := h.Schedules.List(uid, limit, offset)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Schedules.Get(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *ScheduleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		Amount *int64     `json:"amount,omitempty"`
+		EndAt  *time.Time `json:"end_at,omitempty"`
+		Status *string    `json:"status,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	p := services.SchedulePatch{Amount: in.Amount, EndAt: in.EndAt}
+	if in.Status != nil {
+		st := models.ScheduleStatus(*in.Status)
+		p.Status = &st
+	}
+	out, err := h.Schedules.Update(uid, chi.URLParam(r, "id"), p)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *ScheduleHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Schedules.Cancel(uid, chi.URLParam(r, "id"))
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *ScheduleHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	limit, offset := pageParams(r)
+	out, err := h.Schedules.Runs(uid, chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+func writeScheduleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrScheduleNotFound) {
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+		return
+	}
+	httpx.WriteError(w, http.StatusBadRequest, "schedule_failed", err.Error(), nil)
+}
+
+// pageParams reads limit (default 50) and offset (default 0) from the query string.
+func pageParams(r *http.Request) (limit, offset int) {
+	q := r.URL.Query()
+	return httpx.ParseInt(q.Get("limit"), 50, 1), httpx.ParseInt(q.Get("offset"), 0, 0)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/services"
+	"github.com/baharkarakas/insider-backend/internal/statement"
+)
+
+// StatementHandler serves /api/v1/statements.
+type StatementHandler struct {
+	Statements      *services.StatementService
+	DefaultCurrency string
+}
+
+func NewStatementHandler(ss *services.StatementService, defaultCurrency string) *StatementHandler {
+	return &StatementHandler{Statements: ss, DefaultCurrency: defaultCurrency}
+}
+
+func (h *StatementHandler) Routes(r chi.Router) {
+	r.Get("/", h.Export)
+	r.Get("/monthly", h.Monthly)
+}
+
+// Export streams a statement as a file download:
+// ?from=&to= (RFC3339, to exclusive, default now) &format=csv|ofx|camt053 &currency=
+func (h *StatementHandler) Export(w http.ResponseWriter, r *http.Request) {
+	uid, ok := middleware.UserID(r.Context())
+	if !ok || uid == "" {
+		httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+		return
+	}
+	q := r.URL.Query()
+	var verr validate.Errs
+	from, err := time.Parse(time.RFC3339, q.Get("from"))
+	if err != nil {
+		verr = append(verr, validate.ErrField{Field: "from", Msg: "required, RFC3339"})
+	}
+	to := time.Now().UTC()
+	if raw := q.Get("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			verr = append(verr, validate.ErrField{Field: "to", Msg: "must be RFC3339"})
+		}
+	}
+	format := statement.Format(q.Get("format"))
+	if format == "" {
+		format = statement.FormatCSV
+	}
+	if e := validate.OneOf("format", string(format), string(statement.FormatCSV), string(statement.FormatOFX), string(statement.FormatCAMT053)); e != nil {
+		verr = append(verr, *e)
+	}
+	cur := q.Get("currency")
+	if cur == "" {
+		cur = h.DefaultCurrency
+	}
+	c, err := currency.Lookup(cur)
+	if err != nil {
+		verr = append(verr, validate.ErrField{Field: "currency", Msg: err.Error()})
+	}
+	if len(verr) == 0 && !from.Before(to) {
+		verr = append(verr, validate.ErrField{Field: "to", Msg: services.ErrInvalidPeriod.Error()})
+	}
+	if len(verr) > 0 {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", verr)
+		return
+	}
+
+	cw := &countingWriter{w: w}
+	sw, _ := statement.New(format, cw)
+	id := statement.Header{Currency: c, From: from, To: to}.ID()
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+format.Filename(id)+`"`)
+
+	err = h.Statements.Export(r.Context(), uid, c.Code, from, to, sw)
+	if err == nil {
+		return
+	}
+	if cw.n == 0 && !errors.Is(err, r.Context().Err()) {
+		w.Header().Del("Content-Disposition")
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	// the download is already under way; the truncated body is all we can signal
+	slog.Error("statement export", "user_id", uid, "format", format, "written", cw.n, "err", err)
+}
+
+// Monthly lists the caller's closed month-end statements, optionally for one ?currency=.
+func (h *StatementHandler) Monthly(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	limit, offset := pageParams(r)
+	out, err := h.Statements.Monthly(uid, r.URL.Query().Get("currency"), limit, offset)
+	if errors.Is(err, currency.ErrUnknown) {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// countingWriter records how much of the response has been written.
+type countingWriter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/api/validate"
+	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/services"
+)
+
+// WalletHandler serves /api/v1/wallets: the caller's wallets and moves between them.
+type WalletHandler struct {
+	Wallets         *services.WalletService
+	Txns            *services.TransactionService
+	DefaultCurrency string
+	// TxnError writes the error of a failed move, the same way the transfer endpoints do.
+	TxnError func(w http.ResponseWriter, fallback string, err error)
+}
+
+func NewWalletHandler(ws *services.WalletService, ts *services.TransactionService, defaultCurrency string,
+	txnError func(http.ResponseWriter, string, error)) *WalletHandler {
+	return &WalletHandler{Wallets: ws, Txns: ts, DefaultCurrency: defaultCurrency, TxnError: txnError}
+}
+
+func (h *WalletHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	out, err := h.Wallets.List(uid)
+	if err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, out)
+}
+
+// Create opens a savings or custom wallet.
+func (h *WalletHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in models.Wallet
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	out, err := h.Wallets.Create(uid, in)
+	if errors.Is(err, repository.ErrDuplicateWalletName) {
+		httpx.WriteError(w, http.StatusConflict, "duplicate_wallet", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "wallet_failed", err.Error(), nil)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, out)
+}
+
+// Move shifts money between two of the caller's wallets; an omitted wallet id is main.
+func (h *WalletHandler) Move(w http.ResponseWriter, r *http.Request) {
+	uid, _ := middleware.UserID(r.Context())
+	var in struct {
+		FromWalletID string `json:"from_wallet_id"`
+		ToWalletID   string `json:"to_wallet_id"`
+		Amount       int64  `json:"amount"`
+		Currency     string `json:"currency"`
+		models.TxnDetails
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+		return
+	}
+	if e := validate.MinInt("amount", in.Amount, 1); e != nil {
+		httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+		return
+	}
+	if in.Currency == "" {
+		in.Currency = h.DefaultCurrency
+	}
+	tx, err := h.Txns.Move(uid, in.FromWalletID, in.ToWalletID, in.Amount, in.Currency, in.TxnDetails)
+	if err != nil {
+		h.TxnError(w, "move_failed", err)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, tx)
+}
@@ -3,6 +3,7 @@ package httpx
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 )
 
 type APIError struct {
@@ -24,3 +25,18 @@ func WriteError(w http.ResponseWriter, status int, code, msg string, details int
 		Details: details,
 	})
 }
+
+// ParseInt parses s into int; returns def if empty/invalid; clamps to min.
+func ParseInt(s string, def, min int) int {
+	if s == "" {
+		return def
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	if v < min {
+		return def
+	}
+	return v
+}
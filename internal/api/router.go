@@ -3,8 +3,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"errors"
@@ -20,12 +22,16 @@ import (
 	"github.com/baharkarakas/insider-backend/internal/api/validate"
 	a "github.com/baharkarakas/insider-backend/internal/auth"
 	"github.com/baharkarakas/insider-backend/internal/config"
+	"github.com/baharkarakas/insider-backend/internal/currency"
 	"github.com/baharkarakas/insider-backend/internal/middleware"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/risk"
 	"github.com/baharkarakas/insider-backend/internal/services"
 )
 
 // NewRouter sets up all routes & middlewares.
-func NewRouter(cfg config.Config, us *services.UserService, bs *services.BalanceService, ts *services.TransactionService) http.Handler {
+func NewRouter(cfg config.Config, us *services.UserService, bs *services.BalanceService, ts *services.TransactionService, ls *services.LedgerService, is *services.IdempotencyService, ss *services.ScheduleService, fx *services.FXService, lim *services.LimitService, fs *services.FeeService, cls *services.CreditLineService, svs *services.SavingsService, prs *services.PaymentRequestService, re *risk.Engine, ws *services.WalletService, ogs *services.OrgService, rs *services.ReconciliationService, sts *services.StatementService) http.Handler {
 	r := chi.NewRouter()
 
 	// -------- Middlewares --------
@@ -51,6 +57,26 @@ func NewRouter(cfg config.Config, us *services.UserService, bs *services.Balance
 
 	tm := a.NewTokenManager(accessSecret, refreshSecret, accessTTL, refreshTTL)
 ah := h.NewAuthHandler(tm, us) 
+	sh := h.NewScheduleHandler(ss, cfg.DefaultCurrency)
+	lh := h.NewLimitHandler(lim)
+	rh := h.NewReconciliationHandler(rs)
+	fh := h.NewFeeHandler(fs, cfg.DefaultCurrency)
+	clh := h.NewCreditLineHandler(cls, cfg.DefaultCurrency)
+	ash := h.NewAccountStateHandler(us)
+	svh := h.NewSavingsHandler(svs)
+	prh := h.NewPaymentRequestHandler(prs, cfg.DefaultCurrency, writeTxnError)
+	rkh := h.NewRiskHandler(ts, re, writeTxnError)
+	wh := h.NewWalletHandler(ws, ts, cfg.DefaultCurrency, writeTxnError)
+	dh := h.NewDisputeHandler(ts, writeTxnError)
+	sth := h.NewStatementHandler(sts, cfg.DefaultCurrency)
+
+	// requests that don't name a currency use the configured default
+	currencyOr := func(c string) string {
+		if c == "" {
+			return cfg.DefaultCurrency
+		}
+		return c
+	}
 
 	// API v1 
 	r.Route("/api/v1", func(r chi.Router) {
@@ -105,38 +131,190 @@ r.Post("/auth/refresh", ah.Refresh)
 				httpx.WriteJSON(w, http.StatusOK, users)
 			})
 
+			// --- Ledger (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Get("/admin/ledger/trial-balance", func(w http.ResponseWriter, r *http.Request) {
+				tb, err := ls.TrialBalance()
+				if err != nil {
+					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, tb)
+			})
+
+			// --- Limits (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/limits", lh.Routes)
+			pr.With(middleware.RequireRole("admin")).Put(`/admin/users/{id:[0-9a-fA-F-]{36}}/tier`, lh.SetTier)
+			pr.With(middleware.RequireRole("admin")).Put(`/admin/users/{id:[0-9a-fA-F-]{36}}/credit-line`, clh.Set)
+			pr.With(middleware.RequireRole("admin")).Put(`/admin/users/{id:[0-9a-fA-F-]{36}}/state`, ash.Set)
+
+			// --- Fees (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/fees", fh.Routes)
+
+			// --- Savings interest rates (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/interest-rates", svh.Routes)
+
+			// --- Reconciliation (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/reconciliation", rh.Routes)
+
+			// --- Risk review queue (admin only) ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/risk", rkh.Routes)
+
+			// --- Disputes (admin): resolve for the sender (chargeback) or the recipient ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/disputes", dh.AdminRoutes)
+
+			// --- Approvals (admin): transfers above the approval threshold; organizations also approve their own under /orgs ---
+			pr.With(middleware.RequireRole("admin")).Route("/admin/approvals", func(ar chi.Router) {
+				ar.Get("/", func(w http.ResponseWriter, r *http.Request) {
+					limit := httpx.ParseInt(r.URL.Query().Get("limit"), 50, 1)
+					offset := httpx.ParseInt(r.URL.Query().Get("offset"), 0, 0)
+					txs, err := ts.PendingApprovals(limit, offset)
+					if err != nil {
+						httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+						return
+					}
+					httpx.WriteJSON(w, http.StatusOK, txs)
+				})
+				// executes the transfer; the approver must not be its creator
+				ar.Post(`/{id:[0-9a-fA-F-]{36}}/approve`, func(w http.ResponseWriter, r *http.Request) {
+					uid, _ := middleware.UserID(r.Context())
+					tx, err := ts.Approve(chi.URLParam(r, "id"), uid)
+					if err != nil {
+						writeTxnError(w, "approve_failed", err)
+						return
+					}
+					httpx.WriteJSON(w, http.StatusOK, tx)
+				})
+				ar.Post(`/{id:[0-9a-fA-F-]{36}}/reject`, func(w http.ResponseWriter, r *http.Request) {
+					uid, _ := middleware.UserID(r.Context())
+					var in struct {
+						Reason string `json:"reason"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+						httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+						return
+					}
+					if e := validate.Required("reason", in.Reason); e != nil {
+						httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+						return
+					}
+					tx, err := ts.Reject(chi.URLParam(r, "id"), uid, in.Reason)
+					if err != nil {
+						writeTxnError(w, "reject_failed", err)
+						return
+					}
+					httpx.WriteJSON(w, http.StatusOK, tx)
+				})
+			})
+
+			// --- FX rates (admin sets, everyone reads) ---
+			pr.With(middleware.RequireRole("admin")).Post("/admin/fx-rates", func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := middleware.UserID(r.Context())
+				var in struct {
+					BaseCurrency  string `json:"base_currency"`
+					QuoteCurrency string `json:"quote_currency"`
+					Rate          string `json:"rate"` // decimal string, e.g. "1.0834"
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				var verr validate.Errs
+				if e := validate.Required("base_currency", in.BaseCurrency); e != nil { verr = append(verr, *e) }
+				if e := validate.Required("quote_currency", in.QuoteCurrency); e != nil { verr = append(verr, *e) }
+				if e := validate.Required("rate", in.Rate); e != nil { verr = append(verr, *e) }
+				if len(verr) > 0 {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+					return
+				}
+				fr, err := fx.SetRate(in.BaseCurrency, in.QuoteCurrency, in.Rate, uid)
+				if err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "fx_rate_failed", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, fr)
+			})
+			pr.Get("/fx-rates", func(w http.ResponseWriter, r *http.Request) {
+				rates, err := fx.Rates()
+				if err != nil {
+					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, rates)
+			})
+
 			// --- Balances ---
+			// every wallet and currency the user holds
+			pr.Get("/balances", func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				bals, err := bs.List(uid)
+				if err != nil {
+					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, bals)
+			})
+			// every wallet in ?currency=EUR (default currency if omitted) plus their total
 			pr.Get("/balances/current", func(w http.ResponseWriter, r *http.Request) {
 				uid, ok := middleware.UserID(r.Context())
 				if !ok || uid == "" {
 					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
 					return
 				}
-				b, err := bs.Current(uid)
+				b, err := bs.Current(uid, currencyOr(r.URL.Query().Get("currency")))
 				if err != nil {
 					httpx.WriteError(w, http.StatusBadRequest, "balance_failed", err.Error(), nil)
 					return
 				}
 				httpx.WriteJSON(w, http.StatusOK, b)
 			})
-			// placeholder: ileride implement
+			// ?at=RFC3339&currency=EUR
 			pr.Get("/balances/at-time", func(w http.ResponseWriter, r *http.Request) {
-				http.Error(w, "not implemented", http.StatusNotImplemented)
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				raw := r.URL.Query().Get("at")
+				if e := validate.Required("at", raw); e != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", validate.Errs{*e})
+					return
+				}
+				at, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "at must be an RFC 3339 timestamp", nil)
+					return
+				}
+				cur := currencyOr(r.URL.Query().Get("currency"))
+				amount, err := bs.AtTime(uid, cur, at)
+				if err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "balance_failed", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, map[string]any{"user_id": uid, "currency": cur, "amount": amount, "at": at})
 			})
 
 			// --- Transactions (Idempotency-Key destekli) ---
+			idem := func(endpoint string) func(http.Handler) http.Handler {
+				return middleware.Idempotency(is, endpoint)
+			}
 
 			// credit
-			pr.Post("/transactions/credit", func(w http.ResponseWriter, r *http.Request) {
+			pr.With(idem("transactions.credit")).Post("/transactions/credit", func(w http.ResponseWriter, r *http.Request) {
 				uid, ok := middleware.UserID(r.Context())
 				if !ok || uid == "" {
 					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
 					return
 				}
-				idem := r.Header.Get("Idempotency-Key")
 
 				var in struct {
-					Amount int64 `json:"amount"`
+					Amount   int64  `json:"amount"`
+					Currency string `json:"currency"`
+					WalletID string `json:"wallet_id"` // main if omitted
+					models.TxnDetails
 				}
 				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
@@ -148,23 +326,25 @@ r.Post("/auth/refresh", ah.Refresh)
 					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
 					return
 				}
-				tx, err := ts.CreditIdem(uid, in.Amount, idem)
+				tx, err := ts.Credit(uid, in.WalletID, in.Amount, currencyOr(in.Currency), in.TxnDetails)
 				if err != nil {
-					httpx.WriteError(w, http.StatusBadRequest, "credit_failed", err.Error(), nil)
+					writeTxnError(w, "credit_failed", err)
 					return
 				}
 				httpx.WriteJSON(w, http.StatusAccepted, tx)
 			})
 
 			// debit
-			pr.Post("/transactions/debit", func(w http.ResponseWriter, r *http.Request) {
+			pr.With(idem("transactions.debit")).Post("/transactions/debit", func(w http.ResponseWriter, r *http.Request) {
 				uid, ok := middleware.UserID(r.Context())
 				if !ok || uid == "" {
 					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
 					return
 				}
 				var in struct {
-					Amount int64 `json:"amount"`
+					Amount   int64  `json:"amount"`
+					Currency string `json:"currency"`
+					models.TxnDetails
 				}
 				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
@@ -178,27 +358,28 @@ r.Post("/auth/refresh", ah.Refresh)
 					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
 					return
 				}
-				// Idempotent versiyonun yoksa Debit kullan
-				tx, err := ts.Debit(uid, in.Amount)
+				tx, err := ts.Debit(uid, in.Amount, currencyOr(in.Currency), in.TxnDetails)
 				if err != nil {
-					httpx.WriteError(w, http.StatusBadRequest, "debit_failed", err.Error(), nil)
+					writeTxnError(w, "debit_failed", err)
 					return
 				}
 				httpx.WriteJSON(w, http.StatusAccepted, tx)
 			})
 
 			// transfer (from = context; body: to_user_id, amount)
-			pr.Post("/transactions/transfer", func(w http.ResponseWriter, r *http.Request) {
+			pr.With(idem("transactions.transfer")).Post("/transactions/transfer", func(w http.ResponseWriter, r *http.Request) {
 				from, ok := middleware.UserID(r.Context())
 				if !ok || from == "" {
 					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
 					return
 				}
-				idem := r.Header.Get("Idempotency-Key")
 
 				var in struct {
-					ToUserID string `json:"to_user_id"`
-					Amount   int64  `json:"amount"`
+					ToUserID   string `json:"to_user_id"`
+					ToWalletID string `json:"to_wallet_id"` // recipient's main wallet if omitted
+					Amount     int64  `json:"amount"`
+					Currency   string `json:"currency"`
+					models.TxnDetails
 				}
 				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
@@ -221,19 +402,136 @@ if _, err := uuid.Parse(in.ToUserID); err != nil {
 					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "cannot transfer to self", nil)
 					return
 				}
-				    tx, err := ts.TransferIdem(from, in.ToUserID, in.Amount, idem)
+				    tx, err := ts.Transfer(from, in.ToUserID, in.ToWalletID, in.Amount, currencyOr(in.Currency), in.TxnDetails)
     if errors.Is(err, services.ErrRecipientNotFound) {
         httpx.WriteError(w, http.StatusNotFound, "recipient_not_found", err.Error(), nil)
         return
     }
     if err != nil {
-        httpx.WriteError(w, http.StatusBadRequest, "transfer_failed", err.Error(), nil)
+        writeTxnError(w, "transfer_failed", err)
         return
     }
 
 				httpx.WriteJSON(w, http.StatusAccepted, tx)
 			})
 
+			// convert between currencies at the current FX rate; to_user_id defaults to the caller,
+			// and another user is paid by an ordinary transfer of the converted amount
+			pr.With(idem("transactions.convert")).Post("/transactions/convert", func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				var in struct {
+					FromCurrency string `json:"from_currency"`
+					ToCurrency   string `json:"to_currency"`
+					Amount       int64  `json:"amount"` // minor units of from_currency
+					ToUserID     string `json:"to_user_id"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				var verr validate.Errs
+				if e := validate.Required("to_currency", in.ToCurrency); e != nil { verr = append(verr, *e) }
+				if e := validate.MinInt("amount", in.Amount, 1); e != nil { verr = append(verr, *e) }
+				if len(verr) > 0 {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+					return
+				}
+				if in.ToUserID != "" {
+					if _, err := uuid.Parse(in.ToUserID); err != nil {
+						httpx.WriteError(w, http.StatusBadRequest, "validation_error", "to_user_id must be a valid UUID", nil)
+						return
+					}
+				}
+				conv, err := ts.Convert(uid, in.ToUserID, in.Amount, currencyOr(in.FromCurrency), in.ToCurrency)
+				if errors.Is(err, services.ErrRecipientNotFound) {
+					httpx.WriteError(w, http.StatusNotFound, "recipient_not_found", err.Error(), nil)
+					return
+				}
+				if err != nil {
+					writeTxnError(w, "convert_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, conv)
+			})
+
+			pr.Get("/transactions/conversions", func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				limit := httpx.ParseInt(r.URL.Query().Get("limit"), 50, 1)
+				offset := httpx.ParseInt(r.URL.Query().Get("offset"), 0, 0)
+				cs, err := ts.ListConversions(uid, limit, offset)
+				if err != nil {
+					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, cs)
+			})
+
+			// batch transfer: one sender, many recipients, one DB transaction
+			pr.With(idem("transactions.batch")).Post("/transactions/batch", func(w http.ResponseWriter, r *http.Request) {
+				from, ok := middleware.UserID(r.Context())
+				if !ok || from == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				var in struct {
+					Mode     string `json:"mode"` // atomic (default) | best_effort
+					Currency string `json:"currency"`
+					Legs     []struct {
+						ToUserID string `json:"to_user_id"`
+						Amount   int64  `json:"amount"`
+					} `json:"legs"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				var verr validate.Errs
+				legs := make([]models.BatchLeg, len(in.Legs))
+				for i, l := range in.Legs {
+					field := "legs[" + strconv.Itoa(i) + "]"
+					if _, err := uuid.Parse(l.ToUserID); err != nil {
+						verr = append(verr, validate.ErrField{Field: field + ".to_user_id", Msg: "must be a valid UUID"})
+					}
+					if e := validate.MinInt(field+".amount", l.Amount, 1); e != nil { verr = append(verr, *e) }
+					legs[i] = models.BatchLeg{ToUserID: l.ToUserID, Amount: l.Amount}
+				}
+				if len(verr) > 0 {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+					return
+				}
+				b, err := ts.TransferBatch(from, currencyOr(in.Currency), models.BatchMode(in.Mode), legs)
+				if errors.Is(err, services.ErrBatchFailed) {
+					httpx.WriteError(w, http.StatusUnprocessableEntity, "batch_failed", err.Error(), b)
+					return
+				}
+				if err != nil {
+					writeTxnError(w, "batch_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, b)
+			})
+
+			pr.Get(`/transactions/batch/{id:[0-9a-fA-F-]{36}}`, func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := middleware.UserID(r.Context())
+				b, err := ts.GetBatch(uid, chi.URLParam(r, "id"))
+				if err != nil {
+					writeTxnError(w, "batch_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, b)
+			})
+
+			// fee quote, before the user confirms a transfer or debit
+			pr.Get("/transactions/fees/quote", fh.Quote)
+
 			// list/history
 			pr.Get("/transactions/history", func(w http.ResponseWriter, r *http.Request) {
 				uid, ok := middleware.UserID(r.Context())
@@ -241,17 +539,191 @@ if _, err := uuid.Parse(in.ToUserID); err != nil {
 					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
 					return
 				}
-				limit := parseInt(r.URL.Query().Get("limit"), 50, 1)
-				offset := parseInt(r.URL.Query().Get("offset"), 0, 0)
+				limit := httpx.ParseInt(r.URL.Query().Get("limit"), 50, 1)
+				if limit > maxHistoryLimit {
+					limit = maxHistoryLimit
+				}
+				f, verr := historyFilter(r.URL.Query())
+				if len(verr) > 0 {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid query", verr)
+					return
+				}
+
+				page, err := ts.History(uid, f, r.URL.Query().Get("cursor"), limit)
+				if errors.Is(err, services.ErrInvalidCursor) {
+					httpx.WriteError(w, http.StatusBadRequest, "invalid_cursor", err.Error(), nil)
+					return
+				}
+				if err != nil {
+					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, page)
+			})
+
+			// reverse (admin): full compensating transaction
+			pr.With(middleware.RequireRole("admin"), idem("transactions.reverse")).Post(`/transactions/{id:[0-9a-fA-F-]{36}}/reverse`, func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := middleware.UserID(r.Context())
+				var in struct {
+					Reason string `json:"reason"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				if e := validate.Required("reason", in.Reason); e != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+					return
+				}
+				tx, err := ts.Reverse(chi.URLParam(r, "id"), uid, in.Reason)
+				if err != nil {
+					writeTxnError(w, "reverse_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, tx)
+			})
+
+			// refund (transfer recipient): partial or full
+			pr.With(idem("transactions.refund")).Post(`/transactions/{id:[0-9a-fA-F-]{36}}/refund`, func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				var in struct {
+					Amount int64  `json:"amount"`
+					Reason string `json:"reason"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				if e := validate.MinInt("amount", in.Amount, 1); e != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+					return
+				}
+				tx, err := ts.Refund(chi.URLParam(r, "id"), uid, in.Amount, in.Reason)
+				if err != nil {
+					writeTxnError(w, "refund_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, tx)
+			})
+
+			// --- Holds (authorize / capture / void) ---
+			pr.With(idem("transactions.authorize")).Post("/transactions/authorize", func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				var in struct {
+					Amount      int64   `json:"amount"`
+					Currency    string  `json:"currency"`
+					PayeeUserID *string `json:"payee_user_id"`
+					ExpiresIn   int64   `json:"expires_in"` // seconds
+				}
+				if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+					return
+				}
+				var verr validate.Errs
+				if e := validate.MinInt("amount", in.Amount, 1); e != nil { verr = append(verr, *e) }
+				if e := validate.MinInt("expires_in", in.ExpiresIn, 0); e != nil { verr = append(verr, *e) }
+				if len(verr) > 0 {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", verr)
+					return
+				}
+				if in.PayeeUserID != nil {
+					if _, err := uuid.Parse(*in.PayeeUserID); err != nil {
+						httpx.WriteError(w, http.StatusBadRequest, "validation_error", "payee_user_id must be a valid UUID", nil)
+						return
+					}
+				}
+				h, err := ts.Authorize(uid, in.Amount, currencyOr(in.Currency), in.PayeeUserID, time.Duration(in.ExpiresIn)*time.Second)
+				if errors.Is(err, services.ErrRecipientNotFound) {
+					httpx.WriteError(w, http.StatusNotFound, "recipient_not_found", err.Error(), nil)
+					return
+				}
+				if err != nil {
+					writeTxnError(w, "authorize_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, h)
+			})
 
-				txs, err := ts.ListByUser(uid, limit, offset)
+			pr.Get("/transactions/holds", func(w http.ResponseWriter, r *http.Request) {
+				uid, ok := middleware.UserID(r.Context())
+				if !ok || uid == "" {
+					httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "user_id not provided", nil)
+					return
+				}
+				limit := httpx.ParseInt(r.URL.Query().Get("limit"), 50, 1)
+				offset := httpx.ParseInt(r.URL.Query().Get("offset"), 0, 0)
+				hs, err := ts.ListHolds(uid, limit, offset)
 				if err != nil {
 					httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
 					return
 				}
-				httpx.WriteJSON(w, http.StatusOK, txs)
+				httpx.WriteJSON(w, http.StatusOK, hs)
+			})
+
+			pr.With(idem("transactions.capture")).Post(`/transactions/holds/{id:[0-9a-fA-F-]{36}}/capture`, func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := middleware.UserID(r.Context())
+				var in struct {
+					Amount int64 `json:"amount"` // 0 = full
+				}
+				if r.ContentLength != 0 {
+					if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+						httpx.WriteError(w, http.StatusBadRequest, "bad_request", "invalid json", nil)
+						return
+					}
+				}
+				if e := validate.MinInt("amount", in.Amount, 0); e != nil {
+					httpx.WriteError(w, http.StatusBadRequest, "validation_error", "invalid payload", validate.Errs{*e})
+					return
+				}
+				tx, err := ts.Capture(chi.URLParam(r, "id"), uid, in.Amount)
+				if err != nil {
+					writeTxnError(w, "capture_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusCreated, tx)
 			})
 
+			pr.Post(`/transactions/holds/{id:[0-9a-fA-F-]{36}}/void`, func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := middleware.UserID(r.Context())
+				h, err := ts.Void(chi.URLParam(r, "id"), uid)
+				if err != nil {
+					writeTxnError(w, "void_failed", err)
+					return
+				}
+				httpx.WriteJSON(w, http.StatusOK, h)
+			})
+
+			// --- Schedules (standing orders) ---
+			pr.Route("/schedules", sh.Routes)
+
+			// --- Statements (file export) ---
+			pr.Route("/statements", sth.Routes)
+
+			// --- Savings ---
+			pr.Get("/savings/accruals", svh.Accruals)
+
+			// --- Payment requests (request-to-pay) ---
+			pr.Route("/payment-requests", prh.Routes)
+
+			// --- Wallets (sub-accounts) and free moves between them ---
+			pr.Get("/wallets", wh.List)
+			pr.Post("/wallets", wh.Create)
+			pr.With(idem("wallets.move")).Post("/wallets/move", wh.Move)
+
+			// --- Organizations: access follows membership and the member's role ---
+			pr.Route("/orgs", h.NewOrgHandler(ogs, cfg.DefaultCurrency, writeTxnError, idem, historyFilter).Routes)
+
+			// --- Disputes: the sender contests a transfer, the recipient responds ---
+			pr.Route("/disputes", dh.Routes)
+
 			// /transactions/{id}
 			pr.Get(`/transactions/{id:[0-9a-fA-F-]{36}}`, func(w http.ResponseWriter, r *http.Request) {
 				id := chi.URLParam(r, "id")
@@ -268,17 +740,148 @@ if _, err := uuid.Parse(in.ToUserID); err != nil {
 	return r
 }
 
-// parseInt parses s into int; returns def if empty/invalid; clamps to min.
-func parseInt(s string, def, min int) int {
-	if s == "" {
-		return def
+// writeTxnError maps TransactionService errors to HTTP responses; anything unknown is a 400 with fallback code.
+func writeTxnError(w http.ResponseWriter, fallback string, err error) {
+	var limitErr *services.LimitExceededError
+	switch {
+	case errors.As(err, &limitErr):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "limit_exceeded", err.Error(), limitErr)
+	case errors.Is(err, services.ErrTransactionNotFound), errors.Is(err, services.ErrHoldNotFound),
+		errors.Is(err, services.ErrBatchNotFound), errors.Is(err, services.ErrWalletNotFound),
+		errors.Is(err, services.ErrDisputeNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+	case errors.Is(err, services.ErrAccountFrozen):
+		httpx.WriteError(w, http.StatusForbidden, "account_frozen", err.Error(), nil)
+	case errors.Is(err, services.ErrAccountClosed):
+		httpx.WriteError(w, http.StatusForbidden, "account_closed", err.Error(), nil)
+	case errors.Is(err, services.ErrForbidden):
+		httpx.WriteError(w, http.StatusForbidden, "forbidden", err.Error(), nil)
+	case errors.Is(err, services.ErrSelfApproval):
+		httpx.WriteError(w, http.StatusForbidden, "self_approval", err.Error(), nil)
+	case errors.Is(err, services.ErrAwaitingApproval):
+		httpx.WriteError(w, http.StatusConflict, "awaiting_approval", err.Error(), nil)
+	case errors.Is(err, services.ErrHoldNeedsApproval):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "approval_required", err.Error(), nil)
+	case errors.Is(err, services.ErrHeldForReview):
+		httpx.WriteError(w, http.StatusConflict, "held_for_review", err.Error(), nil)
+	case errors.Is(err, services.ErrRiskFlagged):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "risk_flagged", err.Error(), nil)
+	case errors.Is(err, services.ErrRiskCaseNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "not_found", err.Error(), nil)
+	case errors.Is(err, services.ErrRiskCaseClosed):
+		httpx.WriteError(w, http.StatusConflict, "invalid_state", err.Error(), nil)
+	case errors.Is(err, services.ErrNotReversible), errors.Is(err, services.ErrNotRefundable),
+		errors.Is(err, services.ErrHoldNotActive), errors.Is(err, services.ErrNotAwaitingApproval),
+		errors.Is(err, services.ErrNotDisputable), errors.Is(err, services.ErrDisputeClosed):
+		httpx.WriteError(w, http.StatusConflict, "invalid_state", err.Error(), nil)
+	case errors.Is(err, services.ErrRefundExceeds), errors.Is(err, services.ErrCaptureExceeds):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "amount_exceeds", err.Error(), nil)
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "insufficient_balance", err.Error(), nil)
+	case errors.Is(err, services.ErrDisputeActive):
+		httpx.WriteError(w, http.StatusConflict, "disputed", err.Error(), nil)
+	case errors.Is(err, repository.ErrAlreadyDisputed):
+		httpx.WriteError(w, http.StatusConflict, "already_disputed", err.Error(), nil)
+	case errors.Is(err, services.ErrDisputeTooLate):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "dispute_window_passed", err.Error(), nil)
+	case errors.Is(err, repository.ErrDuplicateReference):
+		httpx.WriteError(w, http.StatusConflict, "duplicate_reference", err.Error(), nil)
+	case errors.Is(err, services.ErrNoFXRate):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "fx_rate_unavailable", err.Error(), nil)
+	case errors.Is(err, currency.ErrOverflow):
+		httpx.WriteError(w, http.StatusUnprocessableEntity, "amount_too_large", err.Error(), nil)
+	default:
+		httpx.WriteError(w, http.StatusBadRequest, fallback, err.Error(), nil)
+	}
+}
+
+const maxHistoryLimit = 200
+
+// historyFilter reads the /transactions/history filters. type and status take
+// comma-separated lists or repeated parameters; from/to are RFC3339, to exclusive.
+func historyFilter(q url.Values) (models.TransactionFilter, validate.Errs) {
+	var f models.TransactionFilter
+	var verr validate.Errs
+	for _, t := range listParam(q, "type") {
+		if e := validate.OneOf("type", t, txnTypes...); e != nil {
+			verr = append(verr, *e)
+			break
+		}
+		f.Types = append(f.Types, models.TransactionType(t))
+	}
+	for _, st := range listParam(q, "status") {
+		if e := validate.OneOf("status", st, txnStatuses...); e != nil {
+			verr = append(verr, *e)
+			break
+		}
+		f.Statuses = append(f.Statuses, models.TransactionStatus(st))
 	}
-	v, err := strconv.Atoi(s)
-	if err != nil {
-		return def
+	if d := q.Get("direction"); d != "" {
+		if e := validate.OneOf("direction", d, string(models.DirectionIn), string(models.DirectionOut)); e != nil {
+			verr = append(verr, *e)
+		}
+		f.Direction = models.Direction(d)
 	}
-	if v < min {
-		return def
+	if cp := q.Get("counterparty"); cp != "" {
+		if _, err := uuid.Parse(cp); err != nil {
+			verr = append(verr, validate.ErrField{Field: "counterparty", Msg: "must be a valid UUID"})
+		}
+		f.Counterparty = cp
 	}
-	return v
+	f.Currency = strings.ToUpper(q.Get("currency"))
+	f.Reference = q.Get("external_reference")
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{{"min_amount", &f.MinAmount}, {"max_amount", &f.MaxAmount}} {
+		if raw := q.Get(p.name); raw != "" {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				verr = append(verr, validate.ErrField{Field: p.name, Msg: "must be an integer"})
+				continue
+			}
+			*p.dst = &v
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if raw := q.Get(p.name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				verr = append(verr, validate.ErrField{Field: p.name, Msg: "must be RFC3339"})
+				continue
+			}
+			*p.dst = &t
+		}
+	}
+	return f, verr
 }
+
+var (
+	txnTypes = []string{
+		string(models.TxnCredit), string(models.TxnDebit), string(models.TxnTransfer), string(models.TxnReversal),
+		string(models.TxnRefund), string(models.TxnAuthorization), string(models.TxnCapture), string(models.TxnVoid),
+		string(models.TxnConversion), string(models.TxnAdjustment), string(models.TxnFee), string(models.TxnMove),
+		string(models.TxnOverdraftInterest), string(models.TxnInterest), string(models.TxnChargeback),
+	}
+	txnStatuses = []string{
+		string(models.TxnPending), string(models.TxnCompleted), string(models.TxnFailed),
+		string(models.TxnRolledBack), string(models.TxnReversed), string(models.TxnRefunded),
+		string(models.TxnAwaitingApproval), string(models.TxnRejected), string(models.TxnHeldForReview),
+	}
+)
+
+func listParam(q url.Values, key string) []string {
+	var out []string
+	for _, v := range q[key] {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
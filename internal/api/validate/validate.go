@@ -37,3 +37,12 @@ func MinInt(field string, v, min int64) *ErrField {
 	}
 	return nil
 }
+
+func OneOf(field, value string, allowed ...string) *ErrField {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return &ErrField{Field: field, Msg: "must be one of " + strings.Join(allowed, ", ")}
+}
@@ -0,0 +1,142 @@
+// Package cron parses standard 5-field cron expressions
+// (minute hour day-of-month month day-of-week).
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type field struct {
+	min, max int
+}
+
+var fields = [5]field{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, 0 = Sunday (7 is accepted as Sunday too)
+}
+
+// Schedule is a parsed expression. Times are evaluated in the location of the argument to Next.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Parse accepts *, lists (1,2), ranges (1-5) and steps (*/15, 1-30/5) in every field.
+func Parse(expr string) (Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return Schedule{}, errors.New("cron: expected 5 fields")
+	}
+	var bits [5]uint64
+	for i, p := range parts {
+		b, err := parseField(p, fields[i], i == 4)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("cron: field %d: %w", i+1, err)
+		}
+		bits[i] = b
+	}
+	return Schedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(s string, f field, isDow bool) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		max := f.max
+		if isDow {
+			max = 7
+		}
+		if lo < f.min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range in %q", item)
+		}
+		for v := lo; v <= hi; v += step {
+			if isDow && v == 7 {
+				v = 0
+				bits |= 1
+				break
+			}
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool { return bits&(1<<uint(v)) != 0 }
+
+// Next returns the first matching minute strictly after t.
+// The zero time is returned if nothing matches within five years.
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows the classic cron rule: when both day fields are restricted, either may match.
+func (s Schedule) dayMatches(t time.Time) bool {
+	domOK := has(s.dom, t.Day())
+	dowOK := has(s.dow, int(t.Weekday()))
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dowOK
+	case s.dowStar:
+		return domOK
+	default:
+		return domOK || dowOK
+	}
+}
@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// at builds a UTC minute; 2026-03-02 is a Monday.
+func at(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", at(2026, 3, 2, 10, 7), at(2026, 3, 2, 10, 8)},
+		{"strictly after", "7 10 * * *", at(2026, 3, 2, 10, 7), at(2026, 3, 3, 10, 7)},
+		{"step from star", "*/15 * * * *", at(2026, 3, 2, 10, 7), at(2026, 3, 2, 10, 15)},
+		{"step wraps the hour", "*/15 * * * *", at(2026, 3, 2, 10, 45), at(2026, 3, 2, 11, 0)},
+		{"step from value", "10/20 * * * *", at(2026, 3, 2, 10, 31), at(2026, 3, 2, 10, 50)},
+		{"range", "0 9-11 * * *", at(2026, 3, 2, 11, 0), at(2026, 3, 3, 9, 0)},
+		{"range with step", "0 9-17/4 * * *", at(2026, 3, 2, 10, 0), at(2026, 3, 2, 13, 0)},
+		{"range with step stops at the bound", "0 9-17/4 * * *", at(2026, 3, 2, 17, 0), at(2026, 3, 3, 9, 0)},
+		{"list", "30 8,12,18 * * *", at(2026, 3, 2, 12, 30), at(2026, 3, 2, 18, 30)},
+		{"list of ranges", "0 0 1-2,20-21 * *", at(2026, 3, 3, 0, 0), at(2026, 3, 20, 0, 0)},
+		{"day of month", "0 0 1 * *", at(2026, 1, 15, 0, 0), at(2026, 2, 1, 0, 0)},
+		{"day of month skips short months", "0 0 31 * *", at(2026, 2, 1, 0, 0), at(2026, 3, 31, 0, 0)},
+		{"month", "0 0 1 6 *", at(2026, 3, 2, 0, 0), at(2026, 6, 1, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2026, 1, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"day of week", "0 9 * * 5", at(2026, 3, 2, 10, 0), at(2026, 3, 6, 9, 0)},
+		{"day of week range", "0 9 * * 1-5", at(2026, 3, 6, 10, 0), at(2026, 3, 9, 9, 0)},
+		{"day of week 7 is sunday", "0 0 * * 7", at(2026, 3, 2, 0, 0), at(2026, 3, 8, 0, 0)},
+		{"day of week range to 7", "0 0 * * 6-7", at(2026, 3, 7, 0, 0), at(2026, 3, 8, 0, 0)},
+		// when both day fields are restricted, either may match
+		{"dom or dow: weekday first", "0 0 20 * 5", at(2026, 3, 2, 0, 0), at(2026, 3, 6, 0, 0)},
+		{"dom or dow: date first", "0 0 3 * 5", at(2026, 3, 2, 0, 0), at(2026, 3, 3, 0, 0)},
+		// a stepped star still counts as unrestricted
+		{"stepped dom star defers to dow", "0 0 */1 * 5", at(2026, 3, 2, 0, 0), at(2026, 3, 6, 0, 0)},
+		{"stepped dow star defers to dom", "0 0 10 * */1", at(2026, 3, 2, 0, 0), at(2026, 3, 10, 0, 0)},
+		{"never", "0 0 30 2 *", at(2026, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	s, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 3, 2, 8, 0, 0, 0, loc))
+	if want := time.Date(2026, 3, 2, 9, 0, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 0 0 * *"},
+		{"month out of range", "0 0 * 13 *"},
+		{"day of week out of range", "0 0 * * 8"},
+		{"reversed range", "5-1 * * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"negative step", "*/-1 * * * *"},
+		{"bad step", "*/x * * * *"},
+		{"bad value", "a * * * *"},
+		{"bad range end", "1-x * * * *"},
+		{"empty list item", "1,,2 * * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", tt.expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package currency holds ISO 4217 codes and their minor units.
+// Amounts everywhere in the API are integers in the currency's minor unit.
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Currency struct {
+	Code       string `json:"code"`
+	MinorUnits int    `json:"minor_units"` // digits after the decimal point: USD 2, JPY 0, KWD 3
+}
+
+var (
+	ErrUnknown  = errors.New("unknown currency")
+	ErrOverflow = errors.New("converted amount is too large")
+)
+
+var table = map[string]int{
+	"AED": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "NOK": 2,
+	"NZD": 2, "OMR": 3, "PLN": 2, "QAR": 2, "RON": 2, "RUB": 2, "SAR": 2, "SEK": 2,
+	"SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// Lookup normalises code to upper case and reports whether it is supported.
+func Lookup(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	mu, ok := table[code]
+	if !ok {
+		return Currency{}, ErrUnknown
+	}
+	return Currency{Code: code, MinorUnits: mu}, nil
+}
+
+// Format renders amount minor units as a plain decimal such as "-12.50".
+func (c Currency) Format(amount int64) string {
+	neg := amount < 0
+	if neg {
+		amount = -amount
+	}
+	s := strconv.FormatInt(amount, 10)
+	if c.MinorUnits > 0 {
+		if len(s) <= c.MinorUnits {
+			s = strings.Repeat("0", c.MinorUnits-len(s)+1) + s
+		}
+		s = s[:len(s)-c.MinorUnits] + "." + s[len(s)-c.MinorUnits:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// ParseRate parses a positive decimal rate such as "1.0834" (units of quote per unit of base).
+func ParseRate(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() <= 0 {
+		return nil, errors.New("rate must be a positive decimal")
+	}
+	return r, nil
+}
+
+// Convert turns amount minor units of from into minor units of to at rate,
+// adjusting for differing minor units and rounding down. ErrOverflow is returned
+// if the result does not fit in an int64.
+func Convert(amount int64, from, to Currency, rate *big.Rat) (int64, error) {
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	exp := to.MinorUnits - from.MinorUnits
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil))
+	if exp >= 0 {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+	q := new(big.Int).Quo(v.Num(), v.Denom())
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
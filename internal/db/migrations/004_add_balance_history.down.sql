@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.ix_balance_history_user_created_at;
+DROP TABLE IF EXISTS public.balance_history;
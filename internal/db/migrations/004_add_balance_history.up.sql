@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS public.balance_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delta BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_balance_history_user_created_at
+  ON public.balance_history (user_id, created_at DESC, id DESC);
+
+-- opening row for balances that existed before history tracking
+INSERT INTO public.balance_history (user_id, delta, amount, created_at)
+SELECT b.user_id, b.amount, b.amount, b.last_updated_at
+  FROM public.balances b
+ WHERE NOT EXISTS (SELECT 1 FROM public.balance_history h WHERE h.user_id = b.user_id);
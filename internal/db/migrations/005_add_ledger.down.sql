@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS trg_postings_balanced ON public.postings;
+DROP FUNCTION IF EXISTS public.check_journal_entry_balanced();
+DROP TABLE IF EXISTS public.postings;
+DROP TABLE IF EXISTS public.journal_entries;
+DROP TABLE IF EXISTS public.ledger_accounts;
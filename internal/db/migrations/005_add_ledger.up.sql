@@ -0,0 +1,84 @@
+-- 1) accounts: one per user wallet + system accounts for money entering/leaving
+CREATE TABLE IF NOT EXISTS public.ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL UNIQUE,
+    kind TEXT NOT NULL CHECK (kind IN ('user','system')),
+    user_id UUID UNIQUE REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ledger_accounts_user_kind CHECK ((kind = 'user') = (user_id IS NOT NULL))
+);
+
+INSERT INTO public.ledger_accounts (code, kind) VALUES
+  ('system:funding', 'system'),
+  ('system:payout', 'system'),
+  ('system:opening', 'system')
+ON CONFLICT (code) DO NOTHING;
+
+-- 2) journal entries + postings
+CREATE TABLE IF NOT EXISTS public.journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID REFERENCES transactions(id),
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_journal_entries_transaction_id
+  ON public.journal_entries (transaction_id);
+
+CREATE TABLE IF NOT EXISTS public.postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    direction TEXT NOT NULL CHECK (direction IN ('debit','credit')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_postings_entry_id ON public.postings (entry_id);
+CREATE INDEX IF NOT EXISTS ix_postings_account_id ON public.postings (account_id);
+
+-- 3) every entry must balance (sum of debits = sum of credits) at commit time
+CREATE OR REPLACE FUNCTION public.check_journal_entry_balanced() RETURNS trigger AS $$
+DECLARE
+  diff BIGINT;
+BEGIN
+  SELECT COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END), 0)
+    INTO diff
+    FROM public.postings
+   WHERE entry_id = NEW.entry_id;
+  IF diff <> 0 THEN
+    RAISE EXCEPTION 'journal entry % is not balanced (diff %)', NEW.entry_id, diff;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_postings_balanced ON public.postings;
+CREATE CONSTRAINT TRIGGER trg_postings_balanced
+  AFTER INSERT OR UPDATE ON public.postings
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION public.check_journal_entry_balanced();
+
+-- 4) backfill: existing balances become opening entries so the projection matches the ledger
+INSERT INTO public.ledger_accounts (code, kind, user_id)
+SELECT 'user:' || b.user_id, 'user', b.user_id
+  FROM public.balances b
+ON CONFLICT (code) DO NOTHING;
+
+WITH opening AS (
+  SELECT gen_random_uuid() AS entry_id, b.user_id, b.amount
+    FROM public.balances b
+   WHERE b.amount <> 0
+), entries AS (
+  INSERT INTO public.journal_entries (id, description)
+  SELECT entry_id, 'opening balance' FROM opening
+  RETURNING id
+)
+INSERT INTO public.postings (entry_id, account_id, direction, amount)
+SELECT o.entry_id, a.id, 'credit', o.amount
+  FROM opening o
+  JOIN public.ledger_accounts a ON a.user_id = o.user_id
+UNION ALL
+SELECT o.entry_id, s.id, 'debit', o.amount
+  FROM opening o
+  JOIN public.ledger_accounts s ON s.code = 'system:opening';
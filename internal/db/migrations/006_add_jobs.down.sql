@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.ux_jobs_kind_transaction;
+DROP INDEX IF EXISTS public.ix_jobs_claimable;
+DROP TABLE IF EXISTS public.jobs;
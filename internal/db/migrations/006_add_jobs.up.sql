@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS public.jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued','running','done','failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_at TIMESTAMPTZ,
+    locked_by TEXT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- workers only scan claimable rows
+CREATE INDEX IF NOT EXISTS ix_jobs_claimable
+  ON public.jobs (run_at)
+  WHERE status IN ('queued','running');
+
+-- at most one job per transaction, so enqueue and recovery are idempotent
+CREATE UNIQUE INDEX IF NOT EXISTS ux_jobs_kind_transaction
+  ON public.jobs (kind, transaction_id)
+  WHERE transaction_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.ix_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS public.idempotency_keys;
@@ -0,0 +1,15 @@
+-- idempotency keys scoped per user + endpoint; status_code is NULL while the first request is in flight
+CREATE TABLE IF NOT EXISTS public.idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT,
+    response_body JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, endpoint, key)
+);
+
+CREATE INDEX IF NOT EXISTS ix_idempotency_keys_expires_at
+  ON public.idempotency_keys (expires_at);
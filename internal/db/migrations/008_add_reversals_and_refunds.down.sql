@@ -0,0 +1,12 @@
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back'));
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer'));
+
+DROP INDEX IF EXISTS public.ix_tx_parent_id;
+ALTER TABLE public.transactions DROP COLUMN IF EXISTS parent_id;
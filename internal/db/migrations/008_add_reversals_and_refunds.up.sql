@@ -0,0 +1,16 @@
+ALTER TABLE public.transactions
+  ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES transactions(id);
+
+CREATE INDEX IF NOT EXISTS ix_tx_parent_id
+  ON public.transactions (parent_id)
+  WHERE parent_id IS NOT NULL;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund'));
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back','reversed','refunded'));
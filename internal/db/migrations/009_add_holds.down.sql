@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS public.ix_holds_authorized_expires_at;
+DROP INDEX IF EXISTS public.ix_holds_user_created_at;
+DROP TABLE IF EXISTS public.holds;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund'));
+
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_held_within_amount;
+ALTER TABLE public.balances DROP COLUMN IF EXISTS held_amount;
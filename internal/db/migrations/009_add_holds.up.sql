@@ -0,0 +1,43 @@
+-- 1) balances: reserved funds; available = amount - held_amount
+ALTER TABLE public.balances
+  ADD COLUMN IF NOT EXISTS held_amount BIGINT NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint
+     WHERE conrelid = 'public.balances'::regclass
+       AND conname = 'balances_held_within_amount'
+  ) THEN
+    ALTER TABLE public.balances
+      ADD CONSTRAINT balances_held_within_amount CHECK (held_amount >= 0 AND held_amount <= amount);
+  END IF;
+END$$;
+
+-- 2) transactions: two-phase payment types
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void'));
+
+-- 3) holds
+CREATE TABLE IF NOT EXISTS public.holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    payee_user_id UUID REFERENCES users(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0 CHECK (captured_amount >= 0),
+    status TEXT NOT NULL CHECK (status IN ('authorized','captured','voided','expired')),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT holds_captured_within_amount CHECK (captured_amount <= amount)
+);
+
+CREATE INDEX IF NOT EXISTS ix_holds_user_created_at
+  ON public.holds (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS ix_holds_authorized_expires_at
+  ON public.holds (expires_at)
+  WHERE status = 'authorized';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.schedule_runs;
+DROP INDEX IF EXISTS public.ix_schedules_due;
+DROP INDEX IF EXISTS public.ix_schedules_user_created_at;
+DROP TABLE IF EXISTS public.schedules;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS public.schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    to_user_id UUID NOT NULL REFERENCES users(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    frequency TEXT NOT NULL CHECK (frequency IN ('once','daily','weekly','monthly','cron')),
+    cron_expr TEXT,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ,
+    next_run_at TIMESTAMPTZ,
+    next_attempt_at TIMESTAMPTZ,
+    attempt INT NOT NULL DEFAULT 0,
+    max_retries INT NOT NULL DEFAULT 3 CHECK (max_retries >= 0),
+    retry_interval INT NOT NULL DEFAULT 3600 CHECK (retry_interval >= 0),
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','paused','completed','cancelled')),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT schedules_cron_expr CHECK ((frequency = 'cron') = (cron_expr IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS ix_schedules_user_created_at
+  ON public.schedules (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS ix_schedules_due
+  ON public.schedules (next_attempt_at)
+  WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS public.schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    attempt INT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('succeeded','failed')),
+    transaction_id UUID REFERENCES transactions(id),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (schedule_id, occurrence_at, attempt)
+);
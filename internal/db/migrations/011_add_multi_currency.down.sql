@@ -0,0 +1,29 @@
+DROP INDEX IF EXISTS public.ix_fx_conversions_user_created_at;
+DROP TABLE IF EXISTS public.fx_conversions;
+DROP INDEX IF EXISTS public.ix_fx_rates_pair_created_at;
+DROP TABLE IF EXISTS public.fx_rates;
+
+DROP INDEX IF EXISTS public.ux_ledger_accounts_user_currency;
+UPDATE public.ledger_accounts
+   SET code = left(code, length(code) - 4)
+ WHERE code LIKE '%:___';
+ALTER TABLE public.ledger_accounts ADD CONSTRAINT ledger_accounts_user_id_key UNIQUE (user_id);
+ALTER TABLE public.ledger_accounts DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void'));
+
+ALTER TABLE public.schedules DROP COLUMN IF EXISTS currency;
+ALTER TABLE public.holds DROP COLUMN IF EXISTS currency;
+ALTER TABLE public.transactions DROP COLUMN IF EXISTS currency;
+
+DROP INDEX IF EXISTS public.ix_balance_history_user_currency_created_at;
+ALTER TABLE public.balance_history DROP COLUMN IF EXISTS currency;
+CREATE INDEX IF NOT EXISTS ix_balance_history_user_created_at
+  ON public.balance_history (user_id, created_at DESC, id DESC);
+
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_pkey;
+ALTER TABLE public.balances DROP COLUMN IF EXISTS currency;
+ALTER TABLE public.balances ADD CONSTRAINT balances_pkey PRIMARY KEY (user_id);
@@ -0,0 +1,83 @@
+-- Existing money is treated as USD; new rows must name their currency.
+-- Until this migration the service only handled USD, so every 'USD' below is the
+-- currency of the rows written before it, not a default for new ones (each
+-- default is dropped right after the backfill). A deployment that ran in another
+-- currency must replace every 'USD' and ':USD' in this file with its own code
+-- before upgrading past 010; 028 rejects anything that is not an upper-case
+-- three-letter code.
+
+-- 1) balances: one row per (user, currency)
+ALTER TABLE public.balances
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE public.balances ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_pkey;
+ALTER TABLE public.balances ADD CONSTRAINT balances_pkey PRIMARY KEY (user_id, currency);
+
+ALTER TABLE public.balance_history
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE public.balance_history ALTER COLUMN currency DROP DEFAULT;
+DROP INDEX IF EXISTS public.ix_balance_history_user_created_at;
+CREATE INDEX IF NOT EXISTS ix_balance_history_user_currency_created_at
+  ON public.balance_history (user_id, currency, created_at DESC, id DESC);
+
+-- 2) currency on everything that carries an amount
+ALTER TABLE public.transactions
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE public.transactions ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE public.holds
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE public.holds ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE public.schedules
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE public.schedules ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion'));
+
+-- 3) ledger accounts are per currency: user:<id>:<CUR>, system:<name>:<CUR>
+ALTER TABLE public.ledger_accounts ADD COLUMN IF NOT EXISTS currency TEXT;
+UPDATE public.ledger_accounts
+   SET code = code || ':USD', currency = 'USD'
+ WHERE currency IS NULL;
+ALTER TABLE public.ledger_accounts ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE public.ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_user_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ledger_accounts_user_currency
+  ON public.ledger_accounts (user_id, currency)
+  WHERE user_id IS NOT NULL;
+
+-- 4) FX rates: append-only, the latest row per pair is the current rate
+CREATE TABLE IF NOT EXISTS public.fx_rates (
+    id BIGSERIAL PRIMARY KEY,
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate NUMERIC(30,12) NOT NULL CHECK (rate > 0),
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fx_rates_distinct_pair CHECK (base_currency <> quote_currency)
+);
+
+CREATE INDEX IF NOT EXISTS ix_fx_rates_pair_created_at
+  ON public.fx_rates (base_currency, quote_currency, created_at DESC, id DESC);
+
+-- 5) conversions: the rate actually applied and the two legs it produced
+CREATE TABLE IF NOT EXISTS public.fx_conversions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    to_user_id UUID NOT NULL REFERENCES users(id),
+    from_currency TEXT NOT NULL,
+    to_currency TEXT NOT NULL,
+    from_amount BIGINT NOT NULL CHECK (from_amount > 0),
+    to_amount BIGINT NOT NULL CHECK (to_amount > 0),
+    rate NUMERIC(30,12) NOT NULL,
+    fx_rate_id BIGINT NOT NULL REFERENCES fx_rates(id),
+    debit_transaction_id UUID NOT NULL REFERENCES transactions(id),
+    credit_transaction_id UUID NOT NULL REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_fx_conversions_user_created_at
+  ON public.fx_conversions (user_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.transaction_batch_legs;
+DROP INDEX IF EXISTS public.ix_transaction_batches_user_created_at;
+DROP TABLE IF EXISTS public.transaction_batches;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS public.transaction_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    currency TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('atomic','best_effort')),
+    status TEXT NOT NULL CHECK (status IN ('completed','partial','failed')),
+    total_amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_transaction_batches_user_created_at
+  ON public.transaction_batches (user_id, created_at DESC);
+
+-- one row per requested leg, including those that failed or never ran;
+-- to_user_id is not a foreign key because unknown recipients are recorded too
+CREATE TABLE IF NOT EXISTS public.transaction_batch_legs (
+    batch_id UUID NOT NULL REFERENCES transaction_batches(id) ON DELETE CASCADE,
+    idx INT NOT NULL,
+    to_user_id TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('succeeded','failed','skipped')),
+    transaction_id UUID REFERENCES transactions(id),
+    error TEXT,
+    PRIMARY KEY (batch_id, idx)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.limit_policies;
+ALTER TABLE public.users DROP COLUMN IF EXISTS tier;
@@ -0,0 +1,20 @@
+-- 1) users: tier picks the limit policy alongside role
+ALTER TABLE public.users
+  ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT 'standard';
+
+-- 2) limit policies; the most specific match wins: user > tier > role, exact type > '*'
+CREATE TABLE IF NOT EXISTS public.limit_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope TEXT NOT NULL CHECK (scope IN ('role','tier','user')),
+    subject TEXT NOT NULL, -- role name, tier name or user id
+    txn_type TEXT NOT NULL DEFAULT '*' CHECK (txn_type IN ('*','credit','debit','transfer')),
+    currency TEXT NOT NULL,
+    max_amount BIGINT CHECK (max_amount > 0),
+    daily_amount BIGINT CHECK (daily_amount > 0),
+    monthly_amount BIGINT CHECK (monthly_amount > 0),
+    daily_count INT CHECK (daily_count > 0),
+    monthly_count INT CHECK (monthly_count > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (scope, subject, txn_type, currency)
+);
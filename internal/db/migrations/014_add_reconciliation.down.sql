@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS public.ix_reconciliation_drifts_run_id;
+DROP TABLE IF EXISTS public.reconciliation_drifts;
+DROP INDEX IF EXISTS public.ix_reconciliation_runs_started_at;
+DROP INDEX IF EXISTS public.ux_reconciliation_runs_running;
+DROP TABLE IF EXISTS public.reconciliation_runs;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion'));
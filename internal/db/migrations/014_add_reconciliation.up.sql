@@ -0,0 +1,42 @@
+-- 1) adjustments correct a balance towards the folded transaction history
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment'));
+
+-- 2) runs; at most one may be running at a time
+CREATE TABLE IF NOT EXISTS public.reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status TEXT NOT NULL CHECK (status IN ('running','completed','failed')),
+    adjust BOOLEAN NOT NULL DEFAULT false,
+    balances_checked INT NOT NULL DEFAULT 0,
+    drifts_found INT NOT NULL DEFAULT 0,
+    adjustments_made INT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reconciliation_runs_running
+  ON public.reconciliation_runs ((true))
+  WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS ix_reconciliation_runs_started_at
+  ON public.reconciliation_runs (started_at DESC);
+
+-- 3) drift report: drift = actual - expected
+CREATE TABLE IF NOT EXISTS public.reconciliation_drifts (
+    id BIGSERIAL PRIMARY KEY,
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    currency TEXT NOT NULL,
+    expected BIGINT NOT NULL,
+    actual BIGINT NOT NULL,
+    drift BIGINT NOT NULL,
+    adjustment_transaction_id UUID REFERENCES transactions(id),
+    adjustment_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_reconciliation_drifts_run_id
+  ON public.reconciliation_drifts (run_id, id);
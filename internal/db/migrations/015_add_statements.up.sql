@@ -0,0 +1,19 @@
+-- frozen month-end snapshots per (user, currency); never updated once written
+CREATE TABLE IF NOT EXISTS public.statements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL, -- first instant of the calendar month (UTC)
+    period_end TIMESTAMPTZ NOT NULL,   -- exclusive
+    opening_balance BIGINT NOT NULL,
+    closing_balance BIGINT NOT NULL,
+    total_in BIGINT NOT NULL,
+    total_out BIGINT NOT NULL,
+    totals JSONB NOT NULL DEFAULT '{}'::jsonb, -- per type: {"count","in","out"}
+    transaction_count INT NOT NULL,
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, currency, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS ix_statements_period_start
+  ON public.statements (period_start DESC);
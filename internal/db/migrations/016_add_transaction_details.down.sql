@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS public.ux_transactions_external_reference;
+
+ALTER TABLE public.transactions
+  DROP COLUMN IF EXISTS metadata,
+  DROP COLUMN IF EXISTS external_reference,
+  DROP COLUMN IF EXISTS description;
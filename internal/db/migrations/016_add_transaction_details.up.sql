@@ -0,0 +1,10 @@
+ALTER TABLE public.transactions
+  ADD COLUMN IF NOT EXISTS description TEXT,
+  ADD COLUMN IF NOT EXISTS external_reference TEXT,
+  ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+-- an integrator's reference is unique per initiating user: the sender, or the
+-- recipient of a credit
+CREATE UNIQUE INDEX IF NOT EXISTS ux_transactions_external_reference
+  ON public.transactions ((COALESCE(from_user_id, to_user_id)), external_reference)
+  WHERE external_reference IS NOT NULL;
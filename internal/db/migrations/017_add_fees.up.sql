@@ -0,0 +1,22 @@
+-- 1) fee transactions: the payer pays the house revenue account, linked to the
+--    charged transaction through parent_id
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee'));
+
+-- 2) fee rules; a rule for the payer's role beats the '*' rule
+CREATE TABLE IF NOT EXISTS public.fee_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role TEXT NOT NULL DEFAULT '*',
+    txn_type TEXT NOT NULL CHECK (txn_type IN ('transfer','debit')),
+    currency TEXT NOT NULL,
+    flat BIGINT NOT NULL DEFAULT 0 CHECK (flat >= 0),
+    bps INT NOT NULL DEFAULT 0 CHECK (bps BETWEEN 0 AND 10000), -- basis points of the amount
+    tiers JSONB NOT NULL DEFAULT '[]'::jsonb, -- [{"up_to","flat","bps"}], ascending; replaces flat/bps
+    min_fee BIGINT CHECK (min_fee >= 0),
+    max_fee BIGINT CHECK (max_fee >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (role, txn_type, currency)
+);
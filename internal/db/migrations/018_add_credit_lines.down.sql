@@ -0,0 +1,15 @@
+-- fails while any balance is still overdrawn; settle those first
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee'));
+
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_held_nonnegative;
+ALTER TABLE public.balances
+  ADD CONSTRAINT balances_held_within_amount CHECK (held_amount >= 0 AND held_amount <= amount);
+ALTER TABLE public.balances
+  ADD CONSTRAINT balances_amount_nonnegative CHECK (amount >= 0);
+
+ALTER TABLE public.balances
+  DROP COLUMN IF EXISTS overdraft_rate_bps,
+  DROP COLUMN IF EXISTS credit_limit;
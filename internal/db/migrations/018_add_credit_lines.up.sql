@@ -0,0 +1,18 @@
+-- 1) balances: a credit line lets the amount go down to -credit_limit.
+--    available = amount - held_amount + credit_limit. The limit is enforced when
+--    money is spent, not by a CHECK, so an admin can lower a limit below what is
+--    already drawn and interest can still be booked on an exhausted line.
+ALTER TABLE public.balances
+  ADD COLUMN IF NOT EXISTS credit_limit BIGINT NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+  ADD COLUMN IF NOT EXISTS overdraft_rate_bps INT NOT NULL DEFAULT 0 CHECK (overdraft_rate_bps BETWEEN 0 AND 10000);
+
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_amount_nonnegative;
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_held_within_amount;
+ALTER TABLE public.balances
+  ADD CONSTRAINT balances_held_nonnegative CHECK (held_amount >= 0);
+
+-- 2) transactions: daily interest on a drawn credit line, paid to revenue
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest'));
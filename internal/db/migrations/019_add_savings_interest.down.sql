@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.interest_accruals;
+DROP TABLE IF EXISTS public.interest_rates;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest'));
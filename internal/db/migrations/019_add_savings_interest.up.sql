@@ -0,0 +1,33 @@
+-- 1) transactions: monthly savings interest, paid from the house interest account
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest','interest'));
+
+-- 2) interest rate bands per currency; the part of a balance at or above
+--    min_balance (up to the next band) earns apy_bps
+CREATE TABLE IF NOT EXISTS public.interest_rates (
+    currency TEXT NOT NULL,
+    min_balance BIGINT NOT NULL CHECK (min_balance >= 0),
+    apy_bps INT NOT NULL CHECK (apy_bps BETWEEN 0 AND 10000),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (currency, min_balance)
+);
+
+-- 3) one accrual per balance per day. carry is the fraction of a minor unit left
+--    over, in 1/(10000*365) units, and is added to the next day's accrual.
+--    payout_id is set when the interest transaction paying the day is booked.
+CREATE TABLE IF NOT EXISTS public.interest_accruals (
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    day DATE NOT NULL,
+    balance BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    carry BIGINT NOT NULL CHECK (carry >= 0),
+    payout_id UUID REFERENCES public.transactions(id) DEFERRABLE INITIALLY DEFERRED,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, currency, day)
+);
+
+CREATE INDEX IF NOT EXISTS ix_interest_accruals_unpaid
+  ON public.interest_accruals (user_id, currency, day) WHERE payout_id IS NULL;
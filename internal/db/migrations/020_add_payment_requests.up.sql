@@ -0,0 +1,24 @@
+-- a request from requester_id asking payer_id for money. A pending request past
+-- expires_at reads as expired; accepting it pays it through a transfer whose
+-- idempotency key is the request id, linked back through transaction_id.
+CREATE TABLE IF NOT EXISTS public.payment_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requester_id UUID NOT NULL REFERENCES public.users(id),
+    payer_id UUID NOT NULL REFERENCES public.users(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    note TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','paid','declined','cancelled')),
+    expires_at TIMESTAMPTZ,
+    transaction_id UUID REFERENCES public.transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT payment_requests_not_self CHECK (requester_id <> payer_id),
+    CONSTRAINT payment_requests_paid_linked CHECK ((status = 'paid') = (transaction_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS ix_payment_requests_payer_created_at
+  ON public.payment_requests (payer_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS ix_payment_requests_requester_created_at
+  ON public.payment_requests (requester_id, created_at DESC);
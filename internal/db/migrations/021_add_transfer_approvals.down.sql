@@ -0,0 +1,7 @@
+-- fails while any transfer is still awaiting approval or rejected
+DROP INDEX IF EXISTS public.ix_transactions_awaiting_approval;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back','reversed','refunded'));
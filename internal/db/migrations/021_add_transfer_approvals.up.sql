@@ -0,0 +1,10 @@
+-- maker-checker: transfers above the approval threshold wait in awaiting_approval
+-- until a second admin approves (completed) or rejects (rejected) them
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back','reversed','refunded','awaiting_approval','rejected'));
+
+CREATE INDEX IF NOT EXISTS ix_transactions_awaiting_approval
+  ON public.transactions (created_at, id)
+  WHERE status = 'awaiting_approval';
@@ -0,0 +1,7 @@
+-- fails while any transaction is still held for review
+DROP TABLE IF EXISTS public.risk_cases;
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back','reversed','refunded','awaiting_approval','rejected'));
@@ -0,0 +1,21 @@
+-- 1) transactions flagged by risk screening wait in held_for_review
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_status_check
+  CHECK (status IN ('pending','completed','failed','rolled_back','reversed','refunded','awaiting_approval','rejected','held_for_review'));
+
+-- 2) the reviewer queue: one case per held transaction with the rules that fired
+CREATE TABLE IF NOT EXISTS public.risk_cases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL UNIQUE REFERENCES public.transactions(id),
+    decision TEXT NOT NULL CHECK (decision IN ('review','block')),
+    hits JSONB NOT NULL DEFAULT '[]'::jsonb, -- [{"rule","decision","reason"}]
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','released','rejected')),
+    reviewer_id UUID REFERENCES public.users(id),
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    decided_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS ix_risk_cases_status_created_at
+  ON public.risk_cases (status, created_at);
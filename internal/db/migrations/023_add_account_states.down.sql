@@ -0,0 +1,5 @@
+ALTER TABLE public.users
+  DROP COLUMN IF EXISTS state_changed_at,
+  DROP COLUMN IF EXISTS state_until,
+  DROP COLUMN IF EXISTS state_reason,
+  DROP COLUMN IF EXISTS state;
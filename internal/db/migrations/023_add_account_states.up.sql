@@ -0,0 +1,8 @@
+-- account state: compliance can freeze a user's money without deleting the user.
+-- A freeze with state_until lapses by itself; readers treat it as active afterwards.
+ALTER TABLE public.users
+  ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'active'
+    CHECK (state IN ('active','debit_frozen','fully_frozen','closed')),
+  ADD COLUMN IF NOT EXISTS state_reason TEXT,
+  ADD COLUMN IF NOT EXISTS state_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS state_changed_at TIMESTAMPTZ;
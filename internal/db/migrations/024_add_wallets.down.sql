@@ -0,0 +1,29 @@
+-- fails while any balance, ledger account or transaction uses a wallet other than main
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest','interest'));
+
+ALTER TABLE public.transactions
+  DROP COLUMN IF EXISTS to_wallet_id,
+  DROP COLUMN IF EXISTS from_wallet_id;
+
+DROP INDEX IF EXISTS public.ix_ledger_accounts_user_id;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ledger_accounts_user_currency
+  ON public.ledger_accounts (user_id, currency)
+  WHERE user_id IS NOT NULL;
+
+DROP INDEX IF EXISTS public.ix_balance_history_wallet_currency_created_at;
+CREATE INDEX IF NOT EXISTS ix_balance_history_user_currency_created_at
+  ON public.balance_history (user_id, currency, created_at DESC, id DESC);
+ALTER TABLE public.balance_history DROP COLUMN IF EXISTS wallet_id;
+
+DROP INDEX IF EXISTS public.ix_balances_user_id;
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_pkey;
+ALTER TABLE public.balances ADD CONSTRAINT balances_pkey PRIMARY KEY (user_id, currency);
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_wallet_fk;
+ALTER TABLE public.balances DROP COLUMN IF EXISTS wallet_id;
+
+DROP TRIGGER IF EXISTS trg_users_main_wallet ON public.users;
+DROP FUNCTION IF EXISTS public.create_main_wallet();
+DROP TABLE IF EXISTS public.wallets;
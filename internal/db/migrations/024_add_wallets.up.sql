@@ -0,0 +1,66 @@
+-- wallets: named pots per user, each with its own balance per currency.
+-- A user's main wallet shares the user's id, so balances, history and ledger
+-- accounts written before wallets existed all belong to it unchanged.
+
+-- 1) wallets, with a main wallet for every existing user
+CREATE TABLE IF NOT EXISTS public.wallets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(btrim(name)) > 0),
+    kind TEXT NOT NULL CHECK (kind IN ('main','savings','custom')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name),
+    CONSTRAINT wallets_main_is_user CHECK ((kind = 'main') = (id = user_id))
+);
+
+INSERT INTO public.wallets (id, user_id, name, kind)
+SELECT u.id, u.id, 'main', 'main'
+  FROM public.users u
+ON CONFLICT (id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION public.create_main_wallet() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO public.wallets (id, user_id, name, kind)
+  VALUES (NEW.id, NEW.id, 'main', 'main')
+  ON CONFLICT (id) DO NOTHING;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_users_main_wallet ON public.users;
+CREATE TRIGGER trg_users_main_wallet
+  AFTER INSERT ON public.users
+  FOR EACH ROW EXECUTE FUNCTION public.create_main_wallet();
+
+-- 2) balances: one row per (wallet, currency)
+ALTER TABLE public.balances ADD COLUMN IF NOT EXISTS wallet_id UUID;
+UPDATE public.balances SET wallet_id = user_id WHERE wallet_id IS NULL;
+ALTER TABLE public.balances ALTER COLUMN wallet_id SET NOT NULL;
+ALTER TABLE public.balances
+  ADD CONSTRAINT balances_wallet_fk FOREIGN KEY (wallet_id) REFERENCES public.wallets(id);
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_pkey;
+ALTER TABLE public.balances ADD CONSTRAINT balances_pkey PRIMARY KEY (wallet_id, currency);
+CREATE INDEX IF NOT EXISTS ix_balances_user_id ON public.balances (user_id);
+
+ALTER TABLE public.balance_history ADD COLUMN IF NOT EXISTS wallet_id UUID;
+UPDATE public.balance_history SET wallet_id = user_id WHERE wallet_id IS NULL;
+ALTER TABLE public.balance_history ALTER COLUMN wallet_id SET NOT NULL;
+DROP INDEX IF EXISTS public.ix_balance_history_user_currency_created_at;
+CREATE INDEX IF NOT EXISTS ix_balance_history_wallet_currency_created_at
+  ON public.balance_history (wallet_id, currency, created_at DESC, id DESC);
+
+-- 3) ledger: user:<user>:<CUR> stays the main wallet, user:<user>:<wallet>:<CUR> is any other
+DROP INDEX IF EXISTS public.ux_ledger_accounts_user_currency;
+CREATE INDEX IF NOT EXISTS ix_ledger_accounts_user_id
+  ON public.ledger_accounts (user_id)
+  WHERE user_id IS NOT NULL;
+
+-- 4) transactions: the wallets money left and arrived in; NULL is the main wallet
+ALTER TABLE public.transactions
+  ADD COLUMN IF NOT EXISTS from_wallet_id UUID REFERENCES public.wallets(id),
+  ADD COLUMN IF NOT EXISTS to_wallet_id UUID REFERENCES public.wallets(id);
+
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest','interest','move'));
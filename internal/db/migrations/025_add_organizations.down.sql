@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.ix_organization_members_user_id;
+DROP TABLE IF EXISTS public.organization_members;
+DROP TABLE IF EXISTS public.organizations;
+-- the organizations' users rows stay: they still own balances and transactions
@@ -0,0 +1,23 @@
+-- organizations: shared accounts for B2B customers. An organization holds money the
+-- way a user does, through a users row of role 'organization' that shares its id and
+-- can't sign in, so balances, wallets, limits and the ledger apply unchanged.
+CREATE TABLE IF NOT EXISTS public.organizations (
+    id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL UNIQUE CHECK (length(btrim(name)) > 0),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- members act for the organization; the role decides what they may do
+CREATE TABLE IF NOT EXISTS public.organization_members (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner','approver','viewer')),
+    added_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS ix_organization_members_user_id
+  ON public.organization_members (user_id);
@@ -0,0 +1,10 @@
+-- fails while any chargeback transaction exists
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest','interest','move'));
+
+DROP INDEX IF EXISTS public.ix_disputes_active;
+DROP INDEX IF EXISTS public.ix_disputes_recipient_created_at;
+DROP INDEX IF EXISTS public.ix_disputes_sender_created_at;
+DROP TABLE IF EXISTS public.disputes;
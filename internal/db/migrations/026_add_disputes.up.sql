@@ -0,0 +1,43 @@
+-- disputes: the sender of a completed transfer contests it. While the dispute is
+-- open or responded, held_amount of it is held on the recipient's wallet
+-- (hold_wallet_id); an admin either charges the transfer back or rejects the
+-- dispute, which releases the hold. One dispute per transfer, ever.
+CREATE TABLE IF NOT EXISTS public.disputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL UNIQUE REFERENCES public.transactions(id),
+    sender_id UUID NOT NULL REFERENCES public.users(id),
+    recipient_id UUID NOT NULL REFERENCES public.users(id),
+    hold_wallet_id UUID NOT NULL REFERENCES public.wallets(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    held_amount BIGINT NOT NULL CHECK (held_amount >= 0 AND held_amount <= amount),
+    currency TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    evidence TEXT,
+    response TEXT,
+    status TEXT NOT NULL DEFAULT 'open'
+      CHECK (status IN ('open','responded','withdrawn','charged_back','rejected')),
+    resolved_by UUID REFERENCES public.users(id),
+    resolution_note TEXT,
+    chargeback_id UUID REFERENCES public.transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT disputes_charged_back_linked CHECK ((status = 'charged_back') = (chargeback_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS ix_disputes_sender_created_at
+  ON public.disputes (sender_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS ix_disputes_recipient_created_at
+  ON public.disputes (recipient_id, created_at DESC);
+
+-- the admin queue
+CREATE INDEX IF NOT EXISTS ix_disputes_active
+  ON public.disputes (created_at)
+  WHERE status IN ('open','responded');
+
+-- a chargeback returns a disputed transfer to its sender
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE public.transactions
+  ADD CONSTRAINT transactions_type_check
+  CHECK (type IN ('credit','debit','transfer','reversal','refund','authorization','capture','void','conversion','adjustment','fee','overdraft_interest','interest','move','chargeback'));
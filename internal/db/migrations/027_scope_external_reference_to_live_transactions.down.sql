@@ -0,0 +1,6 @@
+-- fails while a reference is shared by a live transaction and a failed, rolled
+-- back or rejected one
+DROP INDEX IF EXISTS public.ux_transactions_external_reference;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_transactions_external_reference
+  ON public.transactions ((COALESCE(from_user_id, to_user_id)), external_reference)
+  WHERE external_reference IS NOT NULL;
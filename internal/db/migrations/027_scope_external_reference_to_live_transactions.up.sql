@@ -0,0 +1,7 @@
+-- a reference only stays taken while its transaction can still move money, so a
+-- transfer that failed, was rolled back or was rejected can be retried with it
+DROP INDEX IF EXISTS public.ux_transactions_external_reference;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_transactions_external_reference
+  ON public.transactions ((COALESCE(from_user_id, to_user_id)), external_reference)
+  WHERE external_reference IS NOT NULL
+    AND status NOT IN ('failed','rolled_back','rejected');
@@ -0,0 +1,40 @@
+-- every currency column holds an upper-case ISO 4217 code; which codes are
+-- supported is up to the application (internal/currency)
+ALTER TABLE public.balances DROP CONSTRAINT IF EXISTS balances_currency_check;
+ALTER TABLE public.balances ADD CONSTRAINT balances_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.balance_history DROP CONSTRAINT IF EXISTS balance_history_currency_check;
+ALTER TABLE public.balance_history ADD CONSTRAINT balance_history_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.transactions DROP CONSTRAINT IF EXISTS transactions_currency_check;
+ALTER TABLE public.transactions ADD CONSTRAINT transactions_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.holds DROP CONSTRAINT IF EXISTS holds_currency_check;
+ALTER TABLE public.holds ADD CONSTRAINT holds_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.schedules DROP CONSTRAINT IF EXISTS schedules_currency_check;
+ALTER TABLE public.schedules ADD CONSTRAINT schedules_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_currency_check;
+ALTER TABLE public.ledger_accounts ADD CONSTRAINT ledger_accounts_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.fx_rates DROP CONSTRAINT IF EXISTS fx_rates_base_currency_check;
+ALTER TABLE public.fx_rates ADD CONSTRAINT fx_rates_base_currency_check CHECK (base_currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.fx_rates DROP CONSTRAINT IF EXISTS fx_rates_quote_currency_check;
+ALTER TABLE public.fx_rates ADD CONSTRAINT fx_rates_quote_currency_check CHECK (quote_currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.fx_conversions DROP CONSTRAINT IF EXISTS fx_conversions_from_currency_check;
+ALTER TABLE public.fx_conversions ADD CONSTRAINT fx_conversions_from_currency_check CHECK (from_currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.fx_conversions DROP CONSTRAINT IF EXISTS fx_conversions_to_currency_check;
+ALTER TABLE public.fx_conversions ADD CONSTRAINT fx_conversions_to_currency_check CHECK (to_currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.transaction_batches DROP CONSTRAINT IF EXISTS transaction_batches_currency_check;
+ALTER TABLE public.transaction_batches ADD CONSTRAINT transaction_batches_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.limit_policies DROP CONSTRAINT IF EXISTS limit_policies_currency_check;
+ALTER TABLE public.limit_policies ADD CONSTRAINT limit_policies_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.reconciliation_drifts DROP CONSTRAINT IF EXISTS reconciliation_drifts_currency_check;
+ALTER TABLE public.reconciliation_drifts ADD CONSTRAINT reconciliation_drifts_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.statements DROP CONSTRAINT IF EXISTS statements_currency_check;
+ALTER TABLE public.statements ADD CONSTRAINT statements_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.fee_rules DROP CONSTRAINT IF EXISTS fee_rules_currency_check;
+ALTER TABLE public.fee_rules ADD CONSTRAINT fee_rules_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.interest_rates DROP CONSTRAINT IF EXISTS interest_rates_currency_check;
+ALTER TABLE public.interest_rates ADD CONSTRAINT interest_rates_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.interest_accruals DROP CONSTRAINT IF EXISTS interest_accruals_currency_check;
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.payment_requests DROP CONSTRAINT IF EXISTS payment_requests_currency_check;
+ALTER TABLE public.payment_requests ADD CONSTRAINT payment_requests_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE public.disputes DROP CONSTRAINT IF EXISTS disputes_currency_check;
+ALTER TABLE public.disputes ADD CONSTRAINT disputes_currency_check CHECK (currency ~ '^[A-Z]{3}$');
@@ -0,0 +1,7 @@
+-- fails while any request is rejected
+ALTER TABLE public.payment_requests DROP CONSTRAINT IF EXISTS payment_requests_status_check;
+ALTER TABLE public.payment_requests
+  ADD CONSTRAINT payment_requests_status_check
+  CHECK (status IN ('pending','paid','declined','cancelled'));
+
+ALTER TABLE public.payment_requests DROP COLUMN IF EXISTS pending_transaction_id;
@@ -0,0 +1,10 @@
+-- an accepted request whose transfer waits for approval or risk review records
+-- it in pending_transaction_id; the request can't be declined or cancelled while
+-- that transfer may still move money, and becomes rejected if it is rejected
+ALTER TABLE public.payment_requests
+  ADD COLUMN IF NOT EXISTS pending_transaction_id UUID REFERENCES public.transactions(id);
+
+ALTER TABLE public.payment_requests DROP CONSTRAINT IF EXISTS payment_requests_status_check;
+ALTER TABLE public.payment_requests
+  ADD CONSTRAINT payment_requests_status_check
+  CHECK (status IN ('pending','paid','declined','cancelled','rejected'));
@@ -0,0 +1,16 @@
+-- fails while any accrual or statement belongs to a wallet other than the main one
+DROP INDEX IF EXISTS public.ix_statements_user_id;
+ALTER TABLE public.statements DROP CONSTRAINT IF EXISTS statements_wallet_id_currency_period_start_key;
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_user_id_currency_period_start_key UNIQUE (user_id, currency, period_start);
+ALTER TABLE public.statements DROP CONSTRAINT IF EXISTS statements_wallet_fk;
+ALTER TABLE public.statements DROP COLUMN IF EXISTS wallet_id;
+
+DROP INDEX IF EXISTS public.ix_interest_accruals_unpaid;
+DROP INDEX IF EXISTS public.ix_interest_accruals_user_id;
+ALTER TABLE public.interest_accruals DROP CONSTRAINT IF EXISTS interest_accruals_pkey;
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_pkey PRIMARY KEY (user_id, currency, day);
+ALTER TABLE public.interest_accruals DROP CONSTRAINT IF EXISTS interest_accruals_wallet_fk;
+ALTER TABLE public.interest_accruals DROP COLUMN IF EXISTS wallet_id;
+CREATE INDEX IF NOT EXISTS ix_interest_accruals_unpaid
+  ON public.interest_accruals (user_id, currency, day) WHERE payout_id IS NULL;
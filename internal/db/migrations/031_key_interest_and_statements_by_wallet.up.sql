@@ -0,0 +1,30 @@
+-- savings interest and monthly statements are kept per wallet, not only for the
+-- main wallet. Rows written before this belong to the main wallet, whose id is
+-- the user's.
+
+-- 1) interest_accruals: one accrual per wallet balance per day
+ALTER TABLE public.interest_accruals ADD COLUMN IF NOT EXISTS wallet_id UUID;
+UPDATE public.interest_accruals SET wallet_id = user_id WHERE wallet_id IS NULL;
+ALTER TABLE public.interest_accruals ALTER COLUMN wallet_id SET NOT NULL;
+ALTER TABLE public.interest_accruals
+  ADD CONSTRAINT interest_accruals_wallet_fk FOREIGN KEY (wallet_id) REFERENCES public.wallets(id);
+ALTER TABLE public.interest_accruals DROP CONSTRAINT IF EXISTS interest_accruals_pkey;
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_pkey PRIMARY KEY (wallet_id, currency, day);
+CREATE INDEX IF NOT EXISTS ix_interest_accruals_user_id
+  ON public.interest_accruals (user_id, day DESC);
+
+DROP INDEX IF EXISTS public.ix_interest_accruals_unpaid;
+CREATE INDEX IF NOT EXISTS ix_interest_accruals_unpaid
+  ON public.interest_accruals (wallet_id, currency, day) WHERE payout_id IS NULL;
+
+-- 2) statements: one per wallet, currency and month
+ALTER TABLE public.statements ADD COLUMN IF NOT EXISTS wallet_id UUID;
+UPDATE public.statements SET wallet_id = user_id WHERE wallet_id IS NULL;
+ALTER TABLE public.statements ALTER COLUMN wallet_id SET NOT NULL;
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_wallet_fk FOREIGN KEY (wallet_id) REFERENCES public.wallets(id);
+ALTER TABLE public.statements DROP CONSTRAINT IF EXISTS statements_user_id_currency_period_start_key;
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_wallet_id_currency_period_start_key UNIQUE (wallet_id, currency, period_start);
+CREATE INDEX IF NOT EXISTS ix_statements_user_id
+  ON public.statements (user_id, period_start DESC);
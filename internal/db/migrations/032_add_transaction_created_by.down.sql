@@ -0,0 +1 @@
+ALTER TABLE public.transactions DROP COLUMN IF EXISTS created_by;
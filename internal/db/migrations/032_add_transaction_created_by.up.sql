@@ -0,0 +1,6 @@
+-- the user who made a transfer. It is the sender, except for an organization's
+-- transfer, which the sending member makes; approval checks it so nobody
+-- approves a transfer they made themselves. NULL on older rows and on
+-- transactions no user initiated.
+ALTER TABLE public.transactions
+  ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES public.users(id);
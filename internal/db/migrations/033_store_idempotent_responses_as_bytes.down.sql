@@ -0,0 +1,5 @@
+-- fails while a stored response body isn't JSON
+ALTER TABLE public.idempotency_keys DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE public.idempotency_keys DROP COLUMN IF EXISTS content_type;
+ALTER TABLE public.idempotency_keys
+  ALTER COLUMN response_body TYPE JSONB USING convert_from(response_body, 'UTF8')::jsonb;
@@ -0,0 +1,13 @@
+-- replays must be byte-identical and not every response is JSON, so the body is
+-- kept as sent, with its Content-Type
+ALTER TABLE public.idempotency_keys
+  ALTER COLUMN response_body TYPE BYTEA USING convert_to(response_body::text, 'UTF8');
+ALTER TABLE public.idempotency_keys ADD COLUMN IF NOT EXISTS content_type TEXT;
+UPDATE public.idempotency_keys SET content_type = 'application/json; charset=utf-8'
+ WHERE status_code IS NOT NULL AND content_type IS NULL;
+
+-- a request in flight holds its key until locked_until; a retry after that takes
+-- the key over, so a key isn't stuck until expires_at when its request died.
+-- NULL once the response is stored.
+ALTER TABLE public.idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+UPDATE public.idempotency_keys SET locked_until = created_at WHERE status_code IS NULL;
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/baharkarakas/insider-backend/internal/api/httpx"
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+const maxIdempotentBody = 1 << 20
+
+type IdempotencyStore interface {
+	Begin(userID, endpoint, key, requestHash string) (models.IdempotencyKey, bool, error)
+	Finish(userID, endpoint, key string, status int, contentType string, body []byte) error
+	Abort(userID, endpoint, key string) error
+}
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *bodyRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency replays the stored response, byte for byte and with its Content-Type,
+// when the same Idempotency-Key is sent again for endpoint by the same user. Reusing
+// a key with a different body is rejected with 422; a retry while the first request
+// still holds the key gets 409, and takes the key over once the store's lease on it
+// runs out. Requests without the header pass straight through. Must run after Auth.
+func Idempotency(store IdempotencyStore, endpoint string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			uid, _ := UserID(r.Context())
+			if key == "" || uid == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// one byte past the limit tells an oversized body from one that fits exactly
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+			if err != nil {
+				httpx.WriteError(w, http.StatusBadRequest, "bad_request", "cannot read body", nil)
+				return
+			}
+			if len(body) > maxIdempotentBody {
+				httpx.WriteError(w, http.StatusRequestEntityTooLarge, "body_too_large", "request body must be at most 1 MiB", nil)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+			hash := hex.EncodeToString(sum[:])
+
+			rec, fresh, err := store.Begin(uid, endpoint, key, hash)
+			if err != nil {
+				httpx.WriteError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+				return
+			}
+			if !fresh {
+				switch {
+				case rec.RequestHash != hash:
+					httpx.WriteError(w, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used with a different payload", nil)
+				case rec.StatusCode == nil:
+					httpx.WriteError(w, http.StatusConflict, "idempotency_in_progress", "a request with this Idempotency-Key is still in progress", nil)
+				default:
+					if rec.ContentType != "" {
+						w.Header().Set("Content-Type", rec.ContentType)
+					}
+					w.Header().Set("Idempotent-Replayed", "true")
+					w.WriteHeader(*rec.StatusCode)
+					_, _ = w.Write(rec.ResponseBody)
+				}
+				return
+			}
+
+			bw := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if p := recover(); p != nil {
+					_ = store.Abort(uid, endpoint, key)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(bw, r)
+
+			// server errors aren't final, let the client retry
+			if bw.status >= http.StatusInternalServerError {
+				if err := store.Abort(uid, endpoint, key); err != nil {
+					slog.Error("idempotency abort", "endpoint", endpoint, "err", err)
+				}
+				return
+			}
+			if err := store.Finish(uid, endpoint, key, bw.status, bw.Header().Get("Content-Type"), bw.buf.Bytes()); err != nil {
+				slog.Error("idempotency finish", "endpoint", endpoint, "err", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+// memIdem keeps keys in memory with the takeover rules of the postgres repo, on
+// a clock the test moves.
+type memIdem struct {
+	now   time.Time
+	lease time.Duration
+	keys  map[string]*models.IdempotencyKey
+}
+
+func (m *memIdem) Begin(userID, endpoint, key, requestHash string) (models.IdempotencyKey, bool, error) {
+	id := userID + "|" + endpoint + "|" + key
+	if k, ok := m.keys[id]; ok {
+		stale := k.StatusCode == nil && !k.LockedUntil.After(m.now) && k.RequestHash == requestHash
+		if !stale {
+			return *k, false, nil
+		}
+	}
+	until := m.now.Add(m.lease)
+	k := &models.IdempotencyKey{UserID: userID, Endpoint: endpoint, Key: key, RequestHash: requestHash, LockedUntil: &until}
+	m.keys[id] = k
+	return *k, true, nil
+}
+
+func (m *memIdem) Finish(userID, endpoint, key string, status int, contentType string, body []byte) error {
+	k := m.keys[userID+"|"+endpoint+"|"+key]
+	if k.StatusCode == nil {
+		k.StatusCode, k.ContentType, k.ResponseBody, k.LockedUntil = &status, contentType, body, nil
+	}
+	return nil
+}
+
+func (m *memIdem) Abort(userID, endpoint, key string) error {
+	delete(m.keys, userID+"|"+endpoint+"|"+key)
+	return nil
+}
+
+func hashOf(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func idemRequest(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Idempotency-Key", key)
+	r = r.WithContext(context.WithValue(r.Context(), ctxUserIDKey, "u1"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyReplaysBytes(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+	}{
+		{"json as written", "application/json; charset=utf-8", `{"z": 1,   "a":[2, 1]}` + "\n"},
+		{"csv", "text/csv; charset=utf-8", "id,amount\r\nt1,1.50\r\n"},
+		{"binary", "application/octet-stream", "\x00\xff\xfe{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memIdem{now: time.Now(), lease: time.Minute, keys: map[string]*models.IdempotencyKey{}}
+			runs := 0
+			h := Idempotency(store, "test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			first := idemRequest(h, "k1", `{"amount":1}`)
+			again := idemRequest(h, "k1", `{"amount":1}`)
+			if runs != 1 {
+				t.Fatalf("handler ran %d times", runs)
+			}
+			if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() ||
+				again.Header().Get("Content-Type") != tt.contentType || again.Header().Get("Idempotent-Replayed") != "true" {
+				t.Errorf("replay = %d %q %q, want %d %q %q", again.Code, again.Header().Get("Content-Type"), again.Body.String(),
+					http.StatusCreated, tt.contentType, tt.body)
+			}
+		})
+	}
+}
+
+func TestIdempotencyTakesOverKeyAfterLease(t *testing.T) {
+	store := &memIdem{now: time.Now(), lease: time.Minute, keys: map[string]*models.IdempotencyKey{}}
+	runs := 0
+	h := Idempotency(store, "test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	// the first request reserved the key and its process died
+	if _, fresh, _ := store.Begin("u1", "test", "k1", hashOf(`{"amount":1}`)); !fresh {
+		t.Fatal("key already taken")
+	}
+
+	if w := idemRequest(h, "k1", `{"amount":1}`); w.Code != http.StatusConflict {
+		t.Errorf("retry within the lease: %d, want 409", w.Code)
+	}
+	store.now = store.now.Add(time.Minute)
+	if w := idemRequest(h, "k1", `{"amount":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other payload after the lease: %d, want 422", w.Code)
+	}
+	if w := idemRequest(h, "k1", `{"amount":1}`); w.Code != http.StatusCreated || runs != 1 {
+		t.Errorf("retry after the lease: %d after %d runs, want 201 after 1", w.Code, runs)
+	}
+	if w := idemRequest(h, "k1", `{"amount":1}`); w.Code != http.StatusCreated || runs != 1 || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry once finished: %d after %d runs, want a replayed 201", w.Code, runs)
+	}
+}
@@ -5,7 +5,24 @@ import "time"
 // DB satırını temsil eden sade DTO.
 // Concurrency'yi DB (Postgres) hallediyor; burada mutex'e gerek yok.
 type Balance struct {
-	UserID        string    `db:"user_id" json:"user_id"`
-	Amount        int64     `db:"amount" json:"amount"`
-	LastUpdatedAt time.Time `db:"last_updated_at" json:"last_updated_at"`
+	UserID     string `db:"user_id" json:"user_id"`
+	WalletID   string `db:"wallet_id" json:"wallet_id"`
+	Currency   string `db:"currency" json:"currency"`
+	Amount     int64  `db:"amount" json:"amount"`
+	HeldAmount int64  `db:"held_amount" json:"held_amount"`
+	// CreditLimit is how far Amount may go below zero; OverdraftRateBPS is the
+	// annual interest charged on the drawn part, in basis points.
+	CreditLimit      int64     `db:"credit_limit" json:"credit_limit"`
+	OverdraftRateBPS int64     `db:"overdraft_rate_bps" json:"overdraft_rate_bps"`
+	Available        int64     `db:"-" json:"available"` // amount - held_amount + credit_limit
+	LastUpdatedAt    time.Time `db:"last_updated_at" json:"last_updated_at"`
+}
+
+// OverdraftPosition is a balance that was below zero at the end of a day.
+type OverdraftPosition struct {
+	UserID   string
+	WalletID string
+	Currency string
+	Amount   int64 // end-of-day balance, negative
+	RateBPS  int64
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// BalanceHistory is one append-only row per balance mutation.
+// Amount is the resulting balance after Delta was applied.
+type BalanceHistory struct {
+	ID            int64     `json:"id"`
+	UserID        string    `json:"user_id"`
+	Currency      string    `json:"currency"`
+	Delta         int64     `json:"delta"`
+	Amount        int64     `json:"amount"`
+	TransactionID *string   `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type BatchMode string
+type BatchStatus string
+type BatchLegStatus string
+
+const (
+	// BatchAtomic applies every leg or none; BatchBestEffort applies whatever it can.
+	BatchAtomic     BatchMode = "atomic"
+	BatchBestEffort BatchMode = "best_effort"
+
+	BatchCompleted BatchStatus = "completed"
+	BatchPartial   BatchStatus = "partial"
+	BatchFailed    BatchStatus = "failed"
+
+	LegSucceeded BatchLegStatus = "succeeded"
+	LegFailed    BatchLegStatus = "failed"
+	LegSkipped   BatchLegStatus = "skipped" // not attempted because an atomic batch failed
+)
+
+// Batch is one sender paying many recipients in a single request.
+type Batch struct {
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id"`
+	Currency    string      `json:"currency"`
+	Mode        BatchMode   `json:"mode"`
+	Status      BatchStatus `json:"status"`
+	TotalAmount int64       `json:"total_amount"`
+	Legs        []BatchLeg  `json:"legs"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type BatchLeg struct {
+	Index         int            `json:"index"`
+	ToUserID      string         `json:"to_user_id"`
+	Amount        int64          `json:"amount"`
+	Status        BatchLegStatus `json:"status"`
+	TransactionID *string        `json:"transaction_id,omitempty"`
+	Error         *string        `json:"error,omitempty"`
+}
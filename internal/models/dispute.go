@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type DisputeStatus string
+
+const (
+	// filed by the sender; the recipient may still respond
+	DisputeOpen DisputeStatus = "open"
+	// the recipient gave their side; waiting for an admin
+	DisputeResponded DisputeStatus = "responded"
+	// dropped by the sender; the hold is released
+	DisputeWithdrawn DisputeStatus = "withdrawn"
+	// ruled for the sender: the transfer was returned by a chargeback
+	DisputeChargedBack DisputeStatus = "charged_back"
+	// ruled for the recipient; the hold is released
+	DisputeRejected DisputeStatus = "rejected"
+)
+
+// disputeMoves lists where each status may go; the last three are final.
+var disputeMoves = map[DisputeStatus][]DisputeStatus{
+	DisputeOpen:      {DisputeResponded, DisputeWithdrawn, DisputeChargedBack, DisputeRejected},
+	DisputeResponded: {DisputeWithdrawn, DisputeChargedBack, DisputeRejected},
+}
+
+func (s DisputeStatus) Valid() bool {
+	switch s {
+	case DisputeOpen, DisputeResponded, DisputeWithdrawn, DisputeChargedBack, DisputeRejected:
+		return true
+	}
+	return false
+}
+
+// Active is true while the dispute holds funds on the recipient's wallet.
+func (s DisputeStatus) Active() bool { return len(disputeMoves[s]) > 0 }
+
+func (s DisputeStatus) CanBecome(to DisputeStatus) bool {
+	for _, next := range disputeMoves[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// DisputeOutcome is the side an admin rules for.
+type DisputeOutcome string
+
+const (
+	RuleForSender    DisputeOutcome = "sender"
+	RuleForRecipient DisputeOutcome = "recipient"
+)
+
+const (
+	MaxDisputeReasonLen = 500
+	MaxDisputeTextLen   = 5000
+)
+
+// Dispute contests a completed transfer. Amount is what was left of it after
+// refunds when the dispute was filed; HeldAmount is the part of it the recipient
+// still had available, held on HoldWalletID until the dispute is closed.
+type Dispute struct {
+	ID             string        `json:"id"`
+	TransactionID  string        `json:"transaction_id"`
+	SenderID       string        `json:"sender_id"`
+	RecipientID    string        `json:"recipient_id"`
+	HoldWalletID   string        `json:"hold_wallet_id"`
+	Amount         int64         `json:"amount"`
+	HeldAmount     int64         `json:"held_amount"`
+	Currency       string        `json:"currency"`
+	Reason         string        `json:"reason"`
+	Evidence       *string       `json:"evidence,omitempty"`
+	Response       *string       `json:"response,omitempty"`
+	Status         DisputeStatus `json:"status"`
+	ResolvedBy     *string       `json:"resolved_by,omitempty"`
+	ResolutionNote *string       `json:"resolution_note,omitempty"`
+	// ChargebackID is the transaction that returned the money, once charged back.
+	ChargebackID *string    `json:"chargeback_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// DisputeClaim is what the sender files.
+type DisputeClaim struct {
+	Reason   string  `json:"reason"`
+	Evidence *string `json:"evidence,omitempty"`
+}
+
+func (c DisputeClaim) Validate() error {
+	if strings.TrimSpace(c.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if utf8.RuneCountInString(c.Reason) > MaxDisputeReasonLen {
+		return fmt.Errorf("reason must be at most %d characters", MaxDisputeReasonLen)
+	}
+	if c.Evidence != nil {
+		return ValidateDisputeText("evidence", *c.Evidence)
+	}
+	return nil
+}
+
+// ValidateDisputeText checks evidence, responses and resolution notes.
+func ValidateDisputeText(field, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("%s must not be blank", field)
+	}
+	if utf8.RuneCountInString(text) > MaxDisputeTextLen {
+		return fmt.Errorf("%s must be at most %d characters", field, MaxDisputeTextLen)
+	}
+	return nil
+}
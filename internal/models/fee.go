@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// FeeAnyRole is the role of a rule that applies to every role without a rule of its own.
+const FeeAnyRole = "*"
+
+// FeeRule prices transfers or debits in one currency for one role. The fee is
+// Flat plus BPS basis points of the amount, rounded half up, then clamped to
+// [MinFee, MaxFee]. With Tiers, the first tier whose UpTo covers the amount
+// (or the last tier) supplies Flat and BPS for the whole amount instead.
+type FeeRule struct {
+	ID        string          `json:"id"`
+	Role      string          `json:"role"`
+	TxnType   TransactionType `json:"txn_type"`
+	Currency  string          `json:"currency"`
+	Flat      int64           `json:"flat"`
+	BPS       int64           `json:"bps"`
+	Tiers     []FeeTier       `json:"tiers"`
+	MinFee    *int64          `json:"min_fee,omitempty"`
+	MaxFee    *int64          `json:"max_fee,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type FeeTier struct {
+	UpTo *int64 `json:"up_to,omitempty"` // inclusive; nil = no upper bound
+	Flat int64  `json:"flat"`
+	BPS  int64  `json:"bps"`
+}
+
+func (r FeeRule) Validate() error {
+	if r.Role == "" {
+		return errors.New("role is required")
+	}
+	if r.TxnType != TxnTransfer && r.TxnType != TxnDebit {
+		return errors.New("txn_type must be transfer or debit")
+	}
+	check := func(flat, bps int64) error {
+		if flat < 0 || bps < 0 || bps > 10000 {
+			return errors.New("flat must be >= 0 and bps 0-10000")
+		}
+		return nil
+	}
+	if err := check(r.Flat, r.BPS); err != nil {
+		return err
+	}
+	var prev *int64
+	for i, t := range r.Tiers {
+		if err := check(t.Flat, t.BPS); err != nil {
+			return err
+		}
+		if t.UpTo == nil && i != len(r.Tiers)-1 {
+			return errors.New("only the last tier may be unbounded")
+		}
+		if t.UpTo != nil && prev != nil && *t.UpTo <= *prev {
+			return errors.New("tiers must be in ascending up_to order")
+		}
+		prev = t.UpTo
+	}
+	if (r.MinFee != nil && *r.MinFee < 0) || (r.MaxFee != nil && *r.MaxFee < 0) {
+		return errors.New("min_fee and max_fee must be >= 0")
+	}
+	if r.MinFee != nil && r.MaxFee != nil && *r.MinFee > *r.MaxFee {
+		return errors.New("min_fee must not exceed max_fee")
+	}
+	return nil
+}
+
+// Compute returns the fee for amount.
+func (r FeeRule) Compute(amount int64) int64 {
+	flat, bps := r.Flat, r.BPS
+	for i, t := range r.Tiers {
+		if t.UpTo == nil || amount <= *t.UpTo || i == len(r.Tiers)-1 {
+			flat, bps = t.Flat, t.BPS
+			break
+		}
+	}
+	// amount*bps/10000 rounded half up, without overflowing on large amounts
+	p := new(big.Int).Mul(big.NewInt(amount), big.NewInt(bps))
+	p.Add(p, big.NewInt(5000))
+	p.Quo(p, big.NewInt(10000))
+	fee := flat + p.Int64()
+	if r.MinFee != nil && fee < *r.MinFee {
+		fee = *r.MinFee
+	}
+	if r.MaxFee != nil && fee > *r.MaxFee {
+		fee = *r.MaxFee
+	}
+	return fee
+}
+
+// FeeQuote is the fee a user would pay for a transaction; RuleID is nil when no rule applies.
+type FeeQuote struct {
+	TxnType  TransactionType `json:"txn_type"`
+	Amount   int64           `json:"amount"`
+	Currency string          `json:"currency"`
+	Fee      int64           `json:"fee"`
+	Total    int64           `json:"total"`
+	RuleID   *string         `json:"rule_id,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func TestFeeRuleCompute(t *testing.T) {
+	tiered := []FeeTier{
+		{UpTo: ptr(10000), Flat: 50, BPS: 0},
+		{UpTo: ptr(100000), Flat: 0, BPS: 100},
+		{Flat: 0, BPS: 50},
+	}
+	tests := []struct {
+		name   string
+		rule   FeeRule
+		amount int64
+		want   int64
+	}{
+		{"no pricing", FeeRule{}, 12345, 0},
+		{"flat only", FeeRule{Flat: 25}, 12345, 25},
+		{"bps only", FeeRule{BPS: 150}, 10000, 150},
+		{"flat plus bps", FeeRule{Flat: 30, BPS: 290}, 10000, 320},
+
+		// amount*bps/10000, half up
+		{"rounds down below half", FeeRule{BPS: 1}, 4999, 0},
+		{"rounds half up", FeeRule{BPS: 1}, 5000, 1},
+		{"rounds up above half", FeeRule{BPS: 1}, 15001, 2},
+		{"one and a half rounds up", FeeRule{BPS: 15}, 10000, 15},
+		{"fraction of a unit", FeeRule{BPS: 25}, 1234, 3},
+		{"no overflow on large amounts", FeeRule{BPS: 10000}, math.MaxInt64, math.MaxInt64},
+
+		// the first tier whose up_to covers the amount supplies flat and bps
+		{"first tier", FeeRule{Flat: 999, BPS: 999, Tiers: tiered}, 5000, 50},
+		{"first tier is inclusive", FeeRule{Tiers: tiered}, 10000, 50},
+		{"second tier", FeeRule{Tiers: tiered}, 10001, 100},
+		{"second tier applies to the whole amount", FeeRule{Tiers: tiered}, 100000, 1000},
+		{"unbounded last tier", FeeRule{Tiers: tiered}, 200000, 1000},
+		{"bounded last tier covers larger amounts", FeeRule{Tiers: tiered[:2]}, 200000, 2000},
+
+		// clamped to [min_fee, max_fee] after rounding
+		{"min fee", FeeRule{BPS: 10, MinFee: ptr(100)}, 10000, 100},
+		{"min fee not needed", FeeRule{BPS: 10, MinFee: ptr(100)}, 200000, 200},
+		{"max fee", FeeRule{BPS: 100, MaxFee: ptr(500)}, 100000, 500},
+		{"max fee not needed", FeeRule{BPS: 100, MaxFee: ptr(500)}, 10000, 100},
+		{"min fee on a zero fee", FeeRule{MinFee: ptr(10)}, 1, 10},
+		{"min and max equal", FeeRule{BPS: 100, MinFee: ptr(75), MaxFee: ptr(75)}, 1000000, 75},
+		{"clamps apply to tiers", FeeRule{Tiers: tiered, MinFee: ptr(60), MaxFee: ptr(800)}, 5000, 60},
+		{"max clamps a tiered fee", FeeRule{Tiers: tiered, MinFee: ptr(60), MaxFee: ptr(800)}, 100000, 800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Compute(tt.amount); got != tt.want {
+				t.Errorf("Compute(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
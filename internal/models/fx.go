@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// FXRate is how many units of QuoteCurrency one unit of BaseCurrency buys.
+// Rates are append-only; the newest row for a pair is the current rate.
+type FXRate struct {
+	ID            int64     `json:"id"`
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"` // decimal, kept as text to avoid float rounding
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// FXConversion records a conversion: FromAmount left UserID's FromCurrency wallet
+// and ToAmount arrived in ToUserID's ToCurrency wallet at Rate. Conversions now
+// always credit UserID; another user is paid by a separate transfer.
+type FXConversion struct {
+	ID                  string    `json:"id"`
+	UserID              string    `json:"user_id"`
+	ToUserID            string    `json:"to_user_id"`
+	FromCurrency        string    `json:"from_currency"`
+	ToCurrency          string    `json:"to_currency"`
+	FromAmount          int64     `json:"from_amount"`
+	ToAmount            int64     `json:"to_amount"`
+	Rate                string    `json:"rate"`
+	FXRateID            int64     `json:"fx_rate_id"`
+	DebitTransactionID  string    `json:"debit_transaction_id"`
+	CreditTransactionID string    `json:"credit_transaction_id"`
+	CreatedAt           time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type HoldStatus string
+
+const (
+	HoldAuthorized HoldStatus = "authorized"
+	HoldCaptured   HoldStatus = "captured"
+	HoldVoided     HoldStatus = "voided"
+	HoldExpired    HoldStatus = "expired"
+)
+
+// Hold reserves funds on a balance: available drops, the ledger balance does not
+// move until the hold is captured.
+type Hold struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	PayeeUserID    *string    `json:"payee_user_id,omitempty"`
+	Amount         int64      `json:"amount"`
+	Currency       string     `json:"currency"`
+	CapturedAmount int64      `json:"captured_amount"`
+	Status         HoldStatus `json:"status"`
+	TransactionID  string     `json:"transaction_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
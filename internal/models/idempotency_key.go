@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the outcome of the first request made with a key so retries can be replayed.
+type IdempotencyKey struct {
+	UserID      string `json:"user_id"`
+	Endpoint    string `json:"endpoint"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+	StatusCode  *int   `json:"status_code,omitempty"` // nil while in flight
+	// ResponseBody is replayed byte for byte, with ContentType.
+	ResponseBody []byte `json:"response_body,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	// LockedUntil is the lease of the request in flight; a retry after it takes the key over.
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a row of the durable work queue.
+type Job struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	TransactionID *string    `json:"transaction_id,omitempty"`
+	Status        JobStatus  `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	RunAt         time.Time  `json:"run_at"`
+	LockedAt      *time.Time `json:"locked_at,omitempty"`
+	LockedBy      *string    `json:"locked_by,omitempty"`
+	LastError     *string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Exhausted reports whether this was the last allowed attempt.
+func (j Job) Exhausted() bool { return j.Attempts >= j.MaxAttempts }
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type PostingDirection string
+
+const (
+	PostingDebit  PostingDirection = "debit"
+	PostingCredit PostingDirection = "credit"
+)
+
+// System accounts. Money enters the books through funding and leaves through payout;
+// fx is the counterparty of both legs of a currency conversion, adjustment of
+// reconciliation corrections, revenue collects fees and overdraft interest and
+// interest pays out savings interest.
+// Every account is per currency, see SystemAccountCode.
+const (
+	AccountFunding    = "system:funding"
+	AccountPayout     = "system:payout"
+	AccountOpening    = "system:opening"
+	AccountFX         = "system:fx"
+	AccountAdjustment = "system:adjustment"
+	AccountRevenue    = "system:revenue"
+	AccountInterest   = "system:interest"
+
+	userAccountPrefix = "user:"
+)
+
+// UserAccountCode is the ledger account code of a user's main wallet in one currency.
+func UserAccountCode(userID, currency string) string {
+	return userAccountPrefix + userID + ":" + currency
+}
+
+// WalletAccountCode is the ledger account code of one of a user's wallets; a nil
+// wallet, or the user's own id, is the main wallet.
+func WalletAccountCode(userID string, walletID *string, currency string) string {
+	if walletID == nil || *walletID == userID {
+		return UserAccountCode(userID, currency)
+	}
+	return userAccountPrefix + userID + ":" + *walletID + ":" + currency
+}
+
+// SystemAccountCode is the per-currency code of a system account such as AccountFunding.
+func SystemAccountCode(account, currency string) string { return account + ":" + currency }
+
+// UserIDFromAccountCode reports the user behind a wallet account code.
+func UserIDFromAccountCode(code string) (string, bool) {
+	if !strings.HasPrefix(code, userAccountPrefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(code, userAccountPrefix)
+	i := strings.Index(rest, ":")
+	if i < 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// WalletIDFromAccountCode reports the wallet behind a wallet account code; for a
+// main wallet that is the user's id.
+func WalletIDFromAccountCode(code string) (string, bool) {
+	userID, ok := UserIDFromAccountCode(code)
+	if !ok {
+		return "", false
+	}
+	rest := strings.TrimPrefix(code, userAccountPrefix+userID+":")
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return userID, true
+	}
+	return rest[:i], true
+}
+
+// AccountCurrency returns the currency suffix of an account code.
+func AccountCurrency(code string) string {
+	i := strings.LastIndex(code, ":")
+	if i < 0 {
+		return ""
+	}
+	return code[i+1:]
+}
+
+type LedgerAccount struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Kind      string    `json:"kind"` // user|system
+	Currency  string    `json:"currency"`
+	UserID    *string   `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	ID          int64            `json:"id"`
+	EntryID     string           `json:"entry_id"`
+	AccountCode string           `json:"account_code"`
+	Direction   PostingDirection `json:"direction"`
+	Amount      int64            `json:"amount"`
+}
+
+// JournalEntry groups postings that must balance: sum(debits) == sum(credits).
+type JournalEntry struct {
+	ID            string    `json:"id"`
+	TransactionID *string   `json:"transaction_id,omitempty"`
+	Description   string    `json:"description"`
+	Postings      []Posting `json:"postings"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Unguarded lets the entry take a wallet past its credit line; only for
+	// charges the books take regardless, such as overdraft interest. Not stored.
+	Unguarded bool `json:"-"`
+}
+
+// NewTransferEntry debits one account and credits another for the same amount.
+func NewTransferEntry(txnID, description, debitCode, creditCode string, amount int64) JournalEntry {
+	e := JournalEntry{
+		Description: description,
+		Postings: []Posting{
+			{AccountCode: debitCode, Direction: PostingDebit, Amount: amount},
+			{AccountCode: creditCode, Direction: PostingCredit, Amount: amount},
+		},
+	}
+	if txnID != "" {
+		e.TransactionID = &txnID
+	}
+	return e
+}
+
+func (e JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return errors.New("journal entry needs at least two postings")
+	}
+	var debits, credits int64
+	cur := AccountCurrency(e.Postings[0].AccountCode)
+	for _, p := range e.Postings {
+		if AccountCurrency(p.AccountCode) != cur {
+			return errors.New("journal entry mixes currencies")
+		}
+		if p.Amount <= 0 {
+			return errors.New("posting amount must be > 0")
+		}
+		switch p.Direction {
+		case PostingDebit:
+			debits += p.Amount
+		case PostingCredit:
+			credits += p.Amount
+		default:
+			return errors.New("invalid posting direction")
+		}
+	}
+	if debits != credits {
+		return errors.New("journal entry is not balanced")
+	}
+	return nil
+}
+
+// AccountBalance is one row of the trial balance.
+type AccountBalance struct {
+	AccountID string `json:"account_id"`
+	Code      string `json:"code"`
+	Kind      string `json:"kind"`
+	Currency  string `json:"currency"`
+	Debits    int64  `json:"debits"`
+	Credits   int64  `json:"credits"`
+}
+
+// CurrencyTotals are the trial balance totals of one currency; amounts in
+// different currencies are never added together.
+type CurrencyTotals struct {
+	Currency     string `json:"currency"`
+	TotalDebits  int64  `json:"total_debits"`
+	TotalCredits int64  `json:"total_credits"`
+	Balanced     bool   `json:"balanced"`
+}
+
+// TrialBalance proves the books balance: per currency, total debits equal total credits.
+type TrialBalance struct {
+	Accounts   []AccountBalance `json:"accounts"`
+	Currencies []CurrencyTotals `json:"currencies"`
+	Balanced   bool             `json:"balanced"`
+}
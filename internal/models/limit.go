@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type LimitScope string
+
+const (
+	LimitScopeRole LimitScope = "role"
+	LimitScopeTier LimitScope = "tier"
+	LimitScopeUser LimitScope = "user"
+
+	// LimitAnyType is the txn_type of a policy that covers every type without a policy of its own.
+	LimitAnyType = "*"
+
+	LimitDay   = 24 * time.Hour
+	LimitMonth = 30 * 24 * time.Hour
+)
+
+// LimitPolicy caps what a role, tier or single user may move per transaction and
+// per rolling day/month, in one currency. Nil fields are not enforced.
+// Usage is always counted per transaction type, also for '*' policies.
+type LimitPolicy struct {
+	ID            string     `json:"id"`
+	Scope         LimitScope `json:"scope"`
+	Subject       string     `json:"subject"`
+	TxnType       string     `json:"txn_type"`
+	Currency      string     `json:"currency"`
+	MaxAmount     *int64     `json:"max_amount,omitempty"`
+	DailyAmount   *int64     `json:"daily_amount,omitempty"`
+	MonthlyAmount *int64     `json:"monthly_amount,omitempty"`
+	DailyCount    *int64     `json:"daily_count,omitempty"`
+	MonthlyCount  *int64     `json:"monthly_count,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (p LimitPolicy) Validate() error {
+	switch p.Scope {
+	case LimitScopeRole, LimitScopeTier, LimitScopeUser:
+	default:
+		return errors.New("scope must be role, tier or user")
+	}
+	if p.Subject == "" {
+		return errors.New("subject is required")
+	}
+	switch TransactionType(p.TxnType) {
+	case LimitAnyType, TxnCredit, TxnDebit, TxnTransfer:
+	default:
+		return errors.New("txn_type must be *, credit, debit or transfer")
+	}
+	for _, v := range []*int64{p.MaxAmount, p.DailyAmount, p.MonthlyAmount, p.DailyCount, p.MonthlyCount} {
+		if v != nil && *v <= 0 {
+			return errors.New("limits must be > 0")
+		}
+	}
+	return nil
+}
+
+// LimitUsage is what a user already moved of one type and currency in the rolling windows.
+// The Oldest fields are the first counted transaction of each window.
+type LimitUsage struct {
+	DailyAmount   int64
+	DailyCount    int64
+	DailyOldest   *time.Time
+	MonthlyAmount int64
+	MonthlyCount  int64
+	MonthlyOldest *time.Time
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MonthlyStatement is the frozen close of one calendar month (UTC) for one
+// (wallet, currency). Later reversals land in the month they are booked in and
+// never change a closed statement.
+type MonthlyStatement struct {
+	ID               string                `json:"id"`
+	UserID           string                `json:"user_id"`
+	WalletID         string                `json:"wallet_id"`
+	Currency         string                `json:"currency"`
+	PeriodStart      time.Time             `json:"period_start"`
+	PeriodEnd        time.Time             `json:"period_end"`
+	OpeningBalance   int64                 `json:"opening_balance"`
+	ClosingBalance   int64                 `json:"closing_balance"`
+	TotalIn          int64                 `json:"total_in"`
+	TotalOut         int64                 `json:"total_out"`
+	Totals           map[string]TypeTotals `json:"totals"`
+	TransactionCount int                   `json:"transaction_count"`
+	GeneratedAt      time.Time             `json:"generated_at"`
+}
+
+// TypeTotals sums one transaction type's balance changes; Out is positive.
+type TypeTotals struct {
+	Count int   `json:"count"`
+	In    int64 `json:"in"`
+	Out   int64 `json:"out"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RoleOrganization is the users.role of the account row behind an organization.
+// Such a row has no usable password, so nobody can sign in as it.
+const RoleOrganization = "organization"
+
+// OrgRole is what a member may do for an organization.
+type OrgRole string
+
+const (
+	// manages members, sends and approves money and sees everything
+	OrgOwner OrgRole = "owner"
+	// sends money on the organization's behalf, approves the transfers awaiting
+	// approval that other members sent, and sees everything
+	OrgApprover OrgRole = "approver"
+	// sees balances and transactions only
+	OrgViewer OrgRole = "viewer"
+
+	MaxOrgNameLen = 128
+)
+
+// OrgAction is something a member can ask to do; see OrgRole.Can.
+type OrgAction string
+
+const (
+	OrgView          OrgAction = "view"
+	OrgTransfer      OrgAction = "transfer"
+	OrgApprove       OrgAction = "approve"
+	OrgManageMembers OrgAction = "manage_members"
+)
+
+func (r OrgRole) Valid() bool {
+	return r == OrgOwner || r == OrgApprover || r == OrgViewer
+}
+
+func (r OrgRole) Can(a OrgAction) bool {
+	switch a {
+	case OrgView:
+		return r.Valid()
+	case OrgTransfer, OrgApprove:
+		return r == OrgOwner || r == OrgApprover
+	case OrgManageMembers:
+		return r == OrgOwner
+	}
+	return false
+}
+
+// Organization is a shared account. Its id is also the user id that owns its
+// balances and appears on its transactions.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// Role is the caller's role when listing their organizations.
+	Role OrgRole `json:"role,omitempty"`
+}
+
+func ValidateOrgName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxOrgNameLen {
+		return "", errors.New("name must be 1-128 characters")
+	}
+	return name, nil
+}
+
+type OrgMember struct {
+	OrgID     string    `json:"org_id"`
+	UserID    string    `json:"user_id"`
+	Role      OrgRole   `json:"role"`
+	AddedBy   *string   `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
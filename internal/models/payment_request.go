@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type PaymentRequestStatus string
+
+const (
+	RequestPending   PaymentRequestStatus = "pending"
+	RequestPaid      PaymentRequestStatus = "paid"
+	RequestDeclined  PaymentRequestStatus = "declined"
+	RequestCancelled PaymentRequestStatus = "cancelled"
+	// the transfer paying it was rejected by an admin or by risk review
+	RequestRejected PaymentRequestStatus = "rejected"
+	// never stored: a pending request reads as expired once ExpiresAt has passed
+	RequestExpired PaymentRequestStatus = "expired"
+)
+
+// Which side of a payment request a user is on.
+const (
+	RequestsIncoming = "incoming" // the user is asked to pay
+	RequestsOutgoing = "outgoing" // the user asked to be paid
+)
+
+// PaymentRequest asks PayerID to pay Amount to RequesterID. Once paid,
+// TransactionID is the transfer that paid it. PendingTransactionID is the
+// transfer of an accept that had to wait for approval or risk review.
+type PaymentRequest struct {
+	ID                   string               `json:"id"`
+	RequesterID          string               `json:"requester_id"`
+	PayerID              string               `json:"payer_id"`
+	Amount               int64                `json:"amount"`
+	Currency             string               `json:"currency"`
+	Note                 *string              `json:"note,omitempty"`
+	Status               PaymentRequestStatus `json:"status"`
+	ExpiresAt            *time.Time           `json:"expires_at,omitempty"`
+	TransactionID        *string              `json:"transaction_id,omitempty"`
+	PendingTransactionID *string              `json:"pending_transaction_id,omitempty"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+}
+
+func (p PaymentRequest) Validate() error {
+	if p.Amount <= 0 {
+		return errors.New("amount must be > 0")
+	}
+	if p.RequesterID == p.PayerID {
+		return errors.New("cannot request money from yourself")
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	// the note becomes the description of the paying transfer
+	return TxnDetails{Description: p.Note}.Validate()
+}
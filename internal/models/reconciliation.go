@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type ReconciliationStatus string
+
+const (
+	ReconRunning   ReconciliationStatus = "running"
+	ReconCompleted ReconciliationStatus = "completed"
+	ReconFailed    ReconciliationStatus = "failed"
+)
+
+// ReconciliationRun is one pass that folds settled transactions per (user, currency)
+// and compares the result with balances.amount.
+type ReconciliationRun struct {
+	ID              string               `json:"id"`
+	Status          ReconciliationStatus `json:"status"`
+	Adjust          bool                 `json:"adjust"`
+	BalancesChecked int                  `json:"balances_checked"`
+	DriftsFound     int                  `json:"drifts_found"`
+	AdjustmentsMade int                  `json:"adjustments_made"`
+	Error           *string              `json:"error,omitempty"`
+	StartedAt       time.Time            `json:"started_at"`
+	FinishedAt      *time.Time           `json:"finished_at,omitempty"`
+}
+
+// BalanceDrift is a balance that doesn't match its transaction history.
+// Drift is Actual - Expected: positive means the balance holds too much.
+type BalanceDrift struct {
+	ID                      int64     `json:"id"`
+	RunID                   string    `json:"run_id"`
+	UserID                  string    `json:"user_id"`
+	Currency                string    `json:"currency"`
+	Expected                int64     `json:"expected"`
+	Actual                  int64     `json:"actual"`
+	Drift                   int64     `json:"drift"`
+	AdjustmentTransactionID *string   `json:"adjustment_transaction_id,omitempty"`
+	AdjustmentError         *string   `json:"adjustment_error,omitempty"`
+	CreatedAt               time.Time `json:"created_at"`
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type RiskDecision string
+type RiskCaseStatus string
+
+const (
+	RiskAllow  RiskDecision = "allow"
+	RiskReview RiskDecision = "review"
+	RiskBlock  RiskDecision = "block"
+
+	CaseOpen     RiskCaseStatus = "open"
+	CaseReleased RiskCaseStatus = "released"
+	CaseRejected RiskCaseStatus = "rejected"
+)
+
+func (d RiskDecision) severity() int {
+	switch d {
+	case RiskBlock:
+		return 2
+	case RiskReview:
+		return 1
+	}
+	return 0
+}
+
+// RiskCheck is a debit or transfer about to be created.
+type RiskCheck struct {
+	UserID   string
+	Type     TransactionType
+	ToUserID *string
+	Amount   int64
+	Currency string
+}
+
+// RiskHit is one rule that fired, with the decision it asks for.
+type RiskHit struct {
+	Rule     string       `json:"rule"`
+	Decision RiskDecision `json:"decision"`
+	Reason   string       `json:"reason"`
+}
+
+// RiskResult is the outcome of screening: the most severe decision of its hits.
+type RiskResult struct {
+	Decision RiskDecision `json:"decision"`
+	Hits     []RiskHit    `json:"hits"`
+}
+
+func (r *RiskResult) Add(h RiskHit) {
+	r.Hits = append(r.Hits, h)
+	if h.Decision.severity() > r.Decision.severity() {
+		r.Decision = h.Decision
+	}
+}
+
+// RiskRules configures the built-in screening rules. Amounts are in minor units
+// of whatever currency is being screened.
+type RiskRules struct {
+	// a large payment to someone the user never paid before
+	NewRecipientLarge struct {
+		Enabled   bool         `json:"enabled"`
+		MinAmount int64        `json:"min_amount"`
+		Action    RiskDecision `json:"action"`
+	} `json:"new_recipient_large"`
+
+	// paying more than MaxRecipients different users within the window
+	FanOut struct {
+		Enabled       bool         `json:"enabled"`
+		WindowMinutes int          `json:"window_minutes"`
+		MaxRecipients int          `json:"max_recipients"`
+		Action        RiskDecision `json:"action"`
+	} `json:"fan_out"`
+
+	// an amount over Factor times the user's 30-day average outgoing amount
+	Spike struct {
+		Enabled    bool         `json:"enabled"`
+		Factor     int64        `json:"factor"`
+		MinAmount  int64        `json:"min_amount"`
+		MinHistory int          `json:"min_history"` // transactions needed for a meaningful average
+		Action     RiskDecision `json:"action"`
+	} `json:"spike"`
+
+	// structuring: more than MaxCount round amounts (multiples of Unit) within the window
+	RoundAmount struct {
+		Enabled       bool         `json:"enabled"`
+		Unit          int64        `json:"unit"`
+		MinAmount     int64        `json:"min_amount"`
+		MaxCount      int          `json:"max_count"`
+		WindowMinutes int          `json:"window_minutes"`
+		Action        RiskDecision `json:"action"`
+	} `json:"round_amount"`
+}
+
+func (r RiskRules) Validate() error {
+	action := func(a RiskDecision) error {
+		if a != RiskReview && a != RiskBlock {
+			return errors.New("action must be review or block")
+		}
+		return nil
+	}
+	if r.NewRecipientLarge.Enabled {
+		if err := action(r.NewRecipientLarge.Action); err != nil {
+			return err
+		}
+	}
+	if r.FanOut.Enabled {
+		if r.FanOut.WindowMinutes <= 0 || r.FanOut.MaxRecipients <= 0 {
+			return errors.New("fan_out needs window_minutes and max_recipients > 0")
+		}
+		if err := action(r.FanOut.Action); err != nil {
+			return err
+		}
+	}
+	if r.Spike.Enabled {
+		if r.Spike.Factor <= 1 || r.Spike.MinHistory < 1 {
+			return errors.New("spike needs factor > 1 and min_history >= 1")
+		}
+		if err := action(r.Spike.Action); err != nil {
+			return err
+		}
+	}
+	if r.RoundAmount.Enabled {
+		if r.RoundAmount.Unit <= 0 || r.RoundAmount.MaxCount <= 0 || r.RoundAmount.WindowMinutes <= 0 {
+			return errors.New("round_amount needs unit, max_count and window_minutes > 0")
+		}
+		if err := action(r.RoundAmount.Action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RiskCase is a transaction held for review, with the hits that held it, in the
+// reviewer queue.
+type RiskCase struct {
+	ID          string         `json:"id"`
+	Transaction Transaction    `json:"transaction"`
+	Decision    RiskDecision   `json:"decision"`
+	Hits        []RiskHit      `json:"hits"`
+	Status      RiskCaseStatus `json:"status"`
+	ReviewerID  *string        `json:"reviewer_id,omitempty"`
+	Note        *string        `json:"note,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DecidedAt   *time.Time     `json:"decided_at,omitempty"`
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// InterestDayDenominator is the unit accrual carries are kept in: a carry is
+// the fraction of a minor unit left over, in units of 1/InterestDayDenominator.
+const InterestDayDenominator = 10000 * 365
+
+// InterestTier is one balance band of a currency's savings rate. The part of a
+// balance from MinBalance up to the next tier's MinBalance earns APYBPS a year:
+// it accrues daily at the rate that compounds to the APY over 365 days.
+type InterestTier struct {
+	Currency   string    `json:"currency"`
+	MinBalance int64     `json:"min_balance"`
+	APYBPS     int64     `json:"apy_bps"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ValidateInterestTiers checks the bands of one currency. They must be ascending,
+// and the first one must start at 0 so every positive balance is covered.
+func ValidateInterestTiers(tiers []InterestTier) error {
+	for i, t := range tiers {
+		if t.APYBPS < 0 || t.APYBPS > 10000 {
+			return errors.New("apy_bps must be 0-10000")
+		}
+		if i == 0 && t.MinBalance != 0 {
+			return errors.New("the first tier must start at min_balance 0")
+		}
+		if i > 0 && t.MinBalance <= tiers[i-1].MinBalance {
+			return errors.New("tiers must be in ascending min_balance order")
+		}
+	}
+	return nil
+}
+
+// interestPrec is the precision, in bits, daily rates are worked out to.
+const interestPrec = 256
+
+// dailyRate is (1+apy)^(1/365) - 1, the daily rate that compounds to apyBPS
+// over 365 days, found by Newton's method on y^365 = 1+apy.
+func dailyRate(apyBPS int64) *big.Float {
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(interestPrec) }
+	a := newFloat().Quo(newFloat().SetInt64(10000+apyBPS), newFloat().SetInt64(10000))
+	// 1+apy/365 is at or above the root, from where Newton's steps only go down
+	y := newFloat().Quo(newFloat().SetInt64(365*10000+apyBPS), newFloat().SetInt64(365*10000))
+	for i := 0; i < 100; i++ {
+		p := newFloat().SetInt64(1)
+		for b, e := newFloat().Set(y), 364; e > 0; e >>= 1 {
+			if e&1 == 1 {
+				p.Mul(p, b)
+			}
+			b.Mul(b, b)
+		}
+		// y' = (364*y + a/y^364) / 365
+		next := newFloat().Mul(y, newFloat().SetInt64(364))
+		next.Add(next, newFloat().Quo(a, p))
+		next.Quo(next, newFloat().SetInt64(365))
+		if next.Cmp(y) >= 0 {
+			break
+		}
+		y = next
+	}
+	return y.Sub(y, newFloat().SetInt64(1))
+}
+
+// DailyInterest accrues one day of interest on balance under tiers. balance
+// should include interest accrued but not paid in yet, so it compounds daily
+// whatever the payout schedule. carry is the remainder left by the previous
+// day; the returned carry goes into the next. Each day's rate is worked out to
+// far below a carry unit, and nothing else is rounded away.
+func DailyInterest(tiers []InterestTier, balance, carry int64) (amount, nextCarry int64) {
+	n := big.NewInt(carry)
+	den := new(big.Float).SetPrec(interestPrec).SetInt64(InterestDayDenominator)
+	half := new(big.Float).SetPrec(interestPrec).SetFloat64(0.5)
+	for i, t := range tiers {
+		if balance <= t.MinBalance {
+			break
+		}
+		top := balance
+		if i+1 < len(tiers) && tiers[i+1].MinBalance < balance {
+			top = tiers[i+1].MinBalance
+		}
+		if t.APYBPS == 0 {
+			continue
+		}
+		// this band's interest in carry units, rounded half up
+		f := new(big.Float).SetPrec(interestPrec).SetInt64(top - t.MinBalance)
+		f.Mul(f, dailyRate(t.APYBPS)).Mul(f, den).Add(f, half)
+		units, _ := f.Int(nil)
+		n.Add(n, units)
+	}
+	q, r := new(big.Int).QuoRem(n, big.NewInt(InterestDayDenominator), new(big.Int))
+	return q.Int64(), r.Int64()
+}
+
+// InterestAccrual is one day of savings interest on one wallet balance.
+type InterestAccrual struct {
+	UserID    string    `json:"user_id"`
+	WalletID  string    `json:"wallet_id"`
+	Currency  string    `json:"currency"`
+	Day       time.Time `json:"day"`
+	Balance   int64     `json:"balance"` // end-of-day balance
+	Amount    int64     `json:"amount"`
+	Carry     int64     `json:"-"`
+	PayoutID  *string   `json:"payout_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaverPosition is a positive end-of-day balance that has not accrued for the day yet.
+type SaverPosition struct {
+	UserID   string
+	WalletID string
+	Currency string
+	Balance  int64
+	Accrued  int64 // interest accrued on it before the day and not paid in by its end
+	Carry    int64 // carry of the latest earlier accrual
+}
+
+// InterestDue is a balance with accrued interest that has not been paid out.
+type InterestDue struct {
+	UserID   string
+	WalletID string
+	Currency string
+	Amount   int64
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// accrueYear accrues every day of 2026 on a balance the saver never touches,
+// paying the unpaid interest in at the start of each month, or every day when
+// daily is set. It returns the interest earned over the year, paid or not.
+func accrueYear(tiers []InterestTier, balance int64, daily bool) int64 {
+	var unpaid, carry int64
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day := start; day.Year() == 2026; day = day.AddDate(0, 0, 1) {
+		if daily || day.Day() == 1 {
+			balance, unpaid = balance+unpaid, 0
+		}
+		var amount int64
+		amount, carry = DailyInterest(tiers, balance+unpaid, carry)
+		unpaid += amount
+	}
+	return balance + unpaid
+}
+
+func TestDailyInterestCompoundsToAPY(t *testing.T) {
+	tests := []struct {
+		name    string
+		apyBPS  int64
+		balance int64
+	}{
+		{"small balance", 450, 1000},
+		{"typical", 450, 1234567},
+		{"one basis point", 1, 50000000},
+		{"high rate", 2500, 987654321},
+		{"whole rate", 10000, 100000},
+		{"large balance", 375, 1_000_000_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiers := []InterestTier{{MinBalance: 0, APYBPS: tt.apyBPS}}
+			got := accrueYear(tiers, tt.balance, false) - tt.balance
+			want := new(big.Rat).Mul(new(big.Rat).SetInt64(tt.balance), big.NewRat(tt.apyBPS, 10000))
+			diff := new(big.Rat).Sub(new(big.Rat).SetInt64(got), want)
+			if diff.Abs(diff).Cmp(big.NewRat(1, 1)) > 0 {
+				t.Errorf("a year on %d at %d bps earned %d, want %s within one unit",
+					tt.balance, tt.apyBPS, got, want.FloatString(4))
+			}
+			if daily := accrueYear(tiers, tt.balance, true) - tt.balance; daily != got {
+				t.Errorf("paid daily earned %d, paid monthly %d", daily, got)
+			}
+		})
+	}
+}
+
+func TestDailyInterestTiers(t *testing.T) {
+	tiers := []InterestTier{
+		{MinBalance: 0, APYBPS: 0},
+		{MinBalance: 100000, APYBPS: 365},
+	}
+	if amount, carry := DailyInterest(tiers, 100000, 0); amount != 0 || carry != 0 {
+		t.Errorf("at the band's start: %d carry %d, want nothing", amount, carry)
+	}
+	// only the part above the band's start earns, so 10^8 above it matches 10^8 in one band
+	above, aboveCarry := DailyInterest(tiers, 100000+100000000, 0)
+	one, oneCarry := DailyInterest([]InterestTier{{MinBalance: 0, APYBPS: 365}}, 100000000, 0)
+	if above != one || aboveCarry != oneCarry {
+		t.Errorf("above the band: %d carry %d, want %d carry %d", above, aboveCarry, one, oneCarry)
+	}
+	if amount, carry := DailyInterest(tiers, -500, 7); amount != 0 || carry != 7 {
+		t.Errorf("negative balance: %d carry %d, want 0 carry 7", amount, carry)
+	}
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/cron"
+)
+
+type ScheduleFrequency string
+type ScheduleStatus string
+type ScheduleRunStatus string
+
+const (
+	FreqOnce    ScheduleFrequency = "once"
+	FreqDaily   ScheduleFrequency = "daily"
+	FreqWeekly  ScheduleFrequency = "weekly"
+	FreqMonthly ScheduleFrequency = "monthly"
+	FreqCron    ScheduleFrequency = "cron"
+
+	ScheduleActive    ScheduleStatus = "active"
+	SchedulePaused    ScheduleStatus = "paused"
+	ScheduleCompleted ScheduleStatus = "completed"
+	ScheduleCancelled ScheduleStatus = "cancelled"
+
+	RunSucceeded ScheduleRunStatus = "succeeded"
+	RunFailed    ScheduleRunStatus = "failed"
+)
+
+// Schedule is a standing order: a transfer that runs once at StartAt or repeatedly.
+// NextRunAt is the occurrence being worked on; NextAttemptAt only differs from it
+// while a failed occurrence is waiting for a retry.
+type Schedule struct {
+	ID            string            `json:"id"`
+	UserID        string            `json:"user_id"`
+	ToUserID      string            `json:"to_user_id"`
+	Amount        int64             `json:"amount"`
+	Currency      string            `json:"currency"`
+	Frequency     ScheduleFrequency `json:"frequency"`
+	CronExpr      *string           `json:"cron,omitempty"`
+	StartAt       time.Time         `json:"start_at"`
+	EndAt         *time.Time        `json:"end_at,omitempty"`
+	NextRunAt     *time.Time        `json:"next_run_at,omitempty"`
+	NextAttemptAt *time.Time        `json:"next_attempt_at,omitempty"`
+	Attempt       int               `json:"attempt"`
+	MaxRetries    int               `json:"max_retries"`
+	RetryInterval int               `json:"retry_interval"` // seconds
+	Status        ScheduleStatus    `json:"status"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// ScheduleRun records one attempt at one occurrence.
+type ScheduleRun struct {
+	ID            int64             `json:"id"`
+	ScheduleID    string            `json:"schedule_id"`
+	OccurrenceAt  time.Time         `json:"occurrence_at"`
+	Attempt       int               `json:"attempt"`
+	Status        ScheduleRunStatus `json:"status"`
+	TransactionID *string           `json:"transaction_id,omitempty"`
+	Error         *string           `json:"error,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+func (s Schedule) Validate() error {
+	if s.Amount <= 0 {
+		return errors.New("amount must be > 0")
+	}
+	switch s.Frequency {
+	case FreqOnce, FreqDaily, FreqWeekly, FreqMonthly:
+	case FreqCron:
+		if s.CronExpr == nil {
+			return errors.New("cron expression required")
+		}
+		if _, err := cron.Parse(*s.CronExpr); err != nil {
+			return err
+		}
+	default:
+		return errors.New("frequency must be one of once, daily, weekly, monthly, cron")
+	}
+	if s.EndAt != nil && !s.EndAt.After(s.StartAt) {
+		return errors.New("end_at must be after start_at")
+	}
+	if s.MaxRetries < 0 || s.RetryInterval < 0 {
+		return errors.New("retry policy must not be negative")
+	}
+	return nil
+}
+
+// FirstOccurrence is the first occurrence at or after StartAt.
+func (s Schedule) FirstOccurrence() (time.Time, bool) {
+	return s.OccurrenceAfter(s.StartAt.Add(-time.Nanosecond))
+}
+
+// OccurrenceAfter returns the first occurrence strictly after t, or false when the schedule is exhausted.
+func (s Schedule) OccurrenceAfter(t time.Time) (time.Time, bool) {
+	start := s.StartAt.UTC()
+	t = t.UTC()
+	var next time.Time
+	switch s.Frequency {
+	case FreqOnce:
+		if !start.After(t) {
+			return time.Time{}, false
+		}
+		next = start
+	case FreqDaily, FreqWeekly:
+		days := 1
+		if s.Frequency == FreqWeekly {
+			days = 7
+		}
+		next = start
+		if !start.After(t) {
+			k := int(t.Sub(start)/(time.Duration(days)*24*time.Hour)) + 1
+			next = start.AddDate(0, 0, k*days)
+			for !next.After(t) {
+				k++
+				next = start.AddDate(0, 0, k*days)
+			}
+		}
+	case FreqMonthly:
+		k := 0
+		if !start.After(t) {
+			k = (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+		}
+		next = addMonthsClamped(start, k)
+		for !next.After(t) {
+			k++
+			next = addMonthsClamped(start, k)
+		}
+	case FreqCron:
+		if s.CronExpr == nil {
+			return time.Time{}, false
+		}
+		c, err := cron.Parse(*s.CronExpr)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if t.Before(start) {
+			t = start.Add(-time.Nanosecond)
+		}
+		if next = c.Next(t); next.IsZero() {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+	if s.EndAt != nil && next.After(*s.EndAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// addMonthsClamped keeps month-end schedules on the last day of shorter months (Jan 31 -> Feb 28).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatementLine is one balance change on a statement: the transaction that caused
+// it, the signed change from the account holder's side and the balance after it.
+type StatementLine struct {
+	Transaction
+	BookedAt time.Time `json:"booked_at"`
+	Delta    int64     `json:"delta"`
+	Balance  int64     `json:"balance"`
+}
+
+// HistoryLine is the statement line of balance history row h, made by tx. A row
+// no transaction made, like the opening balances recorded when balance history
+// began, shows as a completed adjustment of its delta, identified by the row.
+func HistoryLine(h BalanceHistory, tx *Transaction) StatementLine {
+	l := StatementLine{BookedAt: h.CreatedAt, Delta: h.Delta, Balance: h.Amount}
+	if tx != nil {
+		l.Transaction = *tx
+		return l
+	}
+	amount := h.Delta
+	if amount < 0 {
+		amount = -amount
+	}
+	desc := "balance recorded without a transaction"
+	l.Transaction = Transaction{
+		ID:         fmt.Sprintf("history-%d", h.ID),
+		Amount:     amount,
+		Currency:   h.Currency,
+		Type:       TxnAdjustment,
+		Status:     TxnCompleted,
+		CreatedAt:  h.CreatedAt,
+		TxnDetails: TxnDetails{Description: &desc},
+	}
+	return l
+}
+
+// Counterparty is the other user of the line, if any.
+func (l StatementLine) Counterparty() string {
+	p := l.FromUserID
+	if l.Delta < 0 {
+		p = l.ToUserID
+	}
+	if p == nil {
+		return ""
+	}
+	return *p
+}
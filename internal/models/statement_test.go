@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryLinesAddUpToClosing(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	txn := func(id string, typ TransactionType, amount int64) *Transaction {
+		return &Transaction{ID: id, Type: typ, Status: TxnCompleted, Amount: amount, Currency: "USD"}
+	}
+	// the balance predates history tracking; its opening row has no transaction
+	history := []struct {
+		row BalanceHistory
+		tx  *Transaction
+	}{
+		{BalanceHistory{ID: 1, Currency: "USD", Delta: 5000, Amount: 5000, CreatedAt: day(2)}, nil},
+		{BalanceHistory{ID: 2, Currency: "USD", Delta: -1200, Amount: 3800, CreatedAt: day(3)}, txn("t1", TxnDebit, 1200)},
+		{BalanceHistory{ID: 3, Currency: "USD", Delta: 700, Amount: 4500, CreatedAt: day(5)}, txn("t2", TxnCredit, 700)},
+	}
+	from, to := day(1), day(31)
+
+	var opening, closing int64
+	var lines []StatementLine
+	for _, h := range history {
+		if h.row.CreatedAt.Before(from) {
+			opening = h.row.Amount
+		}
+		if h.row.CreatedAt.Before(to) {
+			closing = h.row.Amount
+		}
+		if !h.row.CreatedAt.Before(from) && h.row.CreatedAt.Before(to) {
+			lines = append(lines, HistoryLine(h.row, h.tx))
+		}
+	}
+
+	sum := opening
+	for _, l := range lines {
+		sum += l.Delta
+		if l.Balance != sum {
+			t.Errorf("line %s: balance %d, want %d", l.ID, l.Balance, sum)
+		}
+	}
+	if sum != closing {
+		t.Errorf("opening %d plus lines = %d, want closing %d", opening, sum, closing)
+	}
+
+	l := lines[0]
+	if l.ID != "history-1" || l.Type != TxnAdjustment || l.Status != TxnCompleted ||
+		l.Amount != 5000 || l.Currency != "USD" || !l.BookedAt.Equal(day(2)) {
+		t.Errorf("opening row line = %+v", l)
+	}
+	if lines[1].ID != "t1" || lines[1].Delta != -1200 {
+		t.Errorf("transaction line = %+v", lines[1])
+	}
+	neg := HistoryLine(BalanceHistory{ID: 9, Delta: -300}, nil)
+	if neg.Amount != 300 || neg.Counterparty() != "" {
+		t.Errorf("negative adjustment = %+v", neg)
+	}
+}
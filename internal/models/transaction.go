@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 
 type TransactionType string
@@ -12,23 +19,157 @@ const (
 	TxnCredit   TransactionType = "credit"
 	TxnDebit    TransactionType = "debit"
 	TxnTransfer TransactionType = "transfer"
+	TxnReversal TransactionType = "reversal"
+	TxnRefund   TransactionType = "refund"
+
+	// two-phase payments; authorization and void don't move money
+	TxnAuthorization TransactionType = "authorization"
+	TxnCapture       TransactionType = "capture"
+	TxnVoid          TransactionType = "void"
+
+	// one leg of a currency conversion; see FXConversion
+	TxnConversion TransactionType = "conversion"
+
+	// corrects a balance found drifting by reconciliation; not part of the folded history
+	TxnAdjustment TransactionType = "adjustment"
+
+	// charged on top of a transfer or debit, paid to the house revenue account
+	TxnFee TransactionType = "fee"
+
+	// daily interest on a drawn credit line, paid to the house revenue account
+	TxnOverdraftInterest TransactionType = "overdraft_interest"
+
+	// monthly savings interest, paid from the house interest account
+	TxnInterest TransactionType = "interest"
+
+	// between two wallets of the same user; free and never limited
+	TxnMove TransactionType = "move"
+
+	// returns a disputed transfer to its sender once an admin rules for them
+	TxnChargeback TransactionType = "chargeback"
 
 	// statuses
 	TxnPending    TransactionStatus = "pending"
 	TxnCompleted  TransactionStatus = "completed"
 	TxnFailed     TransactionStatus = "failed"
 	TxnRolledBack TransactionStatus = "rolled_back"
+	TxnReversed   TransactionStatus = "reversed"
+	TxnRefunded   TransactionStatus = "refunded"
+
+	// maker-checker: a transfer above the approval threshold waits for a second
+	// admin, who either executes it or rejects it
+	TxnAwaitingApproval TransactionStatus = "awaiting_approval"
+	TxnRejected         TransactionStatus = "rejected"
+
+	// flagged by risk screening; waits in the review queue without moving money
+	TxnHeldForReview TransactionStatus = "held_for_review"
 )
 
+// MovesFunds is false for bookkeeping-only types that never post to the ledger.
+func (t TransactionType) MovesFunds() bool {
+	return t != TxnAuthorization && t != TxnVoid
+}
+
 // Model
 type Transaction struct {
     ID         string             `json:"id"`
     FromUserID *string            `json:"from_user_id,omitempty"`
     ToUserID   *string            `json:"to_user_id,omitempty"`
     Amount     int64              `json:"amount"`
+    Currency   string             `json:"currency"`
     Type       TransactionType    `json:"type"`
     Status     TransactionStatus  `json:"status"`
     CreatedAt  time.Time          `json:"created_at"`
 
     IdempotencyKey *string        `json:"idempotency_key,omitempty"`
-}
\ No newline at end of file
+    // ParentID links a reversal, refund or chargeback to the transaction it compensates.
+    ParentID       *string        `json:"parent_id,omitempty"`
+    // FromWalletID and ToWalletID name the wallets involved; nil is the main wallet.
+    FromWalletID   *string        `json:"from_wallet_id,omitempty"`
+    ToWalletID     *string        `json:"to_wallet_id,omitempty"`
+    // CreatedBy is the user who made a transfer: the sender, or the member who
+    // sent it for an organization.
+    CreatedBy      *string        `json:"created_by,omitempty"`
+
+    TxnDetails
+}
+
+const (
+	MaxDescriptionLen = 500
+	MaxReferenceLen   = 128
+	MaxMetadataKeys   = 50
+	MaxMetadataKeyLen = 64
+	MaxMetadataBytes  = 8 << 10
+)
+
+// TxnDetails is what the client says a payment was for. All optional.
+type TxnDetails struct {
+	Description *string `json:"description,omitempty"`
+	// ExternalReference ties the transaction to the client's own records, e.g. an
+	// order id; unique per initiating user.
+	ExternalReference *string        `json:"external_reference,omitempty"`
+	Metadata          map[string]any `json:"metadata,omitempty"`
+}
+
+func (d TxnDetails) Validate() error {
+	if d.Description != nil && utf8.RuneCountInString(*d.Description) > MaxDescriptionLen {
+		return fmt.Errorf("description must be at most %d characters", MaxDescriptionLen)
+	}
+	if d.ExternalReference != nil {
+		if strings.TrimSpace(*d.ExternalReference) == "" {
+			return errors.New("external_reference must not be blank")
+		}
+		if len(*d.ExternalReference) > MaxReferenceLen {
+			return fmt.Errorf("external_reference must be at most %d bytes", MaxReferenceLen)
+		}
+	}
+	if len(d.Metadata) > MaxMetadataKeys {
+		return fmt.Errorf("metadata must have at most %d keys", MaxMetadataKeys)
+	}
+	for k := range d.Metadata {
+		if k == "" || len(k) > MaxMetadataKeyLen {
+			return fmt.Errorf("metadata keys must be 1-%d bytes", MaxMetadataKeyLen)
+		}
+	}
+	if d.Metadata != nil {
+		b, err := json.Marshal(d.Metadata)
+		if err != nil {
+			return fmt.Errorf("metadata: %w", err)
+		}
+		if len(b) > MaxMetadataBytes {
+			return fmt.Errorf("metadata must be at most %d bytes encoded", MaxMetadataBytes)
+		}
+	}
+	return nil
+}
+
+type Direction string
+
+const (
+	DirectionIn  Direction = "in"  // user is the recipient
+	DirectionOut Direction = "out" // user is the sender
+)
+
+// TransactionFilter narrows a user's history. Zero values don't filter.
+// To is exclusive.
+type TransactionFilter struct {
+	Types        []TransactionType
+	Statuses     []TransactionStatus
+	Direction    Direction
+	Counterparty string
+	Currency     string
+	Reference    string // exact external_reference
+	MinAmount    *int64
+	MaxAmount    *int64
+	From         *time.Time
+	To           *time.Time
+
+	// After continues a listing past the last row of the previous page.
+	After *TransactionCursor
+}
+
+// TransactionCursor is a keyset position in history order (created_at DESC, id DESC).
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
@@ -7,13 +7,17 @@ import (
 )
 
 type User struct {
-	ID          string    `json:"id"`
-	Username    string    `json:"username"`
-	Email       string    `json:"email"`
-	PasswordHash string   `json:"-"`
-	Role        string    `json:"role"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string       `json:"id"`
+	Username     string       `json:"username"`
+	Email        string       `json:"email"`
+	PasswordHash string       `json:"-"`
+	Role         string       `json:"role"`
+	Tier         string       `json:"tier"`
+	State        AccountState `json:"state"`
+	StateReason  *string      `json:"state_reason,omitempty"`
+	StateUntil   *time.Time   `json:"state_until,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 func (u *User) Validate() error {
@@ -21,4 +25,56 @@ func (u *User) Validate() error {
 	if !strings.Contains(u.Email, "@") { return errors.New("invalid email") }
 	if u.Role == "" { u.Role = "user" }
 	return nil
-}
\ No newline at end of file
+}
+
+// AccountState says which way money may move for a user.
+type AccountState string
+
+const (
+	AccountActive AccountState = "active"
+	// money may come in but not go out
+	AccountDebitFrozen AccountState = "debit_frozen"
+	AccountFullyFrozen AccountState = "fully_frozen"
+	// no money movement and no logins
+	AccountClosed AccountState = "closed"
+)
+
+func (s AccountState) Valid() bool {
+	switch s {
+	case AccountActive, AccountDebitFrozen, AccountFullyFrozen, AccountClosed:
+		return true
+	}
+	return false
+}
+
+// CanSend reports whether money may leave an account in this state.
+func (s AccountState) CanSend() bool { return s == AccountActive }
+
+// CanReceive reports whether money may arrive in an account in this state.
+func (s AccountState) CanReceive() bool { return s == AccountActive || s == AccountDebitFrozen }
+
+// AccountStateChange is an admin's request to move a user to another state.
+type AccountStateChange struct {
+	State  AccountState `json:"state"`
+	Reason string       `json:"reason"`
+	// Until lifts a freeze automatically; closed accounts stay closed until reopened.
+	Until *time.Time `json:"until,omitempty"`
+}
+
+func (c AccountStateChange) Validate() error {
+	if !c.State.Valid() {
+		return errors.New("state must be active, debit_frozen, fully_frozen or closed")
+	}
+	if c.State != AccountActive && strings.TrimSpace(c.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if c.Until != nil {
+		if c.State == AccountActive || c.State == AccountClosed {
+			return errors.New("until only applies to freezes")
+		}
+		if !c.Until.After(time.Now()) {
+			return errors.New("until must be in the future")
+		}
+	}
+	return nil
+}
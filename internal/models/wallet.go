@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type WalletKind string
+
+const (
+	WalletMain    WalletKind = "main"
+	WalletSavings WalletKind = "savings"
+	WalletCustom  WalletKind = "custom"
+
+	MaxWalletNameLen = 64
+)
+
+// Wallet is a named pot of a user with its own balance per currency. Every user
+// has exactly one main wallet, which shares the user's id; it is where money
+// goes and comes from unless a request names another wallet.
+type Wallet struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name"`
+	Kind      WalletKind `json:"kind"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Validate checks a wallet a user asks for; the main wallet is never created this way.
+func (w *Wallet) Validate() error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" || utf8.RuneCountInString(w.Name) > MaxWalletNameLen {
+		return errors.New("name must be 1-64 characters")
+	}
+	if w.Kind == "" {
+		w.Kind = WalletCustom
+	}
+	if w.Kind != WalletSavings && w.Kind != WalletCustom {
+		return errors.New("kind must be savings or custom")
+	}
+	return nil
+}
+
+// WalletBalance is a wallet's balance in one currency.
+type WalletBalance struct {
+	Name string     `json:"name"`
+	Kind WalletKind `json:"kind"`
+	Balance
+}
+
+// BalanceSummary is every wallet of a user in one currency and their total.
+type BalanceSummary struct {
+	Currency string          `json:"currency"`
+	Wallets  []WalletBalance `json:"wallets"`
+	Total    BalanceTotal    `json:"total"`
+}
+
+type BalanceTotal struct {
+	Amount     int64 `json:"amount"`
+	HeldAmount int64 `json:"held_amount"`
+	Available  int64 `json:"available"`
+}
+
+// Summarize adds up the wallets of one currency.
+func Summarize(currency string, wallets []WalletBalance) BalanceSummary {
+	s := BalanceSummary{Currency: currency, Wallets: wallets}
+	for _, w := range wallets {
+		s.Total.Amount += w.Amount
+		s.Total.HeldAmount += w.HeldAmount
+		s.Total.Available += w.Available
+	}
+	return s
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "errors"
+
+// ErrInsufficientFunds is returned when a balance update would take the amount below zero.
+var ErrInsufficientFunds = errors.New("insufficient balance")
+
+// ErrRunInProgress is returned when a reconciliation run is already running.
+var ErrRunInProgress = errors.New("a reconciliation run is already in progress")
+
+// ErrDuplicateReference is returned when a user reuses the external reference of
+// a transaction that has not failed, been rolled back or been rejected.
+var ErrDuplicateReference = errors.New("external_reference already used")
+
+// ErrDuplicateWalletName is returned when a user already has a wallet of that name.
+var ErrDuplicateWalletName = errors.New("a wallet with this name already exists")
+
+// ErrDuplicateOrgName is returned when an organization of that name already exists.
+var ErrDuplicateOrgName = errors.New("an organization with this name already exists")
+
+// ErrLastOwner is returned when a change would leave an organization without an owner.
+var ErrLastOwner = errors.New("an organization needs at least one owner")
+
+// ErrAlreadyDisputed is returned when a transaction already has a dispute.
+var ErrAlreadyDisputed = errors.New("this transaction has already been disputed")
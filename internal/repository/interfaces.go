@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/baharkarakas/insider-backend/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -15,22 +16,253 @@ type Users interface {
 	Update(u models.User) error
 	Delete(id string) error
 	Exists(ctx context.Context, id string) (bool, error)
+	// SetState changes the account state; before is the user as it was.
+	SetState(ctx context.Context, id string, c models.AccountStateChange) (before, after models.User, err error)
 }
 
+// Balances are kept per (wallet, currency). A user's id is the id of their main
+// wallet, so passing it addresses the main wallet.
 type Balances interface {
-	GetOrCreate(userID string) (models.Balance, error)
-	UpdateAmount(userID string, delta int64) (models.Balance, error)
-	Get(userID string) (models.Balance, error)
+	GetOrCreate(walletID, currency string) (models.Balance, error)
+	Get(walletID, currency string) (models.Balance, error)
+	// ListByUser lists every wallet of the user in every currency, main wallet first.
+	ListByUser(userID string) ([]models.Balance, error)
+	AmountAt(ctx context.Context, walletID, currency string, at time.Time) (int64, error)
+	HoldTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) error
+	ReleaseTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) error
+	// HoldUpToTx holds as much of amount as is available, possibly nothing, and
+	// returns how much it held.
+	HoldUpToTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) (int64, error)
+	// SetCreditLine sets how far below zero the balance may go and the overdraft rate.
+	SetCreditLine(ctx context.Context, userID, currency string, limit, rateBPS int64) (models.Balance, error)
+	// Overdrawn lists balances with an overdraft rate that were negative just before at.
+	Overdrawn(ctx context.Context, at time.Time) ([]models.OverdraftPosition, error)
+}
+
+type Wallets interface {
+	Create(ctx context.Context, w models.Wallet) (models.Wallet, error)
+	GetByID(ctx context.Context, id string) (models.Wallet, error)
+	ListByUser(ctx context.Context, userID string) ([]models.Wallet, error)
+	// Balances lists every wallet of the user with its balance in currency; wallets
+	// that never held it read as zero.
+	Balances(ctx context.Context, userID, currency string) ([]models.WalletBalance, error)
+}
+
+// Organizations are shared accounts; the organization's id is also the user id
+// that holds its money.
+type Organizations interface {
+	// Create opens the organization and its account row, with ownerID as owner.
+	Create(ctx context.Context, name, ownerID string) (models.Organization, error)
+	GetByID(ctx context.Context, id string) (models.Organization, error)
+	// ListByMember lists the organizations userID belongs to, with their role.
+	ListByMember(ctx context.Context, userID string) ([]models.Organization, error)
+	Member(ctx context.Context, orgID, userID string) (models.OrgMember, error)
+	Members(ctx context.Context, orgID string) ([]models.OrgMember, error)
+	// SetMember adds a member or changes their role. Both it and RemoveMember fail
+	// with ErrLastOwner rather than leave the organization without an owner.
+	SetMember(ctx context.Context, orgID, userID string, role models.OrgRole, addedBy string) (before models.OrgRole, m models.OrgMember, err error)
+	RemoveMember(ctx context.Context, orgID, userID string) (models.OrgMember, error)
 }
 
 type Transactions interface {
 	Create(tx models.Transaction) (models.Transaction, error)
+	CreateTx(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) (models.Transaction, error)
 	GetByID(id string) (models.Transaction, error)
-	ListByUser(userID string, limit, offset int) ([]models.Transaction, error)
+	GetForUpdateTx(ctx context.Context, pgtx pgx.Tx, id string) (models.Transaction, error)
+	GetByIdempotencyKey(key string) (models.Transaction, error)
+	// Search lists a user's transactions newest first, filtered and continued from f.After.
+	Search(ctx context.Context, userID string, f models.TransactionFilter, limit int) ([]models.Transaction, error)
+	// Statement streams a user's balance changes in one currency for [from, to), after
+	// handing begin the opening and closing balances of the same snapshot.
+	Statement(ctx context.Context, userID, currency string, from, to time.Time,
+		begin func(opening, closing int64) error, line func(models.StatementLine) error) error
+	// ListByStatus lists every transaction in status, oldest first.
+	ListByStatus(ctx context.Context, status models.TransactionStatus, limit, offset int) ([]models.Transaction, error)
+	SumChildrenTx(ctx context.Context, pgtx pgx.Tx, parentID string, typ models.TransactionType) (int64, error)
 	UpdateStatus(id string, status models.TransactionStatus) error
+	// ClearIdempotencyKey frees the key of a transaction that never moved money, so it can be retried.
+	ClearIdempotencyKey(id string) error
+	// TransitionTx moves id from one status to another inside tx; false if it was not in `from`.
+	TransitionTx(ctx context.Context, tx pgx.Tx, id string, from, to models.TransactionStatus) (bool, error)
 	WithTx(ctx context.Context, fn func(pgx.Tx) error) error
 }
 
+// Ledger is the double-entry journal. Balances are projected from its postings.
+type Ledger interface {
+	Post(ctx context.Context, tx pgx.Tx, e models.JournalEntry) (models.JournalEntry, error)
+	TrialBalance(ctx context.Context) (models.TrialBalance, error)
+}
+
+// Jobs is the durable work queue shared by every API replica.
+type Jobs interface {
+	Enqueue(ctx context.Context, kind, txnID string) error
+	EnqueuePending(ctx context.Context, types []string) (int64, error)
+	Claim(ctx context.Context, workerID string, limit int, lease time.Duration) ([]models.Job, error)
+	// FailExpired fails running jobs whose lease expired with no attempts left,
+	// and their pending transactions; returns the ids of those transactions.
+	FailExpired(ctx context.Context, lease time.Duration) ([]string, error)
+	Complete(ctx context.Context, id int64) error
+	Fail(ctx context.Context, id int64, errMsg string, retryAt time.Time) error
+	CountQueued(ctx context.Context) (int64, error)
+}
+
+// IdempotencyKeys persists request fingerprints and responses per (user, endpoint, key).
+type IdempotencyKeys interface {
+	Reserve(ctx context.Context, k models.IdempotencyKey) (models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, userID, endpoint, key string, status int, contentType string, body []byte) error
+	Delete(ctx context.Context, userID, endpoint, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type Holds interface {
+	CreateTx(ctx context.Context, tx pgx.Tx, h models.Hold) (models.Hold, error)
+	GetForUpdateTx(ctx context.Context, tx pgx.Tx, id string) (models.Hold, error)
+	SettleTx(ctx context.Context, tx pgx.Tx, id string, status models.HoldStatus, captured int64) (models.Hold, error)
+	ListByUser(userID string, limit, offset int) ([]models.Hold, error)
+	ListExpired(ctx context.Context, limit int) ([]models.Hold, error)
+}
+
+type Disputes interface {
+	// CreateTx fails with ErrAlreadyDisputed if the transaction was disputed before.
+	CreateTx(ctx context.Context, tx pgx.Tx, d models.Dispute) (models.Dispute, error)
+	GetByID(ctx context.Context, id string) (models.Dispute, error)
+	GetForUpdateTx(ctx context.Context, tx pgx.Tx, id string) (models.Dispute, error)
+	// ActiveForTransactionTx reports whether the transaction has an open or responded dispute.
+	ActiveForTransactionTx(ctx context.Context, tx pgx.Tx, txnID string) (bool, error)
+	// ListByUser lists disputes the user filed or received, newest first.
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]models.Dispute, error)
+	// List lists disputes in any of statuses, or all of them if none, oldest first.
+	List(ctx context.Context, statuses []models.DisputeStatus, limit, offset int) ([]models.Dispute, error)
+	// SaveTx writes the status, response and resolution of a dispute locked by GetForUpdateTx.
+	SaveTx(ctx context.Context, tx pgx.Tx, d models.Dispute) (models.Dispute, error)
+}
+
+type Schedules interface {
+	Create(s models.Schedule) (models.Schedule, error)
+	GetByID(id string) (models.Schedule, error)
+	ListByUser(userID string, limit, offset int) ([]models.Schedule, error)
+	Save(ctx context.Context, s models.Schedule) (models.Schedule, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Schedule, error)
+	AddRun(ctx context.Context, run models.ScheduleRun) error
+	ListRuns(scheduleID string, limit, offset int) ([]models.ScheduleRun, error)
+}
+
+type FXRates interface {
+	Create(ctx context.Context, r models.FXRate) (models.FXRate, error)
+	// Latest returns the current rate for base->quote, or pgx.ErrNoRows.
+	Latest(ctx context.Context, base, quote string) (models.FXRate, error)
+	// ListLatest returns the current rate of every pair.
+	ListLatest(ctx context.Context) ([]models.FXRate, error)
+}
+
+type FXConversions interface {
+	CreateTx(ctx context.Context, tx pgx.Tx, c models.FXConversion) (models.FXConversion, error)
+	ListByUser(userID string, limit, offset int) ([]models.FXConversion, error)
+}
+
+// LimitPolicies stores transaction limits and measures usage against them.
+type LimitPolicies interface {
+	List(ctx context.Context) ([]models.LimitPolicy, error)
+	GetByID(ctx context.Context, id string) (models.LimitPolicy, error)
+	Create(ctx context.Context, p models.LimitPolicy) (models.LimitPolicy, error)
+	Update(ctx context.Context, p models.LimitPolicy) (models.LimitPolicy, error)
+	Delete(ctx context.Context, id string) error
+	// Matching returns the policies for any of the subjects that cover typ in currency.
+	Matching(ctx context.Context, role, tier, userID, typ, currency string) ([]models.LimitPolicy, error)
+	// Usage sums userID's non-failed transactions of typ in currency over the rolling
+	// windows. Authorizations with a payee count as transfers.
+	Usage(ctx context.Context, userID string, typ models.TransactionType, currency string, now time.Time) (models.LimitUsage, error)
+	// UsageTx takes userID's limit lock, held until pgtx ends, and then sums only
+	// the transactions that already moved money, leaving out excludeID (if set).
+	UsageTx(ctx context.Context, pgtx pgx.Tx, userID string, typ models.TransactionType, currency, excludeID string, now time.Time) (models.LimitUsage, error)
+}
+
+type FeeRules interface {
+	List(ctx context.Context) ([]models.FeeRule, error)
+	GetByID(ctx context.Context, id string) (models.FeeRule, error)
+	Create(ctx context.Context, r models.FeeRule) (models.FeeRule, error)
+	Update(ctx context.Context, r models.FeeRule) (models.FeeRule, error)
+	Delete(ctx context.Context, id string) error
+	// Match returns the rule for role, or the '*' rule; pgx.ErrNoRows if neither exists.
+	Match(ctx context.Context, role string, typ models.TransactionType, currency string) (models.FeeRule, error)
+}
+
+// Savings stores savings interest rates and the daily accruals they earn.
+type Savings interface {
+	ListRates(ctx context.Context) ([]models.InterestTier, error)
+	RatesFor(ctx context.Context, currency string) ([]models.InterestTier, error)
+	// ReplaceRates swaps every band of currency for tiers at once.
+	ReplaceRates(ctx context.Context, currency string, tiers []models.InterestTier) ([]models.InterestTier, error)
+	Savers(ctx context.Context, day time.Time) ([]models.SaverPosition, error)
+	Accrue(ctx context.Context, a models.InterestAccrual) (bool, error)
+	// Due lists wallet balances with unpaid interest accrued before the given day.
+	Due(ctx context.Context, before time.Time) ([]models.InterestDue, error)
+	ClaimTx(ctx context.Context, tx pgx.Tx, walletID, currency string, before time.Time, payoutID string) (int64, error)
+	ListAccruals(ctx context.Context, userID, currency string, limit, offset int) ([]models.InterestAccrual, error)
+}
+
+type PaymentRequests interface {
+	Create(ctx context.Context, p models.PaymentRequest) (models.PaymentRequest, error)
+	GetByID(ctx context.Context, id string) (models.PaymentRequest, error)
+	// ListByUser takes a direction of models.RequestsIncoming, models.RequestsOutgoing or "" for both.
+	ListByUser(ctx context.Context, userID, direction string, status models.PaymentRequestStatus, limit, offset int) ([]models.PaymentRequest, error)
+	// Close declines or cancels a pending request; pgx.ErrNoRows if it is no longer
+	// pending or its pending transfer has not failed or been rejected.
+	Close(ctx context.Context, id string, status models.PaymentRequestStatus) (models.PaymentRequest, error)
+	MarkPaid(ctx context.Context, id, txID string) (models.PaymentRequest, error)
+	SetPending(ctx context.Context, id, txID string) (models.PaymentRequest, error)
+	// SettlePendingTx pays or rejects the requests waiting on transfer txID, which has just become status.
+	SettlePendingTx(ctx context.Context, pgtx pgx.Tx, txID string, status models.TransactionStatus) error
+}
+
+// RiskSignals answers the questions the risk rules ask about a user's history.
+type RiskSignals interface {
+	KnownRecipient(ctx context.Context, fromID, toID string) (bool, error)
+	RecipientsSince(ctx context.Context, fromID, toID string, since time.Time) (int, error)
+	OutgoingStats(ctx context.Context, userID, currency string, since time.Time) (count int, avg int64, err error)
+	RoundAmountsSince(ctx context.Context, userID, currency string, unit int64, since time.Time) (int, error)
+}
+
+// RiskCases is the reviewer queue of transactions held by risk screening.
+type RiskCases interface {
+	CreateCaseTx(ctx context.Context, tx pgx.Tx, c models.RiskCase) (models.RiskCase, error)
+	GetCase(ctx context.Context, id string) (models.RiskCase, error)
+	ListCases(ctx context.Context, status models.RiskCaseStatus, limit, offset int) ([]models.RiskCase, error)
+	DecideCaseTx(ctx context.Context, tx pgx.Tx, id string, status models.RiskCaseStatus, reviewerID string, note *string) (bool, error)
+}
+
+// Reconciliation finds balances that drifted from their transaction history.
+type Reconciliation interface {
+	// StartRun fails with ErrRunInProgress while another run is running.
+	StartRun(ctx context.Context, adjust bool) (models.ReconciliationRun, error)
+	FinishRun(ctx context.Context, run models.ReconciliationRun) error
+	// Drifts folds settled transactions per (user, currency) in one snapshot and
+	// returns the balances that don't match, plus how many balances were compared.
+	Drifts(ctx context.Context) ([]models.BalanceDrift, int, error)
+	AddDrift(ctx context.Context, d models.BalanceDrift) (models.BalanceDrift, error)
+	ListRuns(ctx context.Context, limit, offset int) ([]models.ReconciliationRun, error)
+	GetRun(ctx context.Context, id string) (models.ReconciliationRun, error)
+	// LatestRun returns the newest finished run.
+	LatestRun(ctx context.Context) (models.ReconciliationRun, error)
+	ListDrifts(ctx context.Context, runID string, limit, offset int) ([]models.BalanceDrift, error)
+}
+
+// Statements stores the frozen monthly statements.
+type Statements interface {
+	LastClosed(ctx context.Context) (start time.Time, ok bool, err error)
+	FirstActivity(ctx context.Context) (at time.Time, ok bool, err error)
+	// CloseMonth writes [start, end) for every wallet balance with history before end; returns rows written.
+	CloseMonth(ctx context.Context, start, end time.Time) (int64, error)
+	ListByUser(ctx context.Context, userID, currency string, limit, offset int) ([]models.MonthlyStatement, error)
+}
+
+// Batches stores batch transfers with their per-leg results.
+type Batches interface {
+	Create(ctx context.Context, b models.Batch) (models.Batch, error)
+	CreateTx(ctx context.Context, tx pgx.Tx, b models.Batch) (models.Batch, error)
+	GetByID(id string) (models.Batch, error)
+}
+
 type AuditLogs interface {
 	Create(l models.AuditLog) error
 }
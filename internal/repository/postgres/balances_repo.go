@@ -2,52 +2,218 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// balances are keyed by wallet; a user's id is the id of their main wallet
 type balancesRepo struct{ pool *pgxpool.Pool }
 
-func (r *balancesRepo) GetOrCreate(userID string) (models.Balance, error) {
-	if b, err := r.Get(userID); err == nil {
+const balanceColumns = `user_id, wallet_id, currency, amount, held_amount, credit_limit, overdraft_rate_bps, last_updated_at`
+
+func balanceDest(b *models.Balance) []any {
+	return []any{&b.UserID, &b.WalletID, &b.Currency, &b.Amount, &b.HeldAmount, &b.CreditLimit, &b.OverdraftRateBPS, &b.LastUpdatedAt}
+}
+
+func scanBalance(row pgx.Row) (models.Balance, error) {
+	var b models.Balance
+	err := row.Scan(balanceDest(&b)...)
+	b.Available = b.Amount - b.HeldAmount + b.CreditLimit
+	return b, err
+}
+
+func (r *balancesRepo) GetOrCreate(walletID, currency string) (models.Balance, error) {
+	if b, err := r.Get(walletID, currency); err == nil {
 		return b, nil
 	}
 	_, err := r.pool.Exec(
 		context.Background(),
-		`INSERT INTO balances(user_id, amount, last_updated_at)
-		 VALUES($1, 0, now())
-		 ON CONFLICT (user_id) DO NOTHING`,
-		userID,
+		`INSERT INTO balances(user_id, wallet_id, currency, amount, last_updated_at)
+		 SELECT user_id, id, $2, 0, now() FROM wallets WHERE id = $1
+		 ON CONFLICT (wallet_id, currency) DO NOTHING`,
+		walletID, currency,
 	)
 	if err != nil {
 		return models.Balance{}, err
 	}
-	return r.Get(userID)
+	return r.Get(walletID, currency)
 }
 
-func (r *balancesRepo) UpdateAmount(userID string, delta int64) (models.Balance, error) {
-	var b models.Balance
-	err := r.pool.QueryRow(
+func (r *balancesRepo) Get(walletID, currency string) (models.Balance, error) {
+	return scanBalance(r.pool.QueryRow(
 		context.Background(),
-		`UPDATE balances
-		    SET amount = amount + $2,
-		        last_updated_at = now()
-		  WHERE user_id = $1
-		  RETURNING user_id, amount, last_updated_at`,
-		userID, delta,
-	).Scan(&b.UserID, &b.Amount, &b.LastUpdatedAt)
-	return b, err
+		`SELECT `+balanceColumns+`
+		   FROM balances
+		  WHERE wallet_id=$1 AND currency=$2`,
+		walletID, currency,
+	))
 }
 
-func (r *balancesRepo) Get(userID string) (models.Balance, error) {
-	var b models.Balance
-	err := r.pool.QueryRow(
+func (r *balancesRepo) ListByUser(userID string) ([]models.Balance, error) {
+	rows, err := r.pool.Query(
 		context.Background(),
-		`SELECT user_id, amount, last_updated_at
+		`SELECT `+balanceColumns+`
 		   FROM balances
-		  WHERE user_id=$1`,
+		  WHERE user_id=$1
+		  ORDER BY currency, wallet_id <> user_id, wallet_id`,
 		userID,
-	).Scan(&b.UserID, &b.Amount, &b.LastUpdatedAt)
-	return b, err
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Balance
+	for rows.Next() {
+		b, err := scanBalance(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// applyDelta changes the balance inside tx and appends a balance_history row.
+// Only the ledger calls it: balances are a projection of postings.
+// Held funds are not spendable, so the guard is on the available amount, which
+// includes the credit line. Incoming money is never refused, even on a balance
+// that is over its limit; unguarded skips the check for charges that are booked
+// regardless (overdraft interest).
+func (r *balancesRepo) applyDelta(ctx context.Context, tx pgx.Tx, walletID, currency, txnID string, delta int64, unguarded bool) (models.Balance, error) {
+	b, err := scanBalance(tx.QueryRow(ctx,
+		`UPDATE balances
+		    SET amount = amount + $3,
+		        last_updated_at = now()
+		  WHERE wallet_id = $1 AND currency = $2
+		    AND ($4 OR $3 >= 0 OR amount - held_amount + credit_limit + $3 >= 0)
+		  RETURNING `+balanceColumns,
+		walletID, currency, delta, unguarded,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Balance{}, repository.ErrInsufficientFunds
+	}
+	if err != nil {
+		return models.Balance{}, err
+	}
+
+	var ref *string
+	if txnID != "" {
+		ref = &txnID
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO balance_history(user_id, wallet_id, currency, delta, amount, transaction_id, created_at)
+		 VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		b.UserID, b.WalletID, currency, delta, b.Amount, ref, b.LastUpdatedAt,
+	); err != nil {
+		return models.Balance{}, err
+	}
+	return b, nil
+}
+
+func (r *balancesRepo) AmountAt(ctx context.Context, walletID, currency string, at time.Time) (int64, error) {
+	var amount int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT amount
+		   FROM balance_history
+		  WHERE wallet_id = $1 AND currency = $2 AND created_at <= $3
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT 1`,
+		walletID, currency, at,
+	).Scan(&amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return amount, err
+}
+
+// HoldTx reserves amount of the available balance inside tx.
+func (r *balancesRepo) HoldTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) error {
+	tag, err := tx.Exec(ctx,
+		`UPDATE balances
+		    SET held_amount = held_amount + $3, last_updated_at = now()
+		  WHERE wallet_id = $1 AND currency = $2 AND amount - held_amount + credit_limit >= $3`,
+		walletID, currency, amount,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrInsufficientFunds
+	}
+	return nil
+}
+
+// ReleaseTx returns previously held funds to the available balance inside tx.
+func (r *balancesRepo) ReleaseTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE balances
+		    SET held_amount = held_amount - $3, last_updated_at = now()
+		  WHERE wallet_id = $1 AND currency = $2`,
+		walletID, currency, amount,
+	)
+	return err
+}
+
+// HoldUpToTx holds min(amount, available) inside tx, never taking available below zero.
+func (r *balancesRepo) HoldUpToTx(ctx context.Context, tx pgx.Tx, walletID, currency string, amount int64) (int64, error) {
+	var held int64
+	err := tx.QueryRow(ctx,
+		`WITH b AS (
+		     SELECT LEAST($3, GREATEST(amount - held_amount + credit_limit, 0)) AS hold
+		       FROM balances
+		      WHERE wallet_id = $1 AND currency = $2
+		      FOR UPDATE
+		 )
+		 UPDATE balances
+		    SET held_amount = held_amount + b.hold, last_updated_at = now()
+		   FROM b
+		  WHERE wallet_id = $1 AND currency = $2
+		 RETURNING b.hold`,
+		walletID, currency, amount,
+	).Scan(&held)
+	return held, err
+}
+
+// SetCreditLine grants, changes or (with 0) withdraws a credit line on the main wallet.
+func (r *balancesRepo) SetCreditLine(ctx context.Context, userID, currency string, limit, rateBPS int64) (models.Balance, error) {
+	return scanBalance(r.pool.QueryRow(ctx,
+		`INSERT INTO balances(user_id, wallet_id, currency, amount, credit_limit, overdraft_rate_bps, last_updated_at)
+		 VALUES($1, $1, $2, 0, $3, $4, now())
+		 ON CONFLICT (wallet_id, currency) DO UPDATE
+		    SET credit_limit = EXCLUDED.credit_limit, overdraft_rate_bps = EXCLUDED.overdraft_rate_bps
+		 RETURNING `+balanceColumns,
+		userID, currency, limit, rateBPS,
+	))
+}
+
+// Overdrawn lists the balances with an overdraft rate that were negative at the end of [.., at).
+func (r *balancesRepo) Overdrawn(ctx context.Context, at time.Time) ([]models.OverdraftPosition, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.user_id, b.wallet_id, b.currency, h.amount, b.overdraft_rate_bps
+		   FROM balances b
+		   CROSS JOIN LATERAL (
+		        SELECT amount FROM balance_history
+		         WHERE wallet_id = b.wallet_id AND currency = b.currency AND created_at < $1
+		         ORDER BY created_at DESC, id DESC LIMIT 1) h
+		  WHERE b.overdraft_rate_bps > 0 AND h.amount < 0`,
+		at,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.OverdraftPosition
+	for rows.Next() {
+		var p models.OverdraftPosition
+		if err := rows.Scan(&p.UserID, &p.WalletID, &p.Currency, &p.Amount, &p.RateBPS); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
 }
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type batchesRepo struct{ pool *pgxpool.Pool }
+
+const batchColumns = `id, user_id, currency, mode, status, total_amount, created_at`
+
+func (r *batchesRepo) Create(ctx context.Context, b models.Batch) (models.Batch, error) {
+	var out models.Batch
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		out, err = r.create(ctx, tx, b)
+		return err
+	})
+	return out, err
+}
+
+func (r *batchesRepo) CreateTx(ctx context.Context, tx pgx.Tx, b models.Batch) (models.Batch, error) {
+	return r.create(ctx, tx, b)
+}
+
+func (r *batchesRepo) create(ctx context.Context, tx pgx.Tx, b models.Batch) (models.Batch, error) {
+	if b.ID == "" {
+		b.ID = uuid.NewString()
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO transaction_batches(id, user_id, currency, mode, status, total_amount)
+		 VALUES($1, $2, $3, $4, $5, $6)
+		 RETURNING created_at`,
+		b.ID, b.UserID, b.Currency, b.Mode, b.Status, b.TotalAmount,
+	).Scan(&b.CreatedAt); err != nil {
+		return models.Batch{}, err
+	}
+	for _, l := range b.Legs {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO transaction_batch_legs(batch_id, idx, to_user_id, amount, status, transaction_id, error)
+			 VALUES($1, $2, $3, $4, $5, $6, $7)`,
+			b.ID, l.Index, l.ToUserID, l.Amount, l.Status, l.TransactionID, l.Error,
+		); err != nil {
+			return models.Batch{}, err
+		}
+	}
+	return b, nil
+}
+
+func (r *batchesRepo) GetByID(id string) (models.Batch, error) {
+	ctx := context.Background()
+	var b models.Batch
+	if err := r.pool.QueryRow(ctx,
+		`SELECT `+batchColumns+` FROM transaction_batches WHERE id=$1`, id,
+	).Scan(&b.ID, &b.UserID, &b.Currency, &b.Mode, &b.Status, &b.TotalAmount, &b.CreatedAt); err != nil {
+		return models.Batch{}, err
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT idx, to_user_id, amount, status, transaction_id, error
+		   FROM transaction_batch_legs
+		  WHERE batch_id=$1
+		  ORDER BY idx`, id)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l models.BatchLeg
+		if err := rows.Scan(&l.Index, &l.ToUserID, &l.Amount, &l.Status, &l.TransactionID, &l.Error); err != nil {
+			return models.Batch{}, err
+		}
+		b.Legs = append(b.Legs, l)
+	}
+	return b, rows.Err()
+}
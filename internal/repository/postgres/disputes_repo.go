@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type disputesRepo struct{ pool *pgxpool.Pool }
+
+const disputeColumns = `id, transaction_id, sender_id, recipient_id, hold_wallet_id, amount, held_amount, currency,
+	reason, evidence, response, status, resolved_by, resolution_note, chargeback_id, created_at, updated_at, resolved_at`
+
+func scanDispute(row pgx.Row) (models.Dispute, error) {
+	var d models.Dispute
+	err := row.Scan(&d.ID, &d.TransactionID, &d.SenderID, &d.RecipientID, &d.HoldWalletID, &d.Amount, &d.HeldAmount, &d.Currency,
+		&d.Reason, &d.Evidence, &d.Response, &d.Status, &d.ResolvedBy, &d.ResolutionNote, &d.ChargebackID,
+		&d.CreatedAt, &d.UpdatedAt, &d.ResolvedAt)
+	return d, err
+}
+
+func collectDisputes(rows pgx.Rows, err error) ([]models.Dispute, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Dispute{}
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *disputesRepo) CreateTx(ctx context.Context, tx pgx.Tx, d models.Dispute) (models.Dispute, error) {
+	out, err := scanDispute(tx.QueryRow(ctx,
+		`INSERT INTO disputes(transaction_id, sender_id, recipient_id, hold_wallet_id, amount, held_amount, currency, reason, evidence)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		 RETURNING `+disputeColumns,
+		d.TransactionID, d.SenderID, d.RecipientID, d.HoldWalletID, d.Amount, d.HeldAmount, d.Currency, d.Reason, d.Evidence,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return models.Dispute{}, repository.ErrAlreadyDisputed
+	}
+	return out, err
+}
+
+func (r *disputesRepo) GetByID(ctx context.Context, id string) (models.Dispute, error) {
+	return scanDispute(r.pool.QueryRow(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = $1`, id))
+}
+
+func (r *disputesRepo) GetForUpdateTx(ctx context.Context, tx pgx.Tx, id string) (models.Dispute, error) {
+	return scanDispute(tx.QueryRow(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = $1 FOR UPDATE`, id))
+}
+
+func (r *disputesRepo) ActiveForTransactionTx(ctx context.Context, tx pgx.Tx, txnID string) (bool, error) {
+	var active bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM disputes WHERE transaction_id = $1 AND status IN ('open','responded'))`,
+		txnID,
+	).Scan(&active)
+	return active, err
+}
+
+func (r *disputesRepo) ListByUser(ctx context.Context, userID string, limit, offset int) ([]models.Dispute, error) {
+	return collectDisputes(r.pool.Query(ctx,
+		`SELECT `+disputeColumns+`
+		   FROM disputes
+		  WHERE sender_id = $1 OR recipient_id = $1
+		  ORDER BY created_at DESC, id
+		  LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	))
+}
+
+func (r *disputesRepo) List(ctx context.Context, statuses []models.DisputeStatus, limit, offset int) ([]models.Dispute, error) {
+	var names []string
+	for _, s := range statuses {
+		names = append(names, string(s))
+	}
+	return collectDisputes(r.pool.Query(ctx,
+		`SELECT `+disputeColumns+`
+		   FROM disputes
+		  WHERE ($1::text[] IS NULL OR status = ANY($1))
+		  ORDER BY created_at, id
+		  LIMIT $2 OFFSET $3`,
+		names, limit, offset,
+	))
+}
+
+func (r *disputesRepo) SaveTx(ctx context.Context, tx pgx.Tx, d models.Dispute) (models.Dispute, error) {
+	return scanDispute(tx.QueryRow(ctx,
+		`UPDATE disputes
+		    SET status = $2, response = $3, resolved_by = $4, resolution_note = $5, chargeback_id = $6,
+		        resolved_at = CASE WHEN $2 IN ('open','responded') THEN NULL ELSE COALESCE(resolved_at, now()) END,
+		        updated_at = now()
+		  WHERE id = $1
+		 RETURNING `+disputeColumns,
+		d.ID, d.Status, d.Response, d.ResolvedBy, d.ResolutionNote, d.ChargebackID,
+	))
+}
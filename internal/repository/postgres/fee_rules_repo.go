@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type feeRulesRepo struct{ pool *pgxpool.Pool }
+
+const feeRuleColumns = `id, role, txn_type, currency, flat, bps, tiers, min_fee, max_fee, created_at, updated_at`
+
+func scanFeeRule(row pgx.Row) (models.FeeRule, error) {
+	var r models.FeeRule
+	err := row.Scan(&r.ID, &r.Role, &r.TxnType, &r.Currency, &r.Flat, &r.BPS, &r.Tiers, &r.MinFee, &r.MaxFee,
+		&r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+func tiersParam(ts []models.FeeTier) []models.FeeTier {
+	if ts == nil {
+		return []models.FeeTier{}
+	}
+	return ts
+}
+
+func (r *feeRulesRepo) List(ctx context.Context) ([]models.FeeRule, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+feeRuleColumns+` FROM fee_rules ORDER BY txn_type, currency, role`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.FeeRule
+	for rows.Next() {
+		fr, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fr)
+	}
+	return out, rows.Err()
+}
+
+func (r *feeRulesRepo) GetByID(ctx context.Context, id string) (models.FeeRule, error) {
+	return scanFeeRule(r.pool.QueryRow(ctx,
+		`SELECT `+feeRuleColumns+` FROM fee_rules WHERE id=$1`, id))
+}
+
+func (r *feeRulesRepo) Create(ctx context.Context, fr models.FeeRule) (models.FeeRule, error) {
+	if fr.ID == "" {
+		fr.ID = uuid.NewString()
+	}
+	return scanFeeRule(r.pool.QueryRow(ctx,
+		`INSERT INTO fee_rules(id, role, txn_type, currency, flat, bps, tiers, min_fee, max_fee)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		 RETURNING `+feeRuleColumns,
+		fr.ID, fr.Role, fr.TxnType, fr.Currency, fr.Flat, fr.BPS, tiersParam(fr.Tiers), fr.MinFee, fr.MaxFee,
+	))
+}
+
+func (r *feeRulesRepo) Update(ctx context.Context, fr models.FeeRule) (models.FeeRule, error) {
+	return scanFeeRule(r.pool.QueryRow(ctx,
+		`UPDATE fee_rules
+		    SET flat=$2, bps=$3, tiers=$4, min_fee=$5, max_fee=$6, updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+feeRuleColumns,
+		fr.ID, fr.Flat, fr.BPS, tiersParam(fr.Tiers), fr.MinFee, fr.MaxFee,
+	))
+}
+
+func (r *feeRulesRepo) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM fee_rules WHERE id=$1`, id)
+	if err == nil && tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+// Match returns the rule for role, falling back to the '*' rule.
+func (r *feeRulesRepo) Match(ctx context.Context, role string, typ models.TransactionType, currency string) (models.FeeRule, error) {
+	return scanFeeRule(r.pool.QueryRow(ctx,
+		`SELECT `+feeRuleColumns+`
+		   FROM fee_rules
+		  WHERE role IN ($1, '*') AND txn_type = $2 AND currency = $3
+		  ORDER BY role = '*'
+		  LIMIT 1`,
+		role, typ, currency,
+	))
+}
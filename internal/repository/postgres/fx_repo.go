@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type fxRatesRepo struct{ pool *pgxpool.Pool }
+
+// rate is read back as text so it never passes through a float.
+const fxRateColumns = `id, base_currency, quote_currency, rate::text, created_by, created_at`
+
+func scanFXRate(row pgx.Row) (models.FXRate, error) {
+	var r models.FXRate
+	err := row.Scan(&r.ID, &r.BaseCurrency, &r.QuoteCurrency, &r.Rate, &r.CreatedBy, &r.CreatedAt)
+	return r, err
+}
+
+func (r *fxRatesRepo) Create(ctx context.Context, fr models.FXRate) (models.FXRate, error) {
+	return scanFXRate(r.pool.QueryRow(ctx,
+		`INSERT INTO fx_rates(base_currency, quote_currency, rate, created_by)
+		 VALUES($1, $2, $3::numeric, $4)
+		 RETURNING `+fxRateColumns,
+		fr.BaseCurrency, fr.QuoteCurrency, fr.Rate, fr.CreatedBy,
+	))
+}
+
+func (r *fxRatesRepo) Latest(ctx context.Context, base, quote string) (models.FXRate, error) {
+	return scanFXRate(r.pool.QueryRow(ctx,
+		`SELECT `+fxRateColumns+`
+		   FROM fx_rates
+		  WHERE base_currency=$1 AND quote_currency=$2
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT 1`,
+		base, quote,
+	))
+}
+
+func (r *fxRatesRepo) ListLatest(ctx context.Context) ([]models.FXRate, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT ON (base_currency, quote_currency) `+fxRateColumns+`
+		   FROM fx_rates
+		  ORDER BY base_currency, quote_currency, created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.FXRate
+	for rows.Next() {
+		fr, err := scanFXRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fr)
+	}
+	return out, rows.Err()
+}
+
+type fxConversionsRepo struct{ pool *pgxpool.Pool }
+
+const fxConversionColumns = `id, user_id, to_user_id, from_currency, to_currency, from_amount, to_amount,
+       rate::text, fx_rate_id, debit_transaction_id, credit_transaction_id, created_at`
+
+func scanFXConversion(row pgx.Row) (models.FXConversion, error) {
+	var c models.FXConversion
+	err := row.Scan(&c.ID, &c.UserID, &c.ToUserID, &c.FromCurrency, &c.ToCurrency, &c.FromAmount, &c.ToAmount,
+		&c.Rate, &c.FXRateID, &c.DebitTransactionID, &c.CreditTransactionID, &c.CreatedAt)
+	return c, err
+}
+
+func (r *fxConversionsRepo) CreateTx(ctx context.Context, tx pgx.Tx, c models.FXConversion) (models.FXConversion, error) {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	return scanFXConversion(tx.QueryRow(ctx,
+		`INSERT INTO fx_conversions(id, user_id, to_user_id, from_currency, to_currency, from_amount, to_amount,
+		                            rate, fx_rate_id, debit_transaction_id, credit_transaction_id)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8::numeric, $9, $10, $11)
+		 RETURNING `+fxConversionColumns,
+		c.ID, c.UserID, c.ToUserID, c.FromCurrency, c.ToCurrency, c.FromAmount, c.ToAmount,
+		c.Rate, c.FXRateID, c.DebitTransactionID, c.CreditTransactionID,
+	))
+}
+
+func (r *fxConversionsRepo) ListByUser(userID string, limit, offset int) ([]models.FXConversion, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+fxConversionColumns+`
+		   FROM fx_conversions
+		  WHERE user_id=$1 OR to_user_id=$1
+		  ORDER BY created_at DESC
+		  LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.FXConversion
+	for rows.Next() {
+		c, err := scanFXConversion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type holdsRepo struct{ pool *pgxpool.Pool }
+
+const holdColumns = `id, user_id, payee_user_id, amount, currency, captured_amount, status, transaction_id, expires_at, created_at, updated_at`
+
+func scanHold(row pgx.Row) (models.Hold, error) {
+	var h models.Hold
+	err := row.Scan(&h.ID, &h.UserID, &h.PayeeUserID, &h.Amount, &h.Currency, &h.CapturedAmount, &h.Status,
+		&h.TransactionID, &h.ExpiresAt, &h.CreatedAt, &h.UpdatedAt)
+	return h, err
+}
+
+func scanHolds(rows pgx.Rows) ([]models.Hold, error) {
+	defer rows.Close()
+	var out []models.Hold
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+func (r *holdsRepo) CreateTx(ctx context.Context, tx pgx.Tx, h models.Hold) (models.Hold, error) {
+	if h.ID == "" {
+		h.ID = uuid.NewString()
+	}
+	return scanHold(tx.QueryRow(ctx,
+		`INSERT INTO holds(id, user_id, payee_user_id, amount, currency, status, transaction_id, expires_at)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING `+holdColumns,
+		h.ID, h.UserID, h.PayeeUserID, h.Amount, h.Currency, h.Status, h.TransactionID, h.ExpiresAt,
+	))
+}
+
+func (r *holdsRepo) GetForUpdateTx(ctx context.Context, tx pgx.Tx, id string) (models.Hold, error) {
+	return scanHold(tx.QueryRow(ctx, `SELECT `+holdColumns+` FROM holds WHERE id=$1 FOR UPDATE`, id))
+}
+
+func (r *holdsRepo) SettleTx(ctx context.Context, tx pgx.Tx, id string, status models.HoldStatus, captured int64) (models.Hold, error) {
+	return scanHold(tx.QueryRow(ctx,
+		`UPDATE holds SET status=$2, captured_amount=$3, updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+holdColumns,
+		id, status, captured,
+	))
+}
+
+func (r *holdsRepo) ListByUser(userID string, limit, offset int) ([]models.Hold, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+holdColumns+`
+		   FROM holds
+		  WHERE user_id=$1 OR payee_user_id=$1
+		  ORDER BY created_at DESC
+		  LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanHolds(rows)
+}
+
+func (r *holdsRepo) ListExpired(ctx context.Context, limit int) ([]models.Hold, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+holdColumns+`
+		   FROM holds
+		  WHERE status='authorized' AND expires_at <= now()
+		  ORDER BY expires_at
+		  LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanHolds(rows)
+}
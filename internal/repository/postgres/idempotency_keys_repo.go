@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type idempotencyKeysRepo struct{ pool *pgxpool.Pool }
+
+const idempotencyKeyCols = `user_id, endpoint, key, request_hash, status_code, response_body, content_type, locked_until,
+	created_at, expires_at`
+
+func scanIdempotencyKey(row pgx.Row) (models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+	var contentType *string
+	err := row.Scan(&k.UserID, &k.Endpoint, &k.Key, &k.RequestHash, &k.StatusCode, &k.ResponseBody, &contentType,
+		&k.LockedUntil, &k.CreatedAt, &k.ExpiresAt)
+	if contentType != nil {
+		k.ContentType = *contentType
+	}
+	return k, err
+}
+
+// Reserve inserts k. An expired row with the same key is taken over, and so is
+// one still in flight past its lease for the same request: its request died, or
+// is slow enough to be retried. If a live row exists it is returned with reserved=false.
+func (r *idempotencyKeysRepo) Reserve(ctx context.Context, k models.IdempotencyKey) (models.IdempotencyKey, bool, error) {
+	got, err := scanIdempotencyKey(r.pool.QueryRow(ctx,
+		`INSERT INTO idempotency_keys(user_id, endpoint, key, request_hash, locked_until, expires_at)
+		 VALUES($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, endpoint, key) DO UPDATE
+		    SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL, content_type = NULL,
+		        locked_until = EXCLUDED.locked_until, created_at = now(), expires_at = EXCLUDED.expires_at
+		  WHERE idempotency_keys.expires_at <= now()
+		     OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= now()
+		         AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+		 RETURNING `+idempotencyKeyCols,
+		k.UserID, k.Endpoint, k.Key, k.RequestHash, k.LockedUntil, k.ExpiresAt,
+	))
+	if err == nil {
+		return got, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.IdempotencyKey{}, false, err
+	}
+	got, err = scanIdempotencyKey(r.pool.QueryRow(ctx,
+		`SELECT `+idempotencyKeyCols+` FROM idempotency_keys WHERE user_id=$1 AND endpoint=$2 AND key=$3`,
+		k.UserID, k.Endpoint, k.Key,
+	))
+	return got, false, err
+}
+
+// Complete stores the response of a request in flight. When a retry took the key
+// over, whichever of the two finishes first is kept.
+func (r *idempotencyKeysRepo) Complete(ctx context.Context, userID, endpoint, key string, status int, contentType string, body []byte) error {
+	if len(body) == 0 {
+		body = nil
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE idempotency_keys SET status_code=$4, content_type=NULLIF($5, ''), response_body=$6, locked_until=NULL
+		  WHERE user_id=$1 AND endpoint=$2 AND key=$3 AND status_code IS NULL`,
+		userID, endpoint, key, status, contentType, body,
+	)
+	return err
+}
+
+// Delete drops a reservation still in flight; a stored response stays.
+func (r *idempotencyKeysRepo) Delete(ctx context.Context, userID, endpoint, key string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE user_id=$1 AND endpoint=$2 AND key=$3 AND status_code IS NULL`,
+		userID, endpoint, key,
+	)
+	return err
+}
+
+func (r *idempotencyKeysRepo) PurgeExpired(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
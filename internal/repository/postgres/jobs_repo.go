@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type jobsRepo struct{ pool *pgxpool.Pool }
+
+func (r *jobsRepo) Enqueue(ctx context.Context, kind, txnID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO jobs(kind, transaction_id)
+		 VALUES($1, $2)
+		 ON CONFLICT (kind, transaction_id) WHERE transaction_id IS NOT NULL DO NOTHING`,
+		kind, txnID,
+	)
+	return err
+}
+
+// EnqueuePending queues a job for every pending transaction of the given types that has none yet.
+func (r *jobsRepo) EnqueuePending(ctx context.Context, types []string) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO jobs(kind, transaction_id)
+		 SELECT t.type, t.id
+		   FROM transactions t
+		  WHERE t.status = 'pending' AND t.type = ANY($1)
+		 ON CONFLICT (kind, transaction_id) WHERE transaction_id IS NOT NULL DO NOTHING`,
+		types,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Claim locks up to limit due jobs for workerID. Jobs whose lease expired
+// (the worker died mid-run) are claimable again while they have attempts left;
+// FailExpired retires the rest.
+func (r *jobsRepo) Claim(ctx context.Context, workerID string, limit int, lease time.Duration) ([]models.Job, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE jobs
+		    SET status = 'running', locked_at = now(), locked_by = $1,
+		        attempts = attempts + 1, updated_at = now()
+		  WHERE id IN (
+		        SELECT id FROM jobs
+		         WHERE (status = 'queued' AND run_at <= now())
+		            OR (status = 'running' AND locked_at < now() - make_interval(secs => $3)
+		                AND attempts < max_attempts)
+		         ORDER BY run_at
+		         LIMIT $2
+		         FOR UPDATE SKIP LOCKED)
+		  RETURNING id, kind, transaction_id, status, attempts, max_attempts, run_at, locked_at, locked_by, last_error, created_at`,
+		workerID, limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Job
+	for rows.Next() {
+		var j models.Job
+		if err := rows.Scan(&j.ID, &j.Kind, &j.TransactionID, &j.Status, &j.Attempts, &j.MaxAttempts,
+			&j.RunAt, &j.LockedAt, &j.LockedBy, &j.LastError, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+// FailExpired marks failed the running jobs whose lease expired on their last
+// attempt, so a job that keeps killing its worker stops being retried. Their
+// transactions that are still pending fail with them in the same statement,
+// since no job will run for them again; it returns those transactions' ids.
+func (r *jobsRepo) FailExpired(ctx context.Context, lease time.Duration) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH j AS (
+		    UPDATE jobs
+		       SET status = 'failed', last_error = 'lease expired on the last attempt',
+		           locked_at = NULL, locked_by = NULL, updated_at = now()
+		     WHERE status = 'running' AND locked_at < now() - make_interval(secs => $1)
+		       AND attempts >= max_attempts
+		     RETURNING transaction_id)
+		 UPDATE transactions t
+		    SET status = 'failed'
+		   FROM j
+		  WHERE t.id = j.transaction_id AND t.status = 'pending'
+		 RETURNING t.id`,
+		lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *jobsRepo) Complete(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'done', locked_at = NULL, locked_by = NULL, updated_at = now() WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+// Fail records errMsg and either requeues the job at retryAt or, once attempts are exhausted, marks it failed.
+func (r *jobsRepo) Fail(ctx context.Context, id int64, errMsg string, retryAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE jobs
+		    SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'queued' END,
+		        run_at = $3, last_error = $2, locked_at = NULL, locked_by = NULL, updated_at = now()
+		  WHERE id = $1`,
+		id, errMsg, retryAt,
+	)
+	return err
+}
+
+func (r *jobsRepo) CountQueued(ctx context.Context) (int64, error) {
+	var n int64
+	err := r.pool.QueryRow(ctx, `SELECT count(*) FROM jobs WHERE status IN ('queued','running')`).Scan(&n)
+	return n, err
+}
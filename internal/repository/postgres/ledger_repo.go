@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ledgerRepo struct {
+	pool *pgxpool.Pool
+	bal  *balancesRepo
+}
+
+// Post writes a balanced journal entry inside tx and projects user postings onto balances.
+func (r *ledgerRepo) Post(ctx context.Context, tx pgx.Tx, e models.JournalEntry) (models.JournalEntry, error) {
+	if err := e.Validate(); err != nil {
+		return models.JournalEntry{}, err
+	}
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO journal_entries(id, transaction_id, description)
+		 VALUES($1, $2, $3)
+		 RETURNING created_at`,
+		e.ID, e.TransactionID, e.Description,
+	).Scan(&e.CreatedAt); err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	var txnID string
+	if e.TransactionID != nil {
+		txnID = *e.TransactionID
+	}
+	for i := range e.Postings {
+		p := &e.Postings[i]
+		p.EntryID = e.ID
+		accountID, err := r.accountID(ctx, tx, p.AccountCode)
+		if err != nil {
+			return models.JournalEntry{}, err
+		}
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO postings(entry_id, account_id, direction, amount)
+			 VALUES($1, $2, $3, $4)
+			 RETURNING id`,
+			e.ID, accountID, p.Direction, p.Amount,
+		).Scan(&p.ID); err != nil {
+			return models.JournalEntry{}, err
+		}
+
+		// user wallets are credit-normal: credits raise the balance, debits lower it
+		if walletID, ok := models.WalletIDFromAccountCode(p.AccountCode); ok {
+			delta := p.Amount
+			if p.Direction == models.PostingDebit {
+				delta = -delta
+			}
+			if _, err := r.bal.applyDelta(ctx, tx, walletID, models.AccountCurrency(p.AccountCode), txnID, delta, e.Unguarded); err != nil {
+				return models.JournalEntry{}, err
+			}
+		}
+	}
+	return e, nil
+}
+
+// accountID resolves an account code, opening accounts on first use in a currency.
+func (r *ledgerRepo) accountID(ctx context.Context, tx pgx.Tx, code string) (string, error) {
+	var id string
+	err := tx.QueryRow(ctx, `SELECT id FROM ledger_accounts WHERE code=$1`, code).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	cur := models.AccountCurrency(code)
+	if _, err := currency.Lookup(cur); err != nil {
+		return "", fmt.Errorf("unknown ledger account %q", code)
+	}
+	kind := "system"
+	var userID *string
+	if uid, ok := models.UserIDFromAccountCode(code); ok {
+		kind, userID = "user", &uid
+	} else if !isSystemAccount(strings.TrimSuffix(code, ":"+cur)) {
+		return "", fmt.Errorf("unknown ledger account %q", code)
+	}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO ledger_accounts(code, kind, user_id, currency)
+		 VALUES($1, $2, $3, $4)
+		 ON CONFLICT (code) DO UPDATE SET code = EXCLUDED.code
+		 RETURNING id`,
+		code, kind, userID, cur,
+	).Scan(&id)
+	return id, err
+}
+
+func isSystemAccount(base string) bool {
+	switch base {
+	case models.AccountFunding, models.AccountPayout, models.AccountOpening, models.AccountFX, models.AccountAdjustment,
+		models.AccountRevenue, models.AccountInterest:
+		return true
+	}
+	return false
+}
+
+func (r *ledgerRepo) TrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT a.id, a.code, a.kind, a.currency,
+		        COALESCE(SUM(p.amount) FILTER (WHERE p.direction = 'debit'), 0),
+		        COALESCE(SUM(p.amount) FILTER (WHERE p.direction = 'credit'), 0)
+		   FROM ledger_accounts a
+		   LEFT JOIN postings p ON p.account_id = a.id
+		  GROUP BY a.id, a.code, a.kind, a.currency
+		  ORDER BY a.currency, a.kind DESC, a.code`)
+	if err != nil {
+		return models.TrialBalance{}, err
+	}
+	defer rows.Close()
+
+	tb := models.TrialBalance{Balanced: true}
+	for rows.Next() {
+		var ab models.AccountBalance
+		if err := rows.Scan(&ab.AccountID, &ab.Code, &ab.Kind, &ab.Currency, &ab.Debits, &ab.Credits); err != nil {
+			return models.TrialBalance{}, err
+		}
+		// rows are ordered by currency, so each currency's totals are contiguous
+		if n := len(tb.Currencies); n == 0 || tb.Currencies[n-1].Currency != ab.Currency {
+			tb.Currencies = append(tb.Currencies, models.CurrencyTotals{Currency: ab.Currency})
+		}
+		t := &tb.Currencies[len(tb.Currencies)-1]
+		t.TotalDebits += ab.Debits
+		t.TotalCredits += ab.Credits
+		tb.Accounts = append(tb.Accounts, ab)
+	}
+	for i := range tb.Currencies {
+		t := &tb.Currencies[i]
+		t.Balanced = t.TotalDebits == t.TotalCredits
+		tb.Balanced = tb.Balanced && t.Balanced
+	}
+	return tb, rows.Err()
+}
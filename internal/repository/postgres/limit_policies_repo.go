@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type limitPoliciesRepo struct{ pool *pgxpool.Pool }
+
+const limitPolicyColumns = `id, scope, subject, txn_type, currency, max_amount, daily_amount, monthly_amount,
+       daily_count, monthly_count, created_at, updated_at`
+
+func scanLimitPolicy(row pgx.Row) (models.LimitPolicy, error) {
+	var p models.LimitPolicy
+	err := row.Scan(&p.ID, &p.Scope, &p.Subject, &p.TxnType, &p.Currency, &p.MaxAmount, &p.DailyAmount, &p.MonthlyAmount,
+		&p.DailyCount, &p.MonthlyCount, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func scanLimitPolicies(rows pgx.Rows) ([]models.LimitPolicy, error) {
+	defer rows.Close()
+	var out []models.LimitPolicy
+	for rows.Next() {
+		p, err := scanLimitPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *limitPoliciesRepo) List(ctx context.Context) ([]models.LimitPolicy, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+limitPolicyColumns+` FROM limit_policies ORDER BY scope, subject, txn_type, currency`)
+	if err != nil {
+		return nil, err
+	}
+	return scanLimitPolicies(rows)
+}
+
+func (r *limitPoliciesRepo) GetByID(ctx context.Context, id string) (models.LimitPolicy, error) {
+	return scanLimitPolicy(r.pool.QueryRow(ctx,
+		`SELECT `+limitPolicyColumns+` FROM limit_policies WHERE id=$1`, id))
+}
+
+func (r *limitPoliciesRepo) Create(ctx context.Context, p models.LimitPolicy) (models.LimitPolicy, error) {
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	return scanLimitPolicy(r.pool.QueryRow(ctx,
+		`INSERT INTO limit_policies(id, scope, subject, txn_type, currency, max_amount, daily_amount,
+		                            monthly_amount, daily_count, monthly_count)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		 RETURNING `+limitPolicyColumns,
+		p.ID, p.Scope, p.Subject, p.TxnType, p.Currency, p.MaxAmount, p.DailyAmount,
+		p.MonthlyAmount, p.DailyCount, p.MonthlyCount,
+	))
+}
+
+func (r *limitPoliciesRepo) Update(ctx context.Context, p models.LimitPolicy) (models.LimitPolicy, error) {
+	return scanLimitPolicy(r.pool.QueryRow(ctx,
+		`UPDATE limit_policies
+		    SET max_amount=$2, daily_amount=$3, monthly_amount=$4, daily_count=$5, monthly_count=$6,
+		        updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+limitPolicyColumns,
+		p.ID, p.MaxAmount, p.DailyAmount, p.MonthlyAmount, p.DailyCount, p.MonthlyCount,
+	))
+}
+
+func (r *limitPoliciesRepo) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM limit_policies WHERE id=$1`, id)
+	if err == nil && tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+func (r *limitPoliciesRepo) Matching(ctx context.Context, role, tier, userID, typ, currency string) ([]models.LimitPolicy, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+limitPolicyColumns+`
+		   FROM limit_policies
+		  WHERE currency = $5
+		    AND txn_type IN ($4, '*')
+		    AND ((scope = 'role' AND subject = $1)
+		      OR (scope = 'tier' AND subject = $2)
+		      OR (scope = 'user' AND subject = $3))`,
+		role, tier, userID, typ, currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanLimitPolicies(rows)
+}
+
+func (r *limitPoliciesRepo) Usage(ctx context.Context, userID string, typ models.TransactionType, currency string, now time.Time) (models.LimitUsage, error) {
+	return usage(ctx, r.pool, userID, typ, currency, now, `status NOT IN ('failed','rolled_back','rejected')`)
+}
+
+func (r *limitPoliciesRepo) UsageTx(ctx context.Context, pgtx pgx.Tx, userID string, typ models.TransactionType, currency, excludeID string, now time.Time) (models.LimitUsage, error) {
+	if _, err := pgtx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('limits:' || $1))`, userID); err != nil {
+		return models.LimitUsage{}, err
+	}
+	var exclude *string
+	if excludeID != "" {
+		exclude = &excludeID
+	}
+	return usage(ctx, pgtx, userID, typ, currency, now,
+		`status NOT IN ('failed','rolled_back','rejected','pending','awaiting_approval','held_for_review')
+		    AND id IS DISTINCT FROM $6::uuid`, exclude)
+}
+
+// usage sums userID's transactions of typ in currency that pass filter; extra
+// are filter's arguments from $6 on.
+func usage(ctx context.Context, q querier, userID string, typ models.TransactionType, currency string, now time.Time,
+	filter string, extra ...any) (models.LimitUsage, error) {
+	// money coming in is attributed to the recipient, everything else to the sender
+	col := "from_user_id"
+	if typ == models.TxnCredit {
+		col = "to_user_id"
+	}
+	args := append([]any{userID, typ, currency, now.Add(-models.LimitDay), now.Add(-models.LimitMonth)}, extra...)
+	var u models.LimitUsage
+	// a hold with a payee is a transfer waiting to be captured
+	err := q.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount) FILTER (WHERE created_at > $4), 0),
+		        COUNT(*) FILTER (WHERE created_at > $4),
+		        MIN(created_at) FILTER (WHERE created_at > $4),
+		        COALESCE(SUM(amount), 0),
+		        COUNT(*),
+		        MIN(created_at)
+		   FROM transactions
+		  WHERE `+col+` = $1 AND currency = $3
+		    AND (type = $2 OR ($2 = 'transfer' AND type = 'authorization' AND to_user_id IS NOT NULL))
+		    AND `+filter+`
+		    AND created_at > $5`,
+		args...,
+	).Scan(&u.DailyAmount, &u.DailyCount, &u.DailyOldest, &u.MonthlyAmount, &u.MonthlyCount, &u.MonthlyOldest)
+	return u, err
+}
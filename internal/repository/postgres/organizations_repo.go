@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type organizationsRepo struct{ pool *pgxpool.Pool }
+
+const (
+	orgColumns    = `id, name, created_by, created_at`
+	memberColumns = `org_id, user_id, role, added_by, created_at, updated_at`
+)
+
+func scanOrg(row pgx.Row) (models.Organization, error) {
+	var o models.Organization
+	err := row.Scan(&o.ID, &o.Name, &o.CreatedBy, &o.CreatedAt)
+	return o, err
+}
+
+func scanMember(row pgx.Row) (models.OrgMember, error) {
+	var m models.OrgMember
+	err := row.Scan(&m.OrgID, &m.UserID, &m.Role, &m.AddedBy, &m.CreatedAt, &m.UpdatedAt)
+	return m, err
+}
+
+// Create opens the organization with its account row and ownerID as the first owner.
+func (r *organizationsRepo) Create(ctx context.Context, name, ownerID string) (models.Organization, error) {
+	id := uuid.NewString()
+	var o models.Organization
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// '!' is never a valid bcrypt hash, so the account row can't sign in
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO users(id, username, email, password_hash, role)
+			 VALUES($1, 'org-' || $1, $1 || '@organizations.invalid', '!', $2)`,
+			id, models.RoleOrganization,
+		); err != nil {
+			return err
+		}
+		var err error
+		if o, err = scanOrg(tx.QueryRow(ctx,
+			`INSERT INTO organizations(id, name, created_by) VALUES($1, $2, $3)
+			 RETURNING `+orgColumns,
+			id, name, ownerID,
+		)); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO organization_members(org_id, user_id, role, added_by) VALUES($1, $2, 'owner', $2)`,
+			id, ownerID,
+		)
+		return err
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "organizations_name_key" {
+		return models.Organization{}, repository.ErrDuplicateOrgName
+	}
+	if err != nil {
+		return models.Organization{}, err
+	}
+	o.Role = models.OrgOwner
+	return o, nil
+}
+
+func (r *organizationsRepo) GetByID(ctx context.Context, id string) (models.Organization, error) {
+	return scanOrg(r.pool.QueryRow(ctx, `SELECT `+orgColumns+` FROM organizations WHERE id = $1`, id))
+}
+
+func (r *organizationsRepo) ListByMember(ctx context.Context, userID string) ([]models.Organization, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+prefixColumns("o", orgColumns)+`, m.role
+		   FROM organizations o
+		   JOIN organization_members m ON m.org_id = o.id
+		  WHERE m.user_id = $1
+		  ORDER BY o.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Organization
+	for rows.Next() {
+		var o models.Organization
+		if err := rows.Scan(&o.ID, &o.Name, &o.CreatedBy, &o.CreatedAt, &o.Role); err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *organizationsRepo) Member(ctx context.Context, orgID, userID string) (models.OrgMember, error) {
+	return scanMember(r.pool.QueryRow(ctx,
+		`SELECT `+memberColumns+` FROM organization_members WHERE org_id = $1 AND user_id = $2`,
+		orgID, userID,
+	))
+}
+
+func (r *organizationsRepo) Members(ctx context.Context, orgID string) ([]models.OrgMember, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+memberColumns+`
+		   FROM organization_members
+		  WHERE org_id = $1
+		  ORDER BY created_at, user_id`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.OrgMember
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// SetMember adds userID or changes their role; before is "" for a new member.
+// Member changes of one organization are serialized on its row, so two owners
+// can't demote each other at the same time.
+func (r *organizationsRepo) SetMember(ctx context.Context, orgID, userID string, role models.OrgRole, addedBy string) (before models.OrgRole, m models.OrgMember, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM organizations WHERE id = $1 FOR UPDATE`, orgID); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx,
+			`SELECT role FROM organization_members WHERE org_id = $1 AND user_id = $2`, orgID, userID,
+		).Scan(&before)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if m, err = scanMember(tx.QueryRow(ctx,
+			`INSERT INTO organization_members(org_id, user_id, role, added_by) VALUES($1, $2, $3, $4)
+			 ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role, updated_at = now()
+			 RETURNING `+memberColumns,
+			orgID, userID, role, addedBy,
+		)); err != nil {
+			return err
+		}
+		return r.ensureOwner(ctx, tx, orgID)
+	})
+	return before, m, err
+}
+
+// RemoveMember takes userID out of the organization and returns the membership it had.
+func (r *organizationsRepo) RemoveMember(ctx context.Context, orgID, userID string) (models.OrgMember, error) {
+	var m models.OrgMember
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM organizations WHERE id = $1 FOR UPDATE`, orgID); err != nil {
+			return err
+		}
+		var err error
+		if m, err = scanMember(tx.QueryRow(ctx,
+			`DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2 RETURNING `+memberColumns,
+			orgID, userID,
+		)); err != nil {
+			return err
+		}
+		return r.ensureOwner(ctx, tx, orgID)
+	})
+	return m, err
+}
+
+func (r *organizationsRepo) ensureOwner(ctx context.Context, tx pgx.Tx, orgID string) error {
+	var owners int
+	if err := tx.QueryRow(ctx,
+		`SELECT count(*) FROM organization_members WHERE org_id = $1 AND role = 'owner'`, orgID,
+	).Scan(&owners); err != nil {
+		return err
+	}
+	if owners == 0 {
+		return repository.ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type paymentRequestsRepo struct{ pool *pgxpool.Pool }
+
+// a pending request past its expiry reads as expired without a sweeper
+const paymentRequestColumns = `id, requester_id, payer_id, amount, currency, note,
+       CASE WHEN status = 'pending' AND expires_at <= now() THEN 'expired' ELSE status END,
+       expires_at, transaction_id, pending_transaction_id, created_at, updated_at`
+
+// pendingRequest matches requests that can still be paid, declined or cancelled.
+const pendingRequest = `status = 'pending' AND (expires_at IS NULL OR expires_at > now())`
+
+// paymentUnderway matches requests whose pending transfer has not failed or been
+// rejected: it may still move money, or already has.
+const paymentUnderway = `EXISTS (SELECT 1 FROM transactions t
+		            WHERE t.id = payment_requests.pending_transaction_id
+		              AND t.status NOT IN ('failed','rolled_back','rejected'))`
+
+func scanPaymentRequest(row pgx.Row) (models.PaymentRequest, error) {
+	var p models.PaymentRequest
+	err := row.Scan(&p.ID, &p.RequesterID, &p.PayerID, &p.Amount, &p.Currency, &p.Note,
+		&p.Status, &p.ExpiresAt, &p.TransactionID, &p.PendingTransactionID, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (r *paymentRequestsRepo) Create(ctx context.Context, p models.PaymentRequest) (models.PaymentRequest, error) {
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	return scanPaymentRequest(r.pool.QueryRow(ctx,
+		`INSERT INTO payment_requests(id, requester_id, payer_id, amount, currency, note, expires_at)
+		 VALUES($1,$2,$3,$4,$5,$6,$7)
+		 RETURNING `+paymentRequestColumns,
+		p.ID, p.RequesterID, p.PayerID, p.Amount, p.Currency, p.Note, p.ExpiresAt,
+	))
+}
+
+func (r *paymentRequestsRepo) GetByID(ctx context.Context, id string) (models.PaymentRequest, error) {
+	return scanPaymentRequest(r.pool.QueryRow(ctx,
+		`SELECT `+paymentRequestColumns+` FROM payment_requests WHERE id=$1`, id))
+}
+
+// ListByUser lists the requests userID is on the given side of, or both sides
+// when direction is empty; status filters on the status as it reads.
+func (r *paymentRequestsRepo) ListByUser(ctx context.Context, userID, direction string, status models.PaymentRequestStatus, limit, offset int) ([]models.PaymentRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM (
+		    SELECT `+paymentRequestColumns+`
+		      FROM payment_requests
+		     WHERE ($2 <> 'outgoing' AND payer_id = $1) OR ($2 <> 'incoming' AND requester_id = $1)) p
+		  WHERE ($3 = '' OR p.status = $3)
+		  ORDER BY p.created_at DESC
+		  LIMIT $4 OFFSET $5`,
+		userID, direction, status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.PaymentRequest{}
+	for rows.Next() {
+		p, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Close moves a still pending request to status; pgx.ErrNoRows if it wasn't
+// pending or its payment is underway.
+func (r *paymentRequestsRepo) Close(ctx context.Context, id string, status models.PaymentRequestStatus) (models.PaymentRequest, error) {
+	return scanPaymentRequest(r.pool.QueryRow(ctx,
+		`UPDATE payment_requests SET status=$2, updated_at=now()
+		  WHERE id=$1 AND `+pendingRequest+` AND NOT `+paymentUnderway+`
+		  RETURNING `+paymentRequestColumns,
+		id, status,
+	))
+}
+
+// SetPending records the transfer an accept is waiting on. Like MarkPaid it
+// applies whatever the current status, so a decision on the transfer always
+// finds the request.
+func (r *paymentRequestsRepo) SetPending(ctx context.Context, id, txID string) (models.PaymentRequest, error) {
+	return scanPaymentRequest(r.pool.QueryRow(ctx,
+		`UPDATE payment_requests SET pending_transaction_id=$2, updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+paymentRequestColumns,
+		id, txID,
+	))
+}
+
+// SettlePendingTx applies a decision on the pending transfer txID: completed
+// pays the request whatever its status, rejected closes it if still pending.
+func (r *paymentRequestsRepo) SettlePendingTx(ctx context.Context, pgtx pgx.Tx, txID string, status models.TransactionStatus) error {
+	var err error
+	switch status {
+	case models.TxnCompleted:
+		_, err = pgtx.Exec(ctx,
+			`UPDATE payment_requests SET status='paid', transaction_id=$1, updated_at=now()
+			  WHERE pending_transaction_id=$1`, txID)
+	case models.TxnRejected:
+		_, err = pgtx.Exec(ctx,
+			`UPDATE payment_requests SET status='rejected', updated_at=now()
+			  WHERE pending_transaction_id=$1 AND status='pending'`, txID)
+	}
+	return err
+}
+
+// MarkPaid links the paying transfer. It applies whatever the current status:
+// once the money has moved the request is paid.
+func (r *paymentRequestsRepo) MarkPaid(ctx context.Context, id, txID string) (models.PaymentRequest, error) {
+	return scanPaymentRequest(r.pool.QueryRow(ctx,
+		`UPDATE payment_requests SET status='paid', transaction_id=$2, updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+paymentRequestColumns,
+		id, txID,
+	))
+}
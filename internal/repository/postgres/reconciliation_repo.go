@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reconciliationRepo struct{ pool *pgxpool.Pool }
+
+const reconRunColumns = `id, status, adjust, balances_checked, drifts_found, adjustments_made, error, started_at, finished_at`
+
+func scanReconRun(row pgx.Row) (models.ReconciliationRun, error) {
+	var r models.ReconciliationRun
+	err := row.Scan(&r.ID, &r.Status, &r.Adjust, &r.BalancesChecked, &r.DriftsFound, &r.AdjustmentsMade,
+		&r.Error, &r.StartedAt, &r.FinishedAt)
+	return r, err
+}
+
+const driftColumns = `id, run_id, user_id, currency, expected, actual, drift, adjustment_transaction_id, adjustment_error, created_at`
+
+func scanDrift(row pgx.Row) (models.BalanceDrift, error) {
+	var d models.BalanceDrift
+	err := row.Scan(&d.ID, &d.RunID, &d.UserID, &d.Currency, &d.Expected, &d.Actual, &d.Drift,
+		&d.AdjustmentTransactionID, &d.AdjustmentError, &d.CreatedAt)
+	return d, err
+}
+
+func (r *reconciliationRepo) StartRun(ctx context.Context, adjust bool) (models.ReconciliationRun, error) {
+	// a run that has been "running" for an hour died with its process
+	if _, err := r.pool.Exec(ctx,
+		`UPDATE reconciliation_runs
+		    SET status='failed', error='abandoned', finished_at=now()
+		  WHERE status='running' AND started_at < now() - interval '1 hour'`); err != nil {
+		return models.ReconciliationRun{}, err
+	}
+	run, err := scanReconRun(r.pool.QueryRow(ctx,
+		`INSERT INTO reconciliation_runs(status, adjust) VALUES('running', $1)
+		 RETURNING `+reconRunColumns,
+		adjust,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return models.ReconciliationRun{}, repository.ErrRunInProgress
+	}
+	return run, err
+}
+
+func (r *reconciliationRepo) FinishRun(ctx context.Context, run models.ReconciliationRun) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE reconciliation_runs
+		    SET status=$2, balances_checked=$3, drifts_found=$4, adjustments_made=$5, error=$6, finished_at=now()
+		  WHERE id=$1`,
+		run.ID, run.Status, run.BalancesChecked, run.DriftsFound, run.AdjustmentsMade, run.Error,
+	)
+	return err
+}
+
+// foldSQL is the expected balance per (user, currency): what settled, money-moving
+// transactions brought in minus what they took out. Adjustments are corrections of
+// the balance, not history, so they are left out. A user's wallets are compared
+// together, so moves between them cancel out.
+const foldSQL = `
+WITH moves AS (
+  SELECT to_user_id AS user_id, currency, amount AS delta
+    FROM transactions
+   WHERE to_user_id IS NOT NULL
+     AND status IN ('completed','reversed','refunded')
+     AND type NOT IN ('authorization','void','adjustment')
+  UNION ALL
+  SELECT from_user_id, currency, -amount
+    FROM transactions
+   WHERE from_user_id IS NOT NULL
+     AND status IN ('completed','reversed','refunded')
+     AND type NOT IN ('authorization','void','adjustment')
+), folded AS (
+  SELECT user_id, currency, SUM(delta)::bigint AS expected
+    FROM moves
+   GROUP BY user_id, currency
+)
+SELECT COALESCE(b.user_id, f.user_id), COALESCE(b.currency, f.currency),
+       COALESCE(f.expected, 0), COALESCE(b.amount, 0)
+  FROM (SELECT user_id, currency, SUM(amount)::bigint AS amount
+          FROM balances
+         GROUP BY user_id, currency) b
+  FULL OUTER JOIN folded f ON f.user_id = b.user_id AND f.currency = b.currency`
+
+func (r *reconciliationRepo) Drifts(ctx context.Context) ([]models.BalanceDrift, int, error) {
+	// one snapshot: money moves and status changes commit together, so a
+	// consistent read never shows drift that isn't there
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, foldSQL)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []models.BalanceDrift
+	checked := 0
+	for rows.Next() {
+		var d models.BalanceDrift
+		if err := rows.Scan(&d.UserID, &d.Currency, &d.Expected, &d.Actual); err != nil {
+			return nil, 0, err
+		}
+		checked++
+		if d.Drift = d.Actual - d.Expected; d.Drift != 0 {
+			out = append(out, d)
+		}
+	}
+	return out, checked, rows.Err()
+}
+
+func (r *reconciliationRepo) AddDrift(ctx context.Context, d models.BalanceDrift) (models.BalanceDrift, error) {
+	return scanDrift(r.pool.QueryRow(ctx,
+		`INSERT INTO reconciliation_drifts(run_id, user_id, currency, expected, actual, drift,
+		                                   adjustment_transaction_id, adjustment_error)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+		 RETURNING `+driftColumns,
+		d.RunID, d.UserID, d.Currency, d.Expected, d.Actual, d.Drift, d.AdjustmentTransactionID, d.AdjustmentError,
+	))
+}
+
+func (r *reconciliationRepo) ListRuns(ctx context.Context, limit, offset int) ([]models.ReconciliationRun, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+reconRunColumns+`
+		   FROM reconciliation_runs
+		  ORDER BY started_at DESC
+		  LIMIT $1 OFFSET $2`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.ReconciliationRun
+	for rows.Next() {
+		run, err := scanReconRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
+
+func (r *reconciliationRepo) GetRun(ctx context.Context, id string) (models.ReconciliationRun, error) {
+	return scanReconRun(r.pool.QueryRow(ctx,
+		`SELECT `+reconRunColumns+` FROM reconciliation_runs WHERE id=$1`, id))
+}
+
+func (r *reconciliationRepo) LatestRun(ctx context.Context) (models.ReconciliationRun, error) {
+	return scanReconRun(r.pool.QueryRow(ctx,
+		`SELECT `+reconRunColumns+`
+		   FROM reconciliation_runs
+		  WHERE status <> 'running'
+		  ORDER BY started_at DESC
+		  LIMIT 1`))
+}
+
+func (r *reconciliationRepo) ListDrifts(ctx context.Context, runID string, limit, offset int) ([]models.BalanceDrift, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+driftColumns+`
+		   FROM reconciliation_drifts
+		  WHERE run_id=$1
+		  ORDER BY id
+		  LIMIT $2 OFFSET $3`,
+		runID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.BalanceDrift
+	for rows.Next() {
+		d, err := scanDrift(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repositories groups every postgres-backed repository behind its interface.
+type Repositories struct {
+	Users        repository.Users
+	Balances     repository.Balances
+	Transactions repository.Transactions
+	AuditLogs    repository.AuditLogs
+	Ledger       repository.Ledger
+	Jobs         repository.Jobs
+	Idempotency  repository.IdempotencyKeys
+	Holds        repository.Holds
+	Schedules    repository.Schedules
+	FXRates      repository.FXRates
+	Conversions  repository.FXConversions
+	Batches      repository.Batches
+	Limits       repository.LimitPolicies
+	Recon        repository.Reconciliation
+	Statements   repository.Statements
+	Fees         repository.FeeRules
+	Savings      repository.Savings
+	Requests     repository.PaymentRequests
+	RiskSignals  repository.RiskSignals
+	RiskCases    repository.RiskCases
+	Wallets      repository.Wallets
+	Orgs         repository.Organizations
+	Disputes     repository.Disputes
+}
+
+func NewRepositories(pool *pgxpool.Pool) *Repositories {
+	bal := &balancesRepo{pool: pool}
+	risk := &riskRepo{pool: pool}
+	return &Repositories{
+		Users:        NewUsers(pool),
+		Balances:     bal,
+		Transactions: &transactionsRepo{pool: pool},
+		AuditLogs:    &auditLogsRepo{pool: pool},
+		Ledger:       &ledgerRepo{pool: pool, bal: bal},
+		Jobs:         &jobsRepo{pool: pool},
+		Idempotency:  &idempotencyKeysRepo{pool: pool},
+		Holds:        &holdsRepo{pool: pool},
+		Schedules:    &schedulesRepo{pool: pool},
+		FXRates:      &fxRatesRepo{pool: pool},
+		Conversions:  &fxConversionsRepo{pool: pool},
+		Batches:      &batchesRepo{pool: pool},
+		Limits:       &limitPoliciesRepo{pool: pool},
+		Recon:        &reconciliationRepo{pool: pool},
+		Statements:   &statementsRepo{pool: pool},
+		Fees:         &feeRulesRepo{pool: pool},
+		Savings:      &savingsRepo{pool: pool},
+		Requests:     &paymentRequestsRepo{pool: pool},
+		RiskSignals:  risk,
+		RiskCases:    risk,
+		Wallets:      &walletsRepo{pool: pool},
+		Orgs:         &organizationsRepo{pool: pool},
+		Disputes:     &disputesRepo{pool: pool},
+	}
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// riskRepo serves both the screening signals and the reviewer queue.
+type riskRepo struct{ pool *pgxpool.Pool }
+
+//  signals
+
+func (r *riskRepo) KnownRecipient(ctx context.Context, fromID, toID string) (bool, error) {
+	var known bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (
+		    SELECT 1 FROM transactions
+		     WHERE from_user_id = $1 AND to_user_id = $2 AND type = 'transfer'
+		       AND status IN ('completed','reversed','refunded'))`,
+		fromID, toID,
+	).Scan(&known)
+	return known, err
+}
+
+// RecipientsSince counts the distinct users fromID sent transfers to since, toID included.
+func (r *riskRepo) RecipientsSince(ctx context.Context, fromID, toID string, since time.Time) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx,
+		`SELECT count(*) FROM (
+		    SELECT to_user_id FROM transactions
+		     WHERE from_user_id = $1 AND type = 'transfer' AND created_at >= $3
+		       AND status NOT IN ('failed','rolled_back','rejected')
+		    UNION
+		    SELECT $2::uuid) r`,
+		fromID, toID, since,
+	).Scan(&n)
+	return n, err
+}
+
+// OutgoingStats returns how many debits and transfers userID settled in currency
+// since, and their average amount.
+func (r *riskRepo) OutgoingStats(ctx context.Context, userID, currency string, since time.Time) (int, int64, error) {
+	var n int
+	var avg int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT count(*), COALESCE(round(avg(amount)), 0)::bigint
+		   FROM transactions
+		  WHERE from_user_id = $1 AND currency = $2 AND type IN ('debit','transfer')
+		    AND status IN ('completed','reversed','refunded') AND created_at >= $3`,
+		userID, currency, since,
+	).Scan(&n, &avg)
+	return n, avg, err
+}
+
+// RoundAmountsSince counts userID's debits and transfers in currency since that
+// were a multiple of unit.
+func (r *riskRepo) RoundAmountsSince(ctx context.Context, userID, currency string, unit int64, since time.Time) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx,
+		`SELECT count(*)
+		   FROM transactions
+		  WHERE from_user_id = $1 AND currency = $2 AND type IN ('debit','transfer') AND amount % $3 = 0
+		    AND status NOT IN ('failed','rolled_back','rejected') AND created_at >= $4`,
+		userID, currency, unit, since,
+	).Scan(&n)
+	return n, err
+}
+
+//  cases
+
+var riskCaseColumns = `c.id, c.decision, c.hits, c.status, c.reviewer_id, c.note, c.created_at, c.decided_at, ` +
+	prefixColumns("t", txnColumns)
+
+func scanRiskCase(row pgx.Row) (models.RiskCase, error) {
+	var c models.RiskCase
+	dest := append([]any{&c.ID, &c.Decision, &c.Hits, &c.Status, &c.ReviewerID, &c.Note, &c.CreatedAt, &c.DecidedAt},
+		txnDest(&c.Transaction)...)
+	err := row.Scan(dest...)
+	return c, err
+}
+
+func (r *riskRepo) CreateCaseTx(ctx context.Context, tx pgx.Tx, c models.RiskCase) (models.RiskCase, error) {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	hits := c.Hits
+	if hits == nil {
+		hits = []models.RiskHit{}
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO risk_cases(id, transaction_id, decision, hits) VALUES($1,$2,$3,$4)`,
+		c.ID, c.Transaction.ID, c.Decision, hits,
+	)
+	c.Status = models.CaseOpen
+	return c, err
+}
+
+func (r *riskRepo) GetCase(ctx context.Context, id string) (models.RiskCase, error) {
+	return scanRiskCase(r.pool.QueryRow(ctx,
+		`SELECT `+riskCaseColumns+`
+		   FROM risk_cases c JOIN transactions t ON t.id = c.transaction_id
+		  WHERE c.id = $1`, id))
+}
+
+func (r *riskRepo) ListCases(ctx context.Context, status models.RiskCaseStatus, limit, offset int) ([]models.RiskCase, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+riskCaseColumns+`
+		   FROM risk_cases c JOIN transactions t ON t.id = c.transaction_id
+		  WHERE ($1 = '' OR c.status = $1)
+		  ORDER BY c.created_at, c.id
+		  LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.RiskCase{}
+	for rows.Next() {
+		c, err := scanRiskCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// DecideCaseTx closes an open case; false if it was already decided.
+func (r *riskRepo) DecideCaseTx(ctx context.Context, tx pgx.Tx, id string, status models.RiskCaseStatus, reviewerID string, note *string) (bool, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE risk_cases SET status=$2, reviewer_id=$3, note=$4, decided_at=now()
+		  WHERE id=$1 AND status='open'`,
+		id, status, reviewerID, note,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type savingsRepo struct{ pool *pgxpool.Pool }
+
+const (
+	interestTierColumns    = `currency, min_balance, apy_bps, updated_at`
+	interestAccrualColumns = `user_id, wallet_id, currency, day, balance, amount, carry, payout_id, created_at`
+)
+
+func scanInterestTiers(rows pgx.Rows) ([]models.InterestTier, error) {
+	defer rows.Close()
+	out := []models.InterestTier{}
+	for rows.Next() {
+		var t models.InterestTier
+		if err := rows.Scan(&t.Currency, &t.MinBalance, &t.APYBPS, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *savingsRepo) ListRates(ctx context.Context) ([]models.InterestTier, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+interestTierColumns+` FROM interest_rates ORDER BY currency, min_balance`)
+	if err != nil {
+		return nil, err
+	}
+	return scanInterestTiers(rows)
+}
+
+func (r *savingsRepo) RatesFor(ctx context.Context, currency string) ([]models.InterestTier, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+interestTierColumns+` FROM interest_rates WHERE currency=$1 ORDER BY min_balance`, currency)
+	if err != nil {
+		return nil, err
+	}
+	return scanInterestTiers(rows)
+}
+
+func (r *savingsRepo) ReplaceRates(ctx context.Context, currency string, tiers []models.InterestTier) ([]models.InterestTier, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM interest_rates WHERE currency=$1`, currency); err != nil {
+		return nil, err
+	}
+	for _, t := range tiers {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO interest_rates(currency, min_balance, apy_bps) VALUES($1,$2,$3)`,
+			currency, t.MinBalance, t.APYBPS,
+		); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := tx.Query(ctx,
+		`SELECT `+interestTierColumns+` FROM interest_rates WHERE currency=$1 ORDER BY min_balance`, currency)
+	if err != nil {
+		return nil, err
+	}
+	out, err := scanInterestTiers(rows)
+	if err != nil {
+		return nil, err
+	}
+	return out, tx.Commit(ctx)
+}
+
+// Savers finds the wallet balances that ended day above zero in a currency with rates
+// and have no accrual for day yet, each with the interest accrued on it earlier but
+// not in the balance by the end of day, and the carry of its previous accrual.
+func (r *savingsRepo) Savers(ctx context.Context, day time.Time) ([]models.SaverPosition, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.user_id, b.wallet_id, b.currency, h.amount, COALESCE(u.amount, 0), COALESCE(c.carry, 0)
+		   FROM balances b
+		   CROSS JOIN LATERAL (
+		        SELECT amount FROM balance_history
+		         WHERE wallet_id = b.wallet_id AND currency = b.currency AND created_at < $2
+		         ORDER BY created_at DESC, id DESC LIMIT 1) h
+		   CROSS JOIN LATERAL (
+		        SELECT SUM(a.amount) AS amount FROM interest_accruals a
+		         WHERE a.wallet_id = b.wallet_id AND a.currency = b.currency AND a.day < $1::date
+		           AND (a.payout_id IS NULL OR NOT EXISTS (
+		                SELECT 1 FROM balance_history ph
+		                 WHERE ph.transaction_id = a.payout_id AND ph.wallet_id = b.wallet_id
+		                   AND ph.created_at < $2))) u
+		   LEFT JOIN LATERAL (
+		        SELECT carry FROM interest_accruals
+		         WHERE wallet_id = b.wallet_id AND currency = b.currency AND day < $1::date
+		         ORDER BY day DESC LIMIT 1) c ON true
+		  WHERE h.amount > 0
+		    AND EXISTS (SELECT 1 FROM interest_rates ir WHERE ir.currency = b.currency)
+		    AND NOT EXISTS (
+		        SELECT 1 FROM interest_accruals a
+		         WHERE a.wallet_id = b.wallet_id AND a.currency = b.currency AND a.day = $1::date)`,
+		day, day.AddDate(0, 0, 1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.SaverPosition
+	for rows.Next() {
+		var p models.SaverPosition
+		if err := rows.Scan(&p.UserID, &p.WalletID, &p.Currency, &p.Balance, &p.Accrued, &p.Carry); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Accrue stores a day's accrual; false if the day was already accrued.
+func (r *savingsRepo) Accrue(ctx context.Context, a models.InterestAccrual) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO interest_accruals(user_id, wallet_id, currency, day, balance, amount, carry)
+		 VALUES($1,$2,$3,$4::date,$5,$6,$7)
+		 ON CONFLICT (wallet_id, currency, day) DO NOTHING`,
+		a.UserID, a.WalletID, a.Currency, a.Day, a.Balance, a.Amount, a.Carry,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *savingsRepo) Due(ctx context.Context, before time.Time) ([]models.InterestDue, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT user_id, wallet_id, currency, SUM(amount)
+		   FROM interest_accruals
+		  WHERE payout_id IS NULL AND day < $1::date
+		  GROUP BY user_id, wallet_id, currency
+		 HAVING SUM(amount) > 0`,
+		before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.InterestDue
+	for rows.Next() {
+		var d models.InterestDue
+		if err := rows.Scan(&d.UserID, &d.WalletID, &d.Currency, &d.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ClaimTx marks the unpaid accruals before a day as paid by payoutID and returns
+// their total. Rows claimed by a concurrent payout are skipped once it commits.
+func (r *savingsRepo) ClaimTx(ctx context.Context, tx pgx.Tx, walletID, currency string, before time.Time, payoutID string) (int64, error) {
+	var total int64
+	err := tx.QueryRow(ctx,
+		`WITH c AS (
+		    UPDATE interest_accruals SET payout_id = $4
+		     WHERE wallet_id = $1 AND currency = $2 AND day < $3::date AND payout_id IS NULL
+		     RETURNING amount)
+		 SELECT COALESCE(SUM(amount), 0) FROM c`,
+		walletID, currency, before, payoutID,
+	).Scan(&total)
+	return total, err
+}
+
+func (r *savingsRepo) ListAccruals(ctx context.Context, userID, currency string, limit, offset int) ([]models.InterestAccrual, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+interestAccrualColumns+`
+		   FROM interest_accruals
+		  WHERE user_id = $1 AND ($2 = '' OR currency = $2)
+		  ORDER BY day DESC, currency, wallet_id
+		  LIMIT $3 OFFSET $4`,
+		userID, currency, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.InterestAccrual{}
+	for rows.Next() {
+		var a models.InterestAccrual
+		if err := rows.Scan(&a.UserID, &a.WalletID, &a.Currency, &a.Day, &a.Balance, &a.Amount, &a.Carry, &a.PayoutID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
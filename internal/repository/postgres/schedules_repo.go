@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type schedulesRepo struct{ pool *pgxpool.Pool }
+
+const scheduleColumns = `id, user_id, to_user_id, amount, currency, frequency, cron_expr, start_at, end_at,
+       next_run_at, next_attempt_at, attempt, max_retries, retry_interval, status, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (models.Schedule, error) {
+	var s models.Schedule
+	err := row.Scan(&s.ID, &s.UserID, &s.ToUserID, &s.Amount, &s.Currency, &s.Frequency, &s.CronExpr, &s.StartAt, &s.EndAt,
+		&s.NextRunAt, &s.NextAttemptAt, &s.Attempt, &s.MaxRetries, &s.RetryInterval, &s.Status, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func scanSchedules(rows pgx.Rows) ([]models.Schedule, error) {
+	defer rows.Close()
+	var out []models.Schedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *schedulesRepo) Create(s models.Schedule) (models.Schedule, error) {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return scanSchedule(r.pool.QueryRow(context.Background(),
+		`INSERT INTO schedules(id, user_id, to_user_id, amount, currency, frequency, cron_expr, start_at, end_at,
+		                       next_run_at, next_attempt_at, max_retries, retry_interval, status)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$10,$11,$12,$13)
+		 RETURNING `+scheduleColumns,
+		s.ID, s.UserID, s.ToUserID, s.Amount, s.Currency, s.Frequency, s.CronExpr, s.StartAt, s.EndAt,
+		s.NextRunAt, s.MaxRetries, s.RetryInterval, s.Status,
+	))
+}
+
+func (r *schedulesRepo) GetByID(id string) (models.Schedule, error) {
+	return scanSchedule(r.pool.QueryRow(context.Background(),
+		`SELECT `+scheduleColumns+` FROM schedules WHERE id=$1`, id))
+}
+
+func (r *schedulesRepo) ListByUser(userID string, limit, offset int) ([]models.Schedule, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+scheduleColumns+`
+		   FROM schedules
+		  WHERE user_id=$1
+		  ORDER BY created_at DESC
+		  LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
+
+// Save persists the mutable fields and releases the scheduler lease.
+func (r *schedulesRepo) Save(ctx context.Context, s models.Schedule) (models.Schedule, error) {
+	return scanSchedule(r.pool.QueryRow(ctx,
+		`UPDATE schedules
+		    SET amount=$2, end_at=$3, next_run_at=$4, next_attempt_at=$5, attempt=$6, status=$7,
+		        locked_until=NULL, updated_at=now()
+		  WHERE id=$1
+		  RETURNING `+scheduleColumns,
+		s.ID, s.Amount, s.EndAt, s.NextRunAt, s.NextAttemptAt, s.Attempt, s.Status,
+	))
+}
+
+// ClaimDue leases up to limit due schedules. Other replicas skip them until the lease runs out.
+func (r *schedulesRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Schedule, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE schedules
+		    SET locked_until = now() + make_interval(secs => $2)
+		  WHERE id IN (
+		        SELECT id FROM schedules
+		         WHERE status = 'active'
+		           AND next_attempt_at <= now()
+		           AND (locked_until IS NULL OR locked_until < now())
+		         ORDER BY next_attempt_at
+		         LIMIT $1
+		         FOR UPDATE SKIP LOCKED)
+		  RETURNING `+scheduleColumns,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
+
+func (r *schedulesRepo) AddRun(ctx context.Context, run models.ScheduleRun) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO schedule_runs(schedule_id, occurrence_at, attempt, status, transaction_id, error)
+		 VALUES($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (schedule_id, occurrence_at, attempt) DO NOTHING`,
+		run.ScheduleID, run.OccurrenceAt, run.Attempt, run.Status, run.TransactionID, run.Error,
+	)
+	return err
+}
+
+func (r *schedulesRepo) ListRuns(scheduleID string, limit, offset int) ([]models.ScheduleRun, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT id, schedule_id, occurrence_at, attempt, status, transaction_id, error, created_at
+		   FROM schedule_runs
+		  WHERE schedule_id=$1
+		  ORDER BY occurrence_at DESC, attempt DESC
+		  LIMIT $2 OFFSET $3`,
+		scheduleID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.ScheduleRun
+	for rows.Next() {
+		var run models.ScheduleRun
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.OccurrenceAt, &run.Attempt, &run.Status,
+			&run.TransactionID, &run.Error, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
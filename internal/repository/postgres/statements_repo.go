@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+type statementsRepo struct{ pool *pgxpool.Pool }
+
+const statementColumns = `id, user_id, wallet_id, currency, period_start, period_end, opening_balance, closing_balance,
+	total_in, total_out, totals, transaction_count, generated_at`
+
+func scanStatement(row pgx.Row) (models.MonthlyStatement, error) {
+	var s models.MonthlyStatement
+	err := row.Scan(&s.ID, &s.UserID, &s.WalletID, &s.Currency, &s.PeriodStart, &s.PeriodEnd, &s.OpeningBalance, &s.ClosingBalance,
+		&s.TotalIn, &s.TotalOut, &s.Totals, &s.TransactionCount, &s.GeneratedAt)
+	return s, err
+}
+
+// LastClosed is the period_start of the most recent closed month; ok is false before the first close.
+func (r *statementsRepo) LastClosed(ctx context.Context) (start time.Time, ok bool, err error) {
+	var t *time.Time
+	if err := r.pool.QueryRow(ctx, `SELECT max(period_start) FROM statements`).Scan(&t); err != nil || t == nil {
+		return time.Time{}, false, err
+	}
+	return *t, true, nil
+}
+
+// FirstActivity is the time of the oldest balance change; ok is false if there is none.
+func (r *statementsRepo) FirstActivity(ctx context.Context) (at time.Time, ok bool, err error) {
+	var t *time.Time
+	if err := r.pool.QueryRow(ctx, `SELECT min(created_at) FROM balance_history`).Scan(&t); err != nil || t == nil {
+		return time.Time{}, false, err
+	}
+	return *t, true, nil
+}
+
+// CloseMonth writes the statement of [start, end) for every (wallet, currency) with
+// history before end. Already closed ones are kept as they are.
+func (r *statementsRepo) CloseMonth(ctx context.Context, start, end time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO statements (user_id, wallet_id, currency, period_start, period_end, opening_balance, closing_balance,
+		                         total_in, total_out, totals, transaction_count)
+		 SELECT b.user_id, b.wallet_id, b.currency, $1, $2,
+		        COALESCE(o.amount, 0), c.amount,
+		        COALESCE(t.total_in, 0), COALESCE(t.total_out, 0), COALESCE(t.totals, '{}'::jsonb), COALESCE(t.n, 0)
+		   FROM balances b
+		   CROSS JOIN LATERAL (
+		        SELECT amount FROM balance_history
+		         WHERE wallet_id = b.wallet_id AND currency = b.currency AND created_at < $2
+		         ORDER BY created_at DESC, id DESC LIMIT 1) c
+		   LEFT JOIN LATERAL (
+		        SELECT amount FROM balance_history
+		         WHERE wallet_id = b.wallet_id AND currency = b.currency AND created_at < $1
+		         ORDER BY created_at DESC, id DESC LIMIT 1) o ON true
+		   LEFT JOIN LATERAL (
+		        SELECT sum(x.n)::int AS n, sum(x.total_in) AS total_in, sum(x.total_out) AS total_out,
+		               jsonb_object_agg(x.type, jsonb_build_object('count', x.n, 'in', x.total_in, 'out', x.total_out)) AS totals
+		          FROM (SELECT t.type, count(*) AS n,
+		                       COALESCE(sum(h.delta) FILTER (WHERE h.delta > 0), 0) AS total_in,
+		                       COALESCE(-sum(h.delta) FILTER (WHERE h.delta < 0), 0) AS total_out
+		                  FROM balance_history h
+		                  JOIN transactions t ON t.id = h.transaction_id
+		                 WHERE h.wallet_id = b.wallet_id AND h.currency = b.currency
+		                   AND h.created_at >= $1 AND h.created_at < $2
+		                 GROUP BY t.type) x) t ON true
+		 ON CONFLICT (wallet_id, currency, period_start) DO NOTHING`,
+		start, end,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *statementsRepo) ListByUser(ctx context.Context, userID, currency string, limit, offset int) ([]models.MonthlyStatement, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+statementColumns+`
+		   FROM statements
+		  WHERE user_id = $1 AND ($2 = '' OR currency = $2)
+		  ORDER BY period_start DESC, currency, wallet_id
+		  LIMIT $3 OFFSET $4`,
+		userID, currency, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.MonthlyStatement
+	for rows.Next() {
+		s, err := scanStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -2,73 +2,301 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type transactionsRepo struct{ pool *pgxpool.Pool }
 
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const txnColumns = `id, from_user_id, to_user_id, amount, currency, type, status, created_at, idempotency_key, parent_id,
+	from_wallet_id, to_wallet_id, description, external_reference, metadata, created_by`
+
+// txnDest returns the scan destinations for txnColumns.
+func txnDest(tx *models.Transaction) []any {
+	return []any{&tx.ID, &tx.FromUserID, &tx.ToUserID, &tx.Amount, &tx.Currency, &tx.Type, &tx.Status, &tx.CreatedAt,
+		&tx.IdempotencyKey, &tx.ParentID, &tx.FromWalletID, &tx.ToWalletID, &tx.Description, &tx.ExternalReference, &tx.Metadata,
+		&tx.CreatedBy}
+}
+
+func scanTxn(row pgx.Row) (models.Transaction, error) {
+	var tx models.Transaction
+	err := row.Scan(txnDest(&tx)...)
+	return tx, err
+}
+
+func scanTxns(rows pgx.Rows) ([]models.Transaction, error) {
+	defer rows.Close()
+	var out []models.Transaction
+	for rows.Next() {
+		tx, err := scanTxn(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tx)
+	}
+	return out, rows.Err()
+}
 
 func (r *transactionsRepo) Create(tx models.Transaction) (models.Transaction, error) {
+	return r.create(context.Background(), r.pool, tx)
+}
+
+func (r *transactionsRepo) CreateTx(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) (models.Transaction, error) {
+	return r.create(ctx, pgtx, tx)
+}
+
+func (r *transactionsRepo) create(ctx context.Context, q querier, tx models.Transaction) (models.Transaction, error) {
 	if tx.ID == "" {
 		tx.ID = uuid.NewString()
 	}
-	const q = `
+	var metadata any
+	if tx.Metadata != nil {
+		metadata = tx.Metadata
+	}
+	created, err := scanTxn(q.QueryRow(ctx, `
 INSERT INTO transactions (
-  id, from_user_id, to_user_id, amount, type, status, idempotency_key
-) VALUES ($1,$2,$3,$4,$5,$6,$7)
+  id, from_user_id, to_user_id, amount, currency, type, status, idempotency_key, parent_id,
+  from_wallet_id, to_wallet_id, description, external_reference, metadata, created_by
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 ON CONFLICT (idempotency_key) DO UPDATE
 SET idempotency_key = EXCLUDED.idempotency_key  -- no-op update; mevcut satırı RETURNING ile alacağız
-RETURNING id, from_user_id, to_user_id, amount, type, status, created_at;
-`
-	err := r.pool.QueryRow(
-		context.Background(), q,
-		tx.ID, tx.FromUserID, tx.ToUserID, tx.Amount, tx.Type, tx.Status, tx.IdempotencyKey,
-	).Scan(&tx.ID, &tx.FromUserID, &tx.ToUserID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt)
-	return tx, err
+RETURNING `+txnColumns,
+		tx.ID, tx.FromUserID, tx.ToUserID, tx.Amount, tx.Currency, tx.Type, tx.Status, tx.IdempotencyKey, tx.ParentID,
+		tx.FromWalletID, tx.ToWalletID, tx.Description, tx.ExternalReference, metadata, tx.CreatedBy,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "ux_transactions_external_reference" {
+		return models.Transaction{}, repository.ErrDuplicateReference
+	}
+	return created, err
 }
 
+func (r *transactionsRepo) GetByID(id string) (models.Transaction, error) {
+	return scanTxn(r.pool.QueryRow(context.Background(),
+		`SELECT `+txnColumns+` FROM transactions WHERE id=$1`, id))
+}
 
+// GetForUpdateTx locks the row for the rest of tx.
+func (r *transactionsRepo) GetForUpdateTx(ctx context.Context, pgtx pgx.Tx, id string) (models.Transaction, error) {
+	return scanTxn(pgtx.QueryRow(ctx,
+		`SELECT `+txnColumns+` FROM transactions WHERE id=$1 FOR UPDATE`, id))
+}
 
-func (r *transactionsRepo) GetByID(id string) (models.Transaction, error) {
-	var tx models.Transaction
-	err := r.pool.QueryRow(
-		context.Background(),
-		`SELECT id, from_user_id, to_user_id, amount, type, status, created_at
-		   FROM transactions
-		  WHERE id=$1`,
-		id,
-	).Scan(&tx.ID, &tx.FromUserID, &tx.ToUserID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt)
-	return tx, err
+func (r *transactionsRepo) GetByIdempotencyKey(key string) (models.Transaction, error) {
+	return scanTxn(r.pool.QueryRow(context.Background(),
+		`SELECT `+txnColumns+` FROM transactions WHERE idempotency_key=$1`, key))
 }
 
-func (r *transactionsRepo) ListByUser(userID string, limit, offset int) ([]models.Transaction, error) {
-	rows, err := r.pool.Query(
-		context.Background(),
-		`SELECT id, from_user_id, to_user_id, amount, type, status, created_at
-		   FROM transactions
-		  WHERE from_user_id=$1 OR to_user_id=$1
-		  ORDER BY created_at DESC
-		  LIMIT $2 OFFSET $3`,
-		userID, limit, offset,
-	)
+// Search lists userID's transactions newest first. Each direction is read as its own
+// branch so both walk the (from_user_id|to_user_id, created_at DESC) indexes and stop
+// after limit rows; the union is merged and cut again.
+func (r *transactionsRepo) Search(ctx context.Context, userID string, f models.TransactionFilter, limit int) ([]models.Transaction, error) {
+	args := []any{userID, limit}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var common []string
+	if len(f.Types) > 0 {
+		types := make([]string, len(f.Types))
+		for i, t := range f.Types {
+			types[i] = string(t)
+		}
+		common = append(common, "type = ANY("+arg(types)+"::text[])")
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, st := range f.Statuses {
+			statuses[i] = string(st)
+		}
+		common = append(common, "status = ANY("+arg(statuses)+"::text[])")
+	}
+	if f.Currency != "" {
+		common = append(common, "currency = "+arg(f.Currency))
+	}
+	if f.Reference != "" {
+		common = append(common, "external_reference = "+arg(f.Reference))
+	}
+	if f.MinAmount != nil {
+		common = append(common, "amount >= "+arg(*f.MinAmount))
+	}
+	if f.MaxAmount != nil {
+		common = append(common, "amount <= "+arg(*f.MaxAmount))
+	}
+	if f.From != nil {
+		common = append(common, "created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		common = append(common, "created_at < "+arg(*f.To))
+	}
+	if f.After != nil {
+		// the created_at bound keeps the index range scan; id breaks ties
+		at, id := arg(f.After.CreatedAt), arg(f.After.ID)
+		common = append(common, fmt.Sprintf("created_at <= %s AND (created_at < %s OR id < %s::uuid)", at, at, id))
+	}
+	var cp string
+	if f.Counterparty != "" {
+		cp = arg(f.Counterparty)
+	}
+
+	branch := func(self, other string, extra ...string) string {
+		conds := append([]string{self + " = $1"}, extra...)
+		if cp != "" {
+			conds = append(conds, other+" = "+cp)
+		}
+		conds = append(conds, common...)
+		return `(SELECT ` + txnColumns + ` FROM transactions WHERE ` + strings.Join(conds, " AND ") +
+			` ORDER BY created_at DESC, id DESC LIMIT $2)`
+	}
+	out := branch("from_user_id", "to_user_id")
+	// a row with the user on both sides is already in the out branch
+	in := branch("to_user_id", "from_user_id", "from_user_id IS DISTINCT FROM $1")
+
+	var q string
+	switch f.Direction {
+	case models.DirectionOut:
+		q = out
+	case models.DirectionIn:
+		q = in
+	default:
+		q = `SELECT ` + txnColumns + ` FROM (` + out + ` UNION ALL ` + in + `) t
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT $2`
+	}
+	rows, err := r.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanTxns(rows)
+}
 
-	var out []models.Transaction
+// Statement reads the currency statement of userID's main wallet for [from, to) from one snapshot:
+// begin gets the opening and closing balances, then line is called for every
+// balance change oldest first. Rows are streamed, never collected.
+func (r *transactionsRepo) Statement(ctx context.Context, userID, currency string, from, to time.Time,
+	begin func(opening, closing int64) error, line func(models.StatementLine) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	const before = `SELECT COALESCE((
+		SELECT amount
+		  FROM balance_history
+		 WHERE wallet_id = $1 AND currency = $2 AND created_at < $3
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT 1), 0)`
+	var opening, closing int64
+	if err := tx.QueryRow(ctx, before, userID, currency, from).Scan(&opening); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, before, userID, currency, to).Scan(&closing); err != nil {
+		return err
+	}
+	if err := begin(opening, closing); err != nil {
+		return err
+	}
+
+	// rows no transaction made, like the opening balances backfilled when history
+	// began, are lines too, or opening plus the lines would not come to closing
+	rows, err := tx.Query(ctx,
+		`SELECT h.id, h.currency, h.created_at, h.delta, h.amount, h.transaction_id, `+statementTxnColumns()+`
+		   FROM balance_history h
+		   LEFT JOIN transactions t ON t.id = h.transaction_id
+		  WHERE h.wallet_id = $1 AND h.currency = $2 AND h.created_at >= $3 AND h.created_at < $4
+		  ORDER BY h.created_at, h.id`,
+		userID, currency, from, to,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 	for rows.Next() {
-		var tx models.Transaction
-		if err := rows.Scan(&tx.ID, &tx.FromUserID, &tx.ToUserID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt); err != nil {
-			return nil, err
+		var h models.BalanceHistory
+		var t models.Transaction
+		dest := append([]any{&h.ID, &h.Currency, &h.CreatedAt, &h.Delta, &h.Amount, &h.TransactionID}, txnDest(&t)...)
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		var made *models.Transaction
+		if h.TransactionID != nil {
+			made = &t
+		}
+		if err := line(models.HistoryLine(h, made)); err != nil {
+			return err
 		}
-		out = append(out, tx)
 	}
-	return out, rows.Err()
+	return rows.Err()
+}
+
+// statementTxnColumns are txnColumns of the transaction t LEFT JOINed to a history
+// row. Without one they are NULL, so those scanned into plain fields get defaults.
+func statementTxnColumns() string {
+	cols := strings.Split(prefixColumns("t", txnColumns), ", ")
+	for i, c := range cols {
+		switch c {
+		case "t.id", "t.currency", "t.type", "t.status":
+			cols[i] = "COALESCE(" + c + "::text, '')"
+		case "t.amount":
+			cols[i] = "COALESCE(t.amount, 0)"
+		case "t.created_at":
+			cols[i] = "COALESCE(t.created_at, h.created_at)"
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+// prefixColumns qualifies a comma-separated column list with a table alias.
+func prefixColumns(alias, cols string) string {
+	parts := strings.Split(cols, ",")
+	for i, c := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(c)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *transactionsRepo) ListByStatus(ctx context.Context, status models.TransactionStatus, limit, offset int) ([]models.Transaction, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+txnColumns+`
+		   FROM transactions
+		  WHERE status = $1
+		  ORDER BY created_at, id
+		  LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTxns(rows)
+}
+
+// SumChildrenTx totals the completed child transactions of parentID with the given type.
+func (r *transactionsRepo) SumChildrenTx(ctx context.Context, pgtx pgx.Tx, parentID string, typ models.TransactionType) (int64, error) {
+	var sum int64
+	err := pgtx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM transactions
+		  WHERE parent_id=$1 AND type=$2 AND status='completed'`,
+		parentID, typ,
+	).Scan(&sum)
+	return sum, err
 }
 
 func (r *transactionsRepo) UpdateStatus(id string, status models.TransactionStatus) error {
@@ -81,7 +309,36 @@ func (r *transactionsRepo) UpdateStatus(id string, status models.TransactionStat
 }
 
 
+func (r *transactionsRepo) ClearIdempotencyKey(id string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE transactions SET idempotency_key=NULL WHERE id=$1`, id)
+	return err
+}
+
+func (r *transactionsRepo) TransitionTx(ctx context.Context, tx pgx.Tx, id string, from, to models.TransactionStatus) (bool, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE transactions SET status=$3 WHERE id=$1 AND status=$2`,
+		id, from, to,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// WithTx runs fn in a serializable transaction, retrying on serialization failures.
 func (r *transactionsRepo) WithTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		err = r.runTx(ctx, fn)
+		if !isSerializationFailure(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *transactionsRepo) runTx(ctx context.Context, fn func(pgx.Tx) error) error {
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.Serializable,
 		AccessMode: pgx.ReadWrite,
@@ -95,3 +352,10 @@ func (r *transactionsRepo) WithTx(ctx context.Context, fn func(pgx.Tx) error) er
 	}
 	return tx.Commit(ctx)
 }
+
+const maxTxAttempts = 3
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
@@ -7,11 +7,24 @@ import (
 	"github.com/baharkarakas/insider-backend/internal/models"
 	"github.com/baharkarakas/insider-backend/internal/repository"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type usersRepo struct{ pool *pgxpool.Pool }
 
+// a freeze past state_until reads as active without a sweeper
+const userColumns = `id, username, email, password_hash, role, tier,
+       CASE WHEN state_until <= now() THEN 'active' ELSE state END,
+       CASE WHEN state_until <= now() THEN NULL ELSE state_reason END,
+       CASE WHEN state_until <= now() THEN NULL ELSE state_until END,
+       created_at, updated_at`
+
+func userDest(u *models.User) []any {
+	return []any{&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.Tier,
+		&u.State, &u.StateReason, &u.StateUntil, &u.CreatedAt, &u.UpdatedAt}
+}
+
 
 func NewUsers(pool *pgxpool.Pool) repository.Users {
 	return &usersRepo{pool: pool}
@@ -32,22 +45,22 @@ func (r *usersRepo) Create(username, email, hash, role string) (models.User, err
 func (r *usersRepo) GetByID(id string) (models.User, error) {
 	var u models.User
 	err := r.pool.QueryRow(context.Background(),
-		`SELECT id, username, email, password_hash, role, created_at, updated_at FROM users WHERE id=$1`, id,
-	).Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+		`SELECT `+userColumns+` FROM users WHERE id=$1`, id,
+	).Scan(userDest(&u)...)
 	return u, err
 }
 
 func (r *usersRepo) GetByEmail(email string) (models.User, error) {
 	var u models.User
 	err := r.pool.QueryRow(context.Background(),
-		`SELECT id, username, email, password_hash, role, created_at, updated_at FROM users WHERE email=$1`, email,
-	).Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+		`SELECT `+userColumns+` FROM users WHERE email=$1`, email,
+	).Scan(userDest(&u)...)
 	return u, err
 }
 
 func (r *usersRepo) List() ([]models.User, error) {
 	rows, err := r.pool.Query(context.Background(),
-		`SELECT id, username, email, password_hash, role, created_at, updated_at
+		`SELECT `+userColumns+`
          FROM users ORDER BY created_at DESC LIMIT 100`)
 	if err != nil {
 		return nil, err
@@ -57,7 +70,7 @@ func (r *usersRepo) List() ([]models.User, error) {
 	var out []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(userDest(&u)...); err != nil {
 			return nil, err
 		}
 		out = append(out, u)
@@ -67,8 +80,8 @@ func (r *usersRepo) List() ([]models.User, error) {
 
 func (r *usersRepo) Update(u models.User) error {
 	_, err := r.pool.Exec(context.Background(),
-		`UPDATE users SET username=$2, email=$3, role=$4, updated_at=now() WHERE id=$1`,
-		u.ID, u.Username, u.Email, u.Role,
+		`UPDATE users SET username=$2, email=$3, role=$4, tier=$5, updated_at=now() WHERE id=$1`,
+		u.ID, u.Username, u.Email, u.Role, u.Tier,
 	)
 	return err
 }
@@ -83,3 +96,23 @@ func (r *usersRepo) Exists(ctx context.Context, id string) (bool, error) {
     err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id=$1)`, id).Scan(&exists)
     return exists, err
 }
+
+// SetState moves the user to another account state and returns the user as it
+// was before and after.
+func (r *usersRepo) SetState(ctx context.Context, id string, c models.AccountStateChange) (before, after models.User, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id=$1 FOR UPDATE`, id).Scan(userDest(&before)...); err != nil {
+			return err
+		}
+		var reason *string
+		if c.Reason != "" {
+			reason = &c.Reason
+		}
+		return tx.QueryRow(ctx,
+			`UPDATE users SET state=$2, state_reason=$3, state_until=$4, state_changed_at=now(), updated_at=now()
+             WHERE id=$1 RETURNING `+userColumns,
+			id, c.State, reason, c.Until,
+		).Scan(userDest(&after)...)
+	})
+	return before, after, err
+}
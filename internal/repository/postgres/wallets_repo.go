@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type walletsRepo struct{ pool *pgxpool.Pool }
+
+const walletColumns = `id, user_id, name, kind, created_at`
+
+func scanWallet(row pgx.Row) (models.Wallet, error) {
+	var w models.Wallet
+	err := row.Scan(&w.ID, &w.UserID, &w.Name, &w.Kind, &w.CreatedAt)
+	return w, err
+}
+
+func (r *walletsRepo) Create(ctx context.Context, w models.Wallet) (models.Wallet, error) {
+	if w.ID == "" {
+		w.ID = uuid.NewString()
+	}
+	created, err := scanWallet(r.pool.QueryRow(ctx,
+		`INSERT INTO wallets(id, user_id, name, kind) VALUES($1, $2, $3, $4)
+		 RETURNING `+walletColumns,
+		w.ID, w.UserID, w.Name, w.Kind,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return models.Wallet{}, repository.ErrDuplicateWalletName
+	}
+	return created, err
+}
+
+func (r *walletsRepo) GetByID(ctx context.Context, id string) (models.Wallet, error) {
+	return scanWallet(r.pool.QueryRow(ctx, `SELECT `+walletColumns+` FROM wallets WHERE id = $1`, id))
+}
+
+func (r *walletsRepo) ListByUser(ctx context.Context, userID string) ([]models.Wallet, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets
+		  WHERE user_id = $1
+		  ORDER BY kind <> 'main', created_at, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Wallet
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+func (r *walletsRepo) Balances(ctx context.Context, userID, currency string) ([]models.WalletBalance, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT w.name, w.kind, w.user_id, w.id, $2::text,
+		        COALESCE(b.amount, 0), COALESCE(b.held_amount, 0), COALESCE(b.credit_limit, 0),
+		        COALESCE(b.overdraft_rate_bps, 0), COALESCE(b.last_updated_at, w.created_at)
+		   FROM wallets w
+		   LEFT JOIN balances b ON b.wallet_id = w.id AND b.currency = $2
+		  WHERE w.user_id = $1
+		  ORDER BY w.kind <> 'main', w.created_at, w.id`,
+		userID, currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.WalletBalance
+	for rows.Next() {
+		var wb models.WalletBalance
+		if err := rows.Scan(append([]any{&wb.Name, &wb.Kind}, balanceDest(&wb.Balance)...)...); err != nil {
+			return nil, err
+		}
+		wb.Available = wb.Amount - wb.HeldAmount + wb.CreditLimit
+		out = append(out, wb)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,151 @@
+// Package risk screens debits and transfers before money moves. The rules are
+// loaded from a JSON file and reloaded whenever the file changes.
+package risk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// Engine evaluates the built-in rules against a user's history.
+type Engine struct {
+	signals repo.RiskSignals
+	path    string
+	rules   atomic.Pointer[models.RiskRules]
+
+	mu      sync.Mutex // serialises reloads
+	modTime time.Time
+}
+
+// NewEngine loads the rules from path, or uses Defaults when path is empty.
+func NewEngine(signals repo.RiskSignals, path string) (*Engine, error) {
+	e := &Engine{signals: signals, path: path}
+	if path == "" {
+		d := Defaults()
+		e.rules.Store(&d)
+		return e, nil
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Defaults are the rules used without a rules file.
+func Defaults() models.RiskRules {
+	var r models.RiskRules
+	r.NewRecipientLarge.Enabled = true
+	r.NewRecipientLarge.MinAmount = 500000
+	r.NewRecipientLarge.Action = models.RiskReview
+
+	r.FanOut.Enabled = true
+	r.FanOut.WindowMinutes = 60
+	r.FanOut.MaxRecipients = 10
+	r.FanOut.Action = models.RiskReview
+
+	r.Spike.Enabled = true
+	r.Spike.Factor = 10
+	r.Spike.MinAmount = 100000
+	r.Spike.MinHistory = 5
+	r.Spike.Action = models.RiskReview
+
+	r.RoundAmount.Enabled = true
+	r.RoundAmount.Unit = 100000
+	r.RoundAmount.MinAmount = 100000
+	r.RoundAmount.MaxCount = 3
+	r.RoundAmount.WindowMinutes = 24 * 60
+	r.RoundAmount.Action = models.RiskReview
+	return r
+}
+
+// Rules returns the rules in force.
+func (e *Engine) Rules() models.RiskRules { return *e.rules.Load() }
+
+// Reload reads the rules file again. A file that doesn't parse or validate is
+// rejected and the current rules stay in force.
+func (e *Engine) Reload() error {
+	if e.path == "" {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	fi, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(e.path)
+	if err != nil {
+		return err
+	}
+	var r models.RiskRules
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&r); err != nil {
+		return fmt.Errorf("risk rules %s: %w", e.path, err)
+	}
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("risk rules %s: %w", e.path, err)
+	}
+	e.rules.Store(&r)
+	e.modTime = fi.ModTime()
+	return nil
+}
+
+// Watch reloads the rules file whenever its modification time changes, checking
+// every interval until ctx is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	if e.path == "" {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		fi, err := os.Stat(e.path)
+		if err != nil {
+			slog.Error("risk rules stat", "path", e.path, "err", err)
+			continue
+		}
+		e.mu.Lock()
+		changed := !fi.ModTime().Equal(e.modTime)
+		e.mu.Unlock()
+		if !changed {
+			continue
+		}
+		if err := e.Reload(); err != nil {
+			slog.Error("risk rules reload", "err", err)
+			continue
+		}
+		slog.Info("risk rules reloaded", "path", e.path)
+	}
+}
+
+// Evaluate runs every enabled rule against c and returns the most severe outcome.
+func (e *Engine) Evaluate(ctx context.Context, c models.RiskCheck) (models.RiskResult, error) {
+	rules := e.rules.Load()
+	res := models.RiskResult{Decision: models.RiskAllow}
+	for _, rule := range checks {
+		hit, ok, err := rule(ctx, e.signals, rules, c)
+		if err != nil {
+			return models.RiskResult{}, err
+		}
+		if ok {
+			res.Add(hit)
+		}
+	}
+	return res, nil
+}
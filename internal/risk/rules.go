@@ -0,0 +1,94 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// Rule names, as reported in models.RiskHit.
+const (
+	RuleNewRecipientLarge = "new_recipient_large"
+	RuleFanOut            = "fan_out"
+	RuleSpike             = "spike"
+	RuleRoundAmount       = "round_amount"
+)
+
+// spikeWindow is how far back the average of the spike rule looks.
+const spikeWindow = 30 * 24 * time.Hour
+
+type check func(ctx context.Context, s repo.RiskSignals, r *models.RiskRules, c models.RiskCheck) (models.RiskHit, bool, error)
+
+var checks = []check{newRecipientLarge, fanOut, spike, roundAmount}
+
+func newRecipientLarge(ctx context.Context, s repo.RiskSignals, r *models.RiskRules, c models.RiskCheck) (models.RiskHit, bool, error) {
+	cfg := r.NewRecipientLarge
+	if !cfg.Enabled || c.ToUserID == nil || c.Amount < cfg.MinAmount {
+		return models.RiskHit{}, false, nil
+	}
+	known, err := s.KnownRecipient(ctx, c.UserID, *c.ToUserID)
+	if err != nil || known {
+		return models.RiskHit{}, false, err
+	}
+	return models.RiskHit{
+		Rule:     RuleNewRecipientLarge,
+		Decision: cfg.Action,
+		Reason:   fmt.Sprintf("first payment to this recipient and amount %d >= %d", c.Amount, cfg.MinAmount),
+	}, true, nil
+}
+
+func fanOut(ctx context.Context, s repo.RiskSignals, r *models.RiskRules, c models.RiskCheck) (models.RiskHit, bool, error) {
+	cfg := r.FanOut
+	if !cfg.Enabled || c.ToUserID == nil {
+		return models.RiskHit{}, false, nil
+	}
+	window := time.Duration(cfg.WindowMinutes) * time.Minute
+	n, err := s.RecipientsSince(ctx, c.UserID, *c.ToUserID, time.Now().Add(-window))
+	if err != nil || n <= cfg.MaxRecipients {
+		return models.RiskHit{}, false, err
+	}
+	return models.RiskHit{
+		Rule:     RuleFanOut,
+		Decision: cfg.Action,
+		Reason:   fmt.Sprintf("%d recipients within %s, max %d", n, window, cfg.MaxRecipients),
+	}, true, nil
+}
+
+func spike(ctx context.Context, s repo.RiskSignals, r *models.RiskRules, c models.RiskCheck) (models.RiskHit, bool, error) {
+	cfg := r.Spike
+	if !cfg.Enabled || c.Amount < cfg.MinAmount {
+		return models.RiskHit{}, false, nil
+	}
+	n, avg, err := s.OutgoingStats(ctx, c.UserID, c.Currency, time.Now().Add(-spikeWindow))
+	// past MaxInt64/Factor no amount can be Factor times the average
+	if err != nil || n < cfg.MinHistory || avg <= 0 || avg > math.MaxInt64/cfg.Factor || c.Amount <= cfg.Factor*avg {
+		return models.RiskHit{}, false, err
+	}
+	return models.RiskHit{
+		Rule:     RuleSpike,
+		Decision: cfg.Action,
+		Reason:   fmt.Sprintf("amount %d is over %dx the 30-day average of %d", c.Amount, cfg.Factor, avg),
+	}, true, nil
+}
+
+func roundAmount(ctx context.Context, s repo.RiskSignals, r *models.RiskRules, c models.RiskCheck) (models.RiskHit, bool, error) {
+	cfg := r.RoundAmount
+	if !cfg.Enabled || c.Amount < cfg.MinAmount || c.Amount%cfg.Unit != 0 {
+		return models.RiskHit{}, false, nil
+	}
+	window := time.Duration(cfg.WindowMinutes) * time.Minute
+	n, err := s.RoundAmountsSince(ctx, c.UserID, c.Currency, cfg.Unit, time.Now().Add(-window))
+	// this one included
+	if err != nil || n+1 <= cfg.MaxCount {
+		return models.RiskHit{}, false, err
+	}
+	return models.RiskHit{
+		Rule:     RuleRoundAmount,
+		Decision: cfg.Action,
+		Reason:   fmt.Sprintf("%d round amounts (multiples of %d) within %s, max %d", n+1, cfg.Unit, window, cfg.MaxCount),
+	}, true, nil
+}
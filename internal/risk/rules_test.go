@@ -0,0 +1,57 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// countSignals answers the counting signals with fixed numbers. recipients
+// includes the one being paid, rounds excludes the amount being screened,
+// as in the postgres repo.
+type countSignals struct {
+	repo.RiskSignals
+	recipients, rounds int
+}
+
+func (s countSignals) RecipientsSince(context.Context, string, string, time.Time) (int, error) {
+	return s.recipients, nil
+}
+
+func (s countSignals) RoundAmountsSince(context.Context, string, string, int64, time.Time) (int, error) {
+	return s.rounds, nil
+}
+
+// Max is the largest count allowed: both rules let it through and flag one more.
+func TestCountRulesBoundary(t *testing.T) {
+	rules := Defaults()
+	to := "b"
+	c := models.RiskCheck{UserID: "a", ToUserID: &to, Amount: 300000, Currency: "USD"}
+	tests := []struct {
+		name    string
+		rule    check
+		signals countSignals
+		want    bool
+	}{
+		{"fan out below max", fanOut, countSignals{recipients: rules.FanOut.MaxRecipients - 1}, false},
+		{"fan out at max", fanOut, countSignals{recipients: rules.FanOut.MaxRecipients}, false},
+		{"fan out over max", fanOut, countSignals{recipients: rules.FanOut.MaxRecipients + 1}, true},
+		{"round amounts below max", roundAmount, countSignals{rounds: rules.RoundAmount.MaxCount - 2}, false},
+		{"round amounts at max", roundAmount, countSignals{rounds: rules.RoundAmount.MaxCount - 1}, false},
+		{"round amounts over max", roundAmount, countSignals{rounds: rules.RoundAmount.MaxCount}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok, err := tt.rule(context.Background(), tt.signals, &rules, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want {
+				t.Errorf("flagged = %v (%s), want %v", ok, hit.Reason, tt.want)
+			}
+		})
+	}
+}
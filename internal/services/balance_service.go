@@ -1,12 +1,46 @@
 package services
 
 import (
+	"context"
+	"time"
+
 	"github.com/baharkarakas/insider-backend/internal/models"
 	repo "github.com/baharkarakas/insider-backend/internal/repository"
 )
 
-type BalanceService struct { r repo.Balances }
+type BalanceService struct {
+	r repo.Balances
+	w repo.Wallets
+}
+
+func NewBalanceService(r repo.Balances, w repo.Wallets) *BalanceService {
+	return &BalanceService{r: r, w: w}
+}
+
+// Current returns every wallet of the user in cur and their total.
+func (s *BalanceService) Current(userID, cur string) (models.BalanceSummary, error) {
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.BalanceSummary{}, err
+	}
+	if _, err := s.r.GetOrCreate(userID, cur); err != nil {
+		return models.BalanceSummary{}, err
+	}
+	wallets, err := s.w.Balances(context.Background(), userID, cur)
+	if err != nil {
+		return models.BalanceSummary{}, err
+	}
+	return models.Summarize(cur, wallets), nil
+}
 
-func NewBalanceService(r repo.Balances) *BalanceService { return &BalanceService{r: r} }
+// List returns the balance of every wallet of the user in every currency they hold.
+func (s *BalanceService) List(userID string) ([]models.Balance, error) { return s.r.ListByUser(userID) }
 
-func (s *BalanceService) Current(userID string) (models.Balance, error) { return s.r.GetOrCreate(userID) }
\ No newline at end of file
+// AtTime returns the main wallet balance as it was at the given moment, based on balance_history.
+func (s *BalanceService) AtTime(userID, cur string, at time.Time) (int64, error) {
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.AmountAt(context.Background(), userID, cur, at)
+}
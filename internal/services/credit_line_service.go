@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// interestLookback is how many past days each accrual run revisits, so days
+// missed while no replica was running are still charged.
+const interestLookback = 7
+
+type CreditLineService struct {
+	bal   repo.Balances
+	users repo.Users
+	ts    *TransactionService
+}
+
+func NewCreditLineService(bal repo.Balances, users repo.Users, ts *TransactionService) *CreditLineService {
+	return &CreditLineService{bal: bal, users: users, ts: ts}
+}
+
+// Set grants userID a credit line of limit in cur with an annual overdraft rate in
+// basis points. A limit of 0 withdraws the line; whatever is drawn stays owed.
+func (s *CreditLineService) Set(userID, cur string, limit, rateBPS int64) (models.Balance, error) {
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Balance{}, err
+	}
+	if limit < 0 {
+		return models.Balance{}, errors.New("credit_limit must be >= 0")
+	}
+	if rateBPS < 0 || rateBPS > 10000 {
+		return models.Balance{}, errors.New("overdraft_rate_bps must be 0-10000")
+	}
+	exists, err := s.users.Exists(context.Background(), userID)
+	if err != nil {
+		return models.Balance{}, err
+	}
+	if !exists {
+		return models.Balance{}, ErrUserNotFound
+	}
+	return s.bal.SetCreditLine(context.Background(), userID, cur, limit, rateBPS)
+}
+
+// AccrueInterest charges a day of interest for every balance that closed a UTC
+// day below zero, for each of the last interestLookback complete days.
+func (s *CreditLineService) AccrueInterest() error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for d := interestLookback; d >= 1; d-- {
+		day := today.AddDate(0, 0, -d)
+		positions, err := s.bal.Overdrawn(context.Background(), day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		charged := 0
+		for _, p := range positions {
+			interest := dailyInterest(-p.Amount, p.RateBPS)
+			if interest == 0 {
+				continue
+			}
+			_, ok, err := s.ts.ChargeOverdraftInterest(p.UserID, p.WalletID, p.Currency, interest, day)
+			if err != nil {
+				slog.Error("overdraft interest", "user_id", p.UserID, "wallet_id", p.WalletID, "currency", p.Currency, "day", day.Format("2006-01-02"), "err", err)
+				continue
+			}
+			if ok {
+				charged++
+			}
+		}
+		if charged > 0 {
+			slog.Info("overdraft interest charged", "day", day.Format("2006-01-02"), "balances", charged)
+		}
+	}
+	return nil
+}
+
+// dailyInterest is one day (1/365) of rateBPS annual interest on drawn, rounded half up.
+func dailyInterest(drawn, rateBPS int64) int64 {
+	n := new(big.Int).Mul(big.NewInt(drawn), big.NewInt(rateBPS))
+	den := big.NewInt(10000 * 365)
+	n.Add(n, new(big.Int).Quo(den, big.NewInt(2)))
+	return n.Quo(n, den).Int64()
+}
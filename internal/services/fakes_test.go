@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// In-memory repositories for service tests. Each implements only what the
+// tested paths call; anything else panics on the embedded nil interface.
+// WithTx runs fn with a nil pgx.Tx and keeps whatever fn wrote, even on error.
+
+type memTxns struct {
+	repo.Transactions
+	rows map[string]models.Transaction
+}
+
+func (m *memTxns) Create(tx models.Transaction) (models.Transaction, error) {
+	return m.CreateTx(context.Background(), nil, tx)
+}
+
+func (m *memTxns) CreateTx(_ context.Context, _ pgx.Tx, tx models.Transaction) (models.Transaction, error) {
+	if m.rows == nil {
+		m.rows = map[string]models.Transaction{}
+	}
+	if tx.ID == "" {
+		tx.ID = uuid.NewString()
+	}
+	m.rows[tx.ID] = tx
+	return tx, nil
+}
+
+func (m *memTxns) GetByID(id string) (models.Transaction, error) {
+	tx, ok := m.rows[id]
+	if !ok {
+		return models.Transaction{}, pgx.ErrNoRows
+	}
+	return tx, nil
+}
+
+func (m *memTxns) TransitionTx(_ context.Context, _ pgx.Tx, id string, from, to models.TransactionStatus) (bool, error) {
+	tx, ok := m.rows[id]
+	if !ok || tx.Status != from {
+		return false, nil
+	}
+	tx.Status = to
+	m.rows[id] = tx
+	return true, nil
+}
+
+func (m *memTxns) WithTx(_ context.Context, fn func(pgx.Tx) error) error { return fn(nil) }
+
+type memUsers struct {
+	repo.Users
+	users map[string]models.User
+}
+
+func (m *memUsers) add(id string) string {
+	if m.users == nil {
+		m.users = map[string]models.User{}
+	}
+	m.users[id] = models.User{ID: id, Role: "user", State: models.AccountActive}
+	return id
+}
+
+func (m *memUsers) GetByID(id string) (models.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return models.User{}, pgx.ErrNoRows
+	}
+	return u, nil
+}
+
+func (m *memUsers) Exists(_ context.Context, id string) (bool, error) {
+	_, ok := m.users[id]
+	return ok, nil
+}
+
+type memBalances struct {
+	repo.Balances
+	rows map[string]models.Balance
+}
+
+func (m *memBalances) GetOrCreate(walletID, cur string) (models.Balance, error) {
+	if m.rows == nil {
+		m.rows = map[string]models.Balance{}
+	}
+	b, ok := m.rows[walletID+":"+cur]
+	if !ok {
+		b = models.Balance{UserID: walletID, Currency: cur}
+		m.rows[walletID+":"+cur] = b
+	}
+	return b, nil
+}
+
+func (m *memBalances) Get(walletID, cur string) (models.Balance, error) {
+	b, ok := m.rows[walletID+":"+cur]
+	if !ok {
+		return models.Balance{}, pgx.ErrNoRows
+	}
+	return b, nil
+}
+
+// memLedger applies every posting to the main wallet balances of memBalances
+// and, like the real ledger, refuses to take one below its credit line.
+type memLedger struct {
+	repo.Ledger
+	bal *memBalances
+}
+
+func (m *memLedger) Post(_ context.Context, _ pgx.Tx, e models.JournalEntry) (models.JournalEntry, error) {
+	next := map[string]models.Balance{}
+	for _, p := range e.Postings {
+		userID, ok := models.UserIDFromAccountCode(p.AccountCode)
+		if !ok {
+			continue
+		}
+		cur := models.AccountCurrency(p.AccountCode)
+		b, seen := next[userID+":"+cur]
+		if !seen {
+			b, _ = m.bal.GetOrCreate(userID, cur)
+		}
+		if p.Direction == models.PostingCredit {
+			b.Amount += p.Amount
+		} else {
+			b.Amount -= p.Amount
+		}
+		b.Available = b.Amount - b.HeldAmount + b.CreditLimit
+		if b.Available < 0 && !e.Unguarded {
+			return models.JournalEntry{}, errors.New("insufficient balance")
+		}
+		next[userID+":"+cur] = b
+	}
+	for k, b := range next {
+		m.bal.rows[k] = b
+	}
+	return e, nil
+}
+
+type memFX struct {
+	repo.FXRates
+	rates map[string]string
+}
+
+func (m *memFX) Latest(_ context.Context, base, quote string) (models.FXRate, error) {
+	r, ok := m.rates[base+"/"+quote]
+	if !ok {
+		return models.FXRate{}, pgx.ErrNoRows
+	}
+	return models.FXRate{ID: 1, BaseCurrency: base, QuoteCurrency: quote, Rate: r}, nil
+}
+
+type memConversions struct{ repo.FXConversions }
+
+func (memConversions) CreateTx(_ context.Context, _ pgx.Tx, c models.FXConversion) (models.FXConversion, error) {
+	c.ID = uuid.NewString()
+	return c, nil
+}
+
+type memAudit struct{ entries []models.AuditLog }
+
+func (m *memAudit) Create(l models.AuditLog) error {
+	m.entries = append(m.entries, l)
+	return nil
+}
+
+// noLimits has no limit policies, noFees no fee rules.
+type noLimits struct{ repo.LimitPolicies }
+
+func (noLimits) Matching(context.Context, string, string, string, string, string) ([]models.LimitPolicy, error) {
+	return nil, nil
+}
+
+type noFees struct{ repo.FeeRules }
+
+func (noFees) Match(context.Context, string, models.TransactionType, string) (models.FeeRule, error) {
+	return models.FeeRule{}, pgx.ErrNoRows
+}
+
+// testBank is a TransactionService over the in-memory repositories.
+type testBank struct {
+	*TransactionService
+	txns  *memTxns
+	users *memUsers
+	bal   *memBalances
+	audit *memAudit
+}
+
+func newTestBank(approvalThreshold int64) *testBank {
+	b := &testBank{txns: &memTxns{}, users: &memUsers{}, bal: &memBalances{}, audit: &memAudit{}}
+	b.TransactionService = &TransactionService{
+		trx:               b.txns,
+		bal:               b.bal,
+		log:               b.audit,
+		users:             b.users,
+		ledger:            &memLedger{bal: b.bal},
+		fx:                &memFX{rates: map[string]string{"USD/EUR": "0.9"}},
+		conv:              memConversions{},
+		limits:            NewLimitService(noLimits{}, b.users),
+		fees:              NewFeeService(noFees{}, b.users),
+		approvalThreshold: approvalThreshold,
+	}
+	return b
+}
+
+// fund credits userID's main wallet directly in the ledger.
+func (b *testBank) fund(userID, cur string, amount int64) {
+	e := models.NewTransferEntry(uuid.NewString(), "test funding",
+		models.SystemAccountCode(models.AccountFunding, cur), models.UserAccountCode(userID, cur), amount)
+	if _, err := b.ledger.Post(context.Background(), nil, e); err != nil {
+		panic(fmt.Sprint("fund: ", err))
+	}
+}
+
+func (b *testBank) balance(userID, cur string) int64 {
+	bal, _ := b.bal.Get(userID, cur)
+	return bal.Amount
+}
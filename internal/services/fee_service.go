@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrFeeRuleNotFound = errors.New("fee rule not found")
+
+type FeeService struct {
+	r     repo.FeeRules
+	users repo.Users
+}
+
+func NewFeeService(r repo.FeeRules, u repo.Users) *FeeService {
+	return &FeeService{r: r, users: u}
+}
+
+// rule finds the fee rule for userID's role; ok is false when none applies.
+func (s *FeeService) rule(ctx context.Context, userID string, typ models.TransactionType, cur string) (models.FeeRule, bool, error) {
+	if typ != models.TxnTransfer && typ != models.TxnDebit {
+		return models.FeeRule{}, false, nil
+	}
+	u, err := s.users.GetByID(userID)
+	if err != nil {
+		return models.FeeRule{}, false, fmt.Errorf("load user for fees: %w", err)
+	}
+	r, err := s.r.Match(ctx, u.Role, typ, cur)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.FeeRule{}, false, nil
+	}
+	if err != nil {
+		return models.FeeRule{}, false, err
+	}
+	return r, true, nil
+}
+
+// Quote returns the fee userID would pay on top of amount.
+func (s *FeeService) Quote(userID string, typ models.TransactionType, amount int64, cur string) (models.FeeQuote, error) {
+	if amount <= 0 {
+		return models.FeeQuote{}, errors.New("amount must be > 0")
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.FeeQuote{}, err
+	}
+	q := models.FeeQuote{TxnType: typ, Amount: amount, Currency: cur, Total: amount}
+	r, ok, err := s.rule(context.Background(), userID, typ, cur)
+	if err != nil || !ok {
+		return q, err
+	}
+	q.Fee = r.Compute(amount)
+	q.Total += q.Fee
+	q.RuleID = &r.ID
+	return q, nil
+}
+
+//  admin 
+
+func (s *FeeService) List() ([]models.FeeRule, error) {
+	return s.r.List(context.Background())
+}
+
+func (s *FeeService) Create(r models.FeeRule) (models.FeeRule, error) {
+	if r.Role == "" {
+		r.Role = models.FeeAnyRole
+	}
+	cur, err := currencyCode(r.Currency)
+	if err != nil {
+		return models.FeeRule{}, err
+	}
+	r.Currency = cur
+	if err := r.Validate(); err != nil {
+		return models.FeeRule{}, err
+	}
+	return s.r.Create(context.Background(), r)
+}
+
+// Update replaces the pricing of a rule; role, type and currency are fixed.
+func (s *FeeService) Update(id string, pricing models.FeeRule) (models.FeeRule, error) {
+	ctx := context.Background()
+	r, err := s.r.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.FeeRule{}, ErrFeeRuleNotFound
+	}
+	if err != nil {
+		return models.FeeRule{}, err
+	}
+	r.Flat, r.BPS, r.Tiers, r.MinFee, r.MaxFee = pricing.Flat, pricing.BPS, pricing.Tiers, pricing.MinFee, pricing.MaxFee
+	if err := r.Validate(); err != nil {
+		return models.FeeRule{}, err
+	}
+	return s.r.Update(ctx, r)
+}
+
+func (s *FeeService) Delete(id string) error {
+	err := s.r.Delete(context.Background(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrFeeRuleNotFound
+	}
+	return err
+}
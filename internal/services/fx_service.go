@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+type FXService struct{ r repo.FXRates }
+
+func NewFXService(r repo.FXRates) *FXService { return &FXService{r: r} }
+
+// SetRate records a new rate for base->quote. Earlier rates stay on file so past
+// conversions keep pointing at the rate they used. Admin only; enforced by the router.
+func (s *FXService) SetRate(base, quote, rate, actorID string) (models.FXRate, error) {
+	base, err := currencyCode(base)
+	if err != nil {
+		return models.FXRate{}, err
+	}
+	quote, err = currencyCode(quote)
+	if err != nil {
+		return models.FXRate{}, err
+	}
+	if base == quote {
+		return models.FXRate{}, errors.New("base and quote currency must differ")
+	}
+	r, err := currency.ParseRate(rate)
+	if err != nil {
+		return models.FXRate{}, err
+	}
+	fr := models.FXRate{BaseCurrency: base, QuoteCurrency: quote, Rate: r.FloatString(12)}
+	if actorID != "" {
+		fr.CreatedBy = &actorID
+	}
+	return s.r.Create(context.Background(), fr)
+}
+
+// Rates returns the current rate of every pair.
+func (s *FXService) Rates() ([]models.FXRate, error) {
+	return s.r.ListLatest(context.Background())
+}
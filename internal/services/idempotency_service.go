@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+type IdempotencyService struct {
+	r     repo.IdempotencyKeys
+	ttl   time.Duration
+	lease time.Duration
+}
+
+// NewIdempotencyService keeps responses for ttl. A request holds its key for
+// lease; a retry after that takes the key over, so lease should be longer than
+// any request takes.
+func NewIdempotencyService(r repo.IdempotencyKeys, ttl, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{r: r, ttl: ttl, lease: lease}
+}
+
+// Begin reserves key for a new request. When the key is already in use it returns
+// the stored record and false; the caller compares hashes and replays or rejects.
+func (s *IdempotencyService) Begin(userID, endpoint, key, requestHash string) (models.IdempotencyKey, bool, error) {
+	now := time.Now()
+	lockedUntil := now.Add(s.lease)
+	return s.r.Reserve(context.Background(), models.IdempotencyKey{
+		UserID:      userID,
+		Endpoint:    endpoint,
+		Key:         key,
+		RequestHash: requestHash,
+		LockedUntil: &lockedUntil,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+}
+
+func (s *IdempotencyService) Finish(userID, endpoint, key string, status int, contentType string, body []byte) error {
+	return s.r.Complete(context.Background(), userID, endpoint, key, status, contentType, body)
+}
+
+// Abort drops a reservation so the client may retry with the same key.
+func (s *IdempotencyService) Abort(userID, endpoint, key string) error {
+	return s.r.Delete(context.Background(), userID, endpoint, key)
+}
+
+func (s *IdempotencyService) PurgeExpired() (int64, error) {
+	return s.r.PurgeExpired(context.Background())
+}
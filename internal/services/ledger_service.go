@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+type LedgerService struct{ r repo.Ledger }
+
+func NewLedgerService(r repo.Ledger) *LedgerService { return &LedgerService{r: r} }
+
+// TrialBalance sums postings per account; Balanced is false if the books don't add up.
+func (s *LedgerService) TrialBalance() (models.TrialBalance, error) {
+	return s.r.TrialBalance(context.Background())
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrLimitPolicyNotFound = errors.New("limit policy not found")
+
+// LimitExceededError says which limit a transaction would break and when the
+// rolling window next frees up (nil for the per-transaction maximum).
+type LimitExceededError struct {
+	Limit     string     `json:"limit"` // max_amount | daily_amount | daily_count | monthly_amount | monthly_count
+	PolicyID  string     `json:"policy_id"`
+	Max       int64      `json:"max"`
+	Used      int64      `json:"used"`
+	Requested int64      `json:"requested"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit exceeded (max %d, used %d, requested %d)", e.Limit, e.Max, e.Used, e.Requested)
+}
+
+type LimitService struct {
+	r     repo.LimitPolicies
+	users repo.Users
+}
+
+func NewLimitService(r repo.LimitPolicies, u repo.Users) *LimitService {
+	return &LimitService{r: r, users: u}
+}
+
+// Check enforces the policy that applies to userID before amounts of typ are moved.
+// Several amounts are checked as separate transactions (a batch).
+func (s *LimitService) Check(ctx context.Context, userID string, typ models.TransactionType, cur string, amounts ...int64) error {
+	return s.check(ctx, userID, typ, cur, amounts, func(now time.Time) (models.LimitUsage, error) {
+		return s.r.Usage(ctx, userID, typ, cur, now)
+	})
+}
+
+// CheckTx repeats Check inside pgtx, the DB transaction that moves the money.
+// It holds userID's limit lock until pgtx ends, so a user's transactions are
+// checked one at a time, and counts only what has already moved; txID, if set,
+// is the transaction being settled.
+func (s *LimitService) CheckTx(ctx context.Context, pgtx pgx.Tx, userID string, typ models.TransactionType, cur, txID string, amounts ...int64) error {
+	return s.check(ctx, userID, typ, cur, amounts, func(now time.Time) (models.LimitUsage, error) {
+		return s.r.UsageTx(ctx, pgtx, userID, typ, cur, txID, now)
+	})
+}
+
+func (s *LimitService) check(ctx context.Context, userID string, typ models.TransactionType, cur string, amounts []int64,
+	usage func(now time.Time) (models.LimitUsage, error)) error {
+	u, err := s.users.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("load user for limits: %w", err)
+	}
+	candidates, err := s.r.Matching(ctx, u.Role, u.Tier, userID, string(typ), cur)
+	if err != nil {
+		return err
+	}
+	p, ok := mostSpecific(candidates, string(typ))
+	if !ok {
+		return nil
+	}
+
+	var total int64
+	for _, a := range amounts {
+		if p.MaxAmount != nil && a > *p.MaxAmount {
+			return &LimitExceededError{Limit: "max_amount", PolicyID: p.ID, Max: *p.MaxAmount, Requested: a}
+		}
+		total += a
+	}
+	if p.DailyAmount == nil && p.MonthlyAmount == nil && p.DailyCount == nil && p.MonthlyCount == nil {
+		return nil
+	}
+
+	now := time.Now()
+	use, err := usage(now)
+	if err != nil {
+		return err
+	}
+	n := int64(len(amounts))
+	checks := []struct {
+		name      string
+		max       *int64
+		used, req int64
+		oldest    *time.Time
+		window    time.Duration
+	}{
+		{"daily_amount", p.DailyAmount, use.DailyAmount, total, use.DailyOldest, models.LimitDay},
+		{"daily_count", p.DailyCount, use.DailyCount, n, use.DailyOldest, models.LimitDay},
+		{"monthly_amount", p.MonthlyAmount, use.MonthlyAmount, total, use.MonthlyOldest, models.LimitMonth},
+		{"monthly_count", p.MonthlyCount, use.MonthlyCount, n, use.MonthlyOldest, models.LimitMonth},
+	}
+	for _, c := range checks {
+		if c.max == nil || c.used+c.req <= *c.max {
+			continue
+		}
+		e := &LimitExceededError{Limit: c.name, PolicyID: p.ID, Max: *c.max, Used: c.used, Requested: c.req}
+		// the window frees up as its oldest transaction ages out
+		if c.oldest != nil {
+			t := c.oldest.Add(c.window)
+			e.ResetsAt = &t
+		}
+		return e
+	}
+	return nil
+}
+
+// mostSpecific picks the policy that applies: user over tier over role, and an
+// exact transaction type over '*'.
+func mostSpecific(ps []models.LimitPolicy, typ string) (models.LimitPolicy, bool) {
+	rank := func(p models.LimitPolicy) int {
+		r := map[models.LimitScope]int{models.LimitScopeRole: 0, models.LimitScopeTier: 2, models.LimitScopeUser: 4}[p.Scope]
+		if p.TxnType == typ {
+			r++
+		}
+		return r
+	}
+	best, found := models.LimitPolicy{}, false
+	for _, p := range ps {
+		if !found || rank(p) > rank(best) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+//  admin 
+
+func (s *LimitService) List() ([]models.LimitPolicy, error) {
+	return s.r.List(context.Background())
+}
+
+func (s *LimitService) Create(p models.LimitPolicy) (models.LimitPolicy, error) {
+	if p.TxnType == "" {
+		p.TxnType = models.LimitAnyType
+	}
+	cur, err := currencyCode(p.Currency)
+	if err != nil {
+		return models.LimitPolicy{}, err
+	}
+	p.Currency = cur
+	if err := p.Validate(); err != nil {
+		return models.LimitPolicy{}, err
+	}
+	return s.r.Create(context.Background(), p)
+}
+
+// Update replaces the limits of a policy; scope, subject, type and currency are fixed.
+func (s *LimitService) Update(id string, limits models.LimitPolicy) (models.LimitPolicy, error) {
+	ctx := context.Background()
+	p, err := s.r.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.LimitPolicy{}, ErrLimitPolicyNotFound
+	}
+	if err != nil {
+		return models.LimitPolicy{}, err
+	}
+	p.MaxAmount, p.DailyAmount, p.MonthlyAmount = limits.MaxAmount, limits.DailyAmount, limits.MonthlyAmount
+	p.DailyCount, p.MonthlyCount = limits.DailyCount, limits.MonthlyCount
+	if err := p.Validate(); err != nil {
+		return models.LimitPolicy{}, err
+	}
+	return s.r.Update(ctx, p)
+}
+
+func (s *LimitService) Delete(id string) error {
+	err := s.r.Delete(context.Background(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLimitPolicyNotFound
+	}
+	return err
+}
+
+// SetTier moves a user to another limit tier.
+func (s *LimitService) SetTier(userID, tier string) (models.User, error) {
+	if tier == "" {
+		return models.User{}, errors.New("tier is required")
+	}
+	u, err := s.users.GetByID(userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	u.Tier = tier
+	if err := s.users.Update(u); err != nil {
+		return models.User{}, err
+	}
+	return u, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOrgNotFound  = errors.New("organization not found")
+	ErrOrgForbidden = errors.New("your role in this organization does not allow this")
+	ErrNotAMember   = errors.New("user is not a member of this organization")
+)
+
+// OrgService manages organizations and acts for them. Access is decided by the
+// caller's membership and role in the organization, not by their JWT role, so
+// every entry point taking an orgID goes through Authorize first.
+type OrgService struct {
+	orgs  repo.Organizations
+	users repo.Users
+	ts    *TransactionService
+	bs    *BalanceService
+	log   repo.AuditLogs
+}
+
+func NewOrgService(o repo.Organizations, u repo.Users, ts *TransactionService, bs *BalanceService, l repo.AuditLogs) *OrgService {
+	return &OrgService{orgs: o, users: u, ts: ts, bs: bs, log: l}
+}
+
+func (s *OrgService) audit(orgID, action string, det map[string]any) {
+	_ = s.log.Create(models.AuditLog{
+		EntityType: "organization",
+		EntityID:   &orgID,
+		Action:     action,
+		Details:    det,
+	})
+}
+
+// Create opens an organization owned by ownerID.
+func (s *OrgService) Create(ownerID, name string) (models.Organization, error) {
+	name, err := models.ValidateOrgName(name)
+	if err != nil {
+		return models.Organization{}, err
+	}
+	o, err := s.orgs.Create(context.Background(), name, ownerID)
+	if err != nil {
+		return models.Organization{}, err
+	}
+	s.audit(o.ID, "created", map[string]any{"name": o.Name, "actor_id": ownerID})
+	return o, nil
+}
+
+// Mine lists the organizations userID belongs to.
+func (s *OrgService) Mine(userID string) ([]models.Organization, error) {
+	out, err := s.orgs.ListByMember(context.Background(), userID)
+	if out == nil {
+		out = []models.Organization{}
+	}
+	return out, err
+}
+
+// Authorize returns userID's membership if their role allows a. Non-members get
+// ErrOrgNotFound, so they can't probe which organizations exist.
+func (s *OrgService) Authorize(orgID, userID string, a models.OrgAction) (models.OrgMember, error) {
+	m, err := s.orgs.Member(context.Background(), orgID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.OrgMember{}, ErrOrgNotFound
+	}
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+	if !m.Role.Can(a) {
+		return models.OrgMember{}, ErrOrgForbidden
+	}
+	return m, nil
+}
+
+// Get returns the organization with the caller's role filled in.
+func (s *OrgService) Get(orgID string, actor models.OrgMember) (models.Organization, error) {
+	o, err := s.orgs.GetByID(context.Background(), orgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Organization{}, ErrOrgNotFound
+	}
+	o.Role = actor.Role
+	return o, err
+}
+
+func (s *OrgService) Members(orgID string) ([]models.OrgMember, error) {
+	out, err := s.orgs.Members(context.Background(), orgID)
+	if out == nil {
+		out = []models.OrgMember{}
+	}
+	return out, err
+}
+
+// SetMember adds userID with role or changes their role. Only people can be
+// members; another organization's account row can't.
+func (s *OrgService) SetMember(orgID string, actor models.OrgMember, userID string, role models.OrgRole) (models.OrgMember, error) {
+	if !role.Valid() {
+		return models.OrgMember{}, errors.New("role must be one of owner, approver, viewer")
+	}
+	u, err := s.users.GetByID(userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && u.Role == models.RoleOrganization) {
+		return models.OrgMember{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+	before, m, err := s.orgs.SetMember(context.Background(), orgID, userID, role, actor.UserID)
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+	action := "member_role_changed"
+	if before == "" {
+		action = "member_added"
+	}
+	s.audit(orgID, action, map[string]any{
+		"member_id": userID, "from": before, "to": m.Role,
+		"actor_id": actor.UserID, "actor_role": actor.Role,
+	})
+	return m, nil
+}
+
+func (s *OrgService) RemoveMember(orgID string, actor models.OrgMember, userID string) error {
+	m, err := s.orgs.RemoveMember(context.Background(), orgID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotAMember
+	}
+	if err != nil {
+		return err
+	}
+	s.audit(orgID, "member_removed", map[string]any{
+		"member_id": userID, "role": m.Role,
+		"actor_id": actor.UserID, "actor_role": actor.Role,
+	})
+	return nil
+}
+
+// Transfer sends money from the organization's main wallet. It is an ordinary
+// transfer from the organization's account, so limits, fees, risk screening and
+// approval all apply. The member who sent it is its creator, so they can't
+// approve it, and is recorded on its audit trail, also when it is held or awaits approval.
+func (s *OrgService) Transfer(orgID string, actor models.OrgMember, toID, toWalletID string, amount int64, cur string, d models.TxnDetails) (models.Transaction, error) {
+	tx, err := s.ts.transferAs(actor.UserID, orgID, toID, toWalletID, amount, cur, "", d)
+	if tx.ID != "" {
+		s.ts.auditDetails(tx.ID, "on_behalf", map[string]any{
+			"org_id": orgID, "member_id": actor.UserID, "member_role": actor.Role,
+		})
+	}
+	return tx, err
+}
+
+// sentBy loads a transfer orgID sent; any other transaction is not found, so
+// members can't reach other accounts' transfers through their organization.
+func (s *OrgService) sentBy(orgID, txID string) (models.Transaction, error) {
+	tx, err := s.ts.trx.GetByID(txID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && (tx.FromUserID == nil || *tx.FromUserID != orgID)) {
+		return models.Transaction{}, ErrTransactionNotFound
+	}
+	return tx, err
+}
+
+// Approve executes a transfer of the organization awaiting approval. The member
+// who sent it can't approve it; see TransactionService.Approve.
+func (s *OrgService) Approve(orgID string, actor models.OrgMember, txID string) (models.Transaction, error) {
+	if _, err := s.sentBy(orgID, txID); err != nil {
+		return models.Transaction{}, err
+	}
+	tx, err := s.ts.Approve(txID, actor.UserID)
+	if tx.ID != "" {
+		s.audit(orgID, "transfer_approved", map[string]any{
+			"transaction_id": tx.ID, "status": tx.Status, "actor_id": actor.UserID, "actor_role": actor.Role,
+		})
+	}
+	return tx, err
+}
+
+// Reject turns down a transfer of the organization awaiting approval.
+func (s *OrgService) Reject(orgID string, actor models.OrgMember, txID, reason string) (models.Transaction, error) {
+	if _, err := s.sentBy(orgID, txID); err != nil {
+		return models.Transaction{}, err
+	}
+	tx, err := s.ts.Reject(txID, actor.UserID, reason)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	s.audit(orgID, "transfer_rejected", map[string]any{
+		"transaction_id": tx.ID, "reason": reason, "actor_id": actor.UserID, "actor_role": actor.Role,
+	})
+	return tx, nil
+}
+
+func (s *OrgService) Balance(orgID, cur string) (models.BalanceSummary, error) {
+	return s.bs.Current(orgID, cur)
+}
+
+func (s *OrgService) History(orgID string, f models.TransactionFilter, cursor string, limit int) (HistoryPage, error) {
+	return s.ts.History(orgID, f, cursor, limit)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+func TestOrgApproverApprovesAnotherMembersTransfer(t *testing.T) {
+	b := newTestBank(100000)
+	org := b.users.add("6f0c1c1e-0000-4000-8000-0000000000a1")
+	maker := models.OrgMember{OrgID: org, UserID: b.users.add("6f0c1c1e-0000-4000-8000-000000000001"), Role: models.OrgApprover}
+	checker := models.OrgMember{OrgID: org, UserID: b.users.add("6f0c1c1e-0000-4000-8000-000000000002"), Role: models.OrgApprover}
+	payee := b.users.add("6f0c1c1e-0000-4000-8000-000000000003")
+	b.fund(org, "USD", 1000000)
+	orgs := NewOrgService(nil, b.users, b.TransactionService, nil, b.audit)
+
+	tx, err := orgs.Transfer(org, maker, payee, "", 200000, "USD", models.TxnDetails{})
+	if err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if tx.Status != models.TxnAwaitingApproval || tx.CreatedBy == nil || *tx.CreatedBy != maker.UserID {
+		t.Fatalf("transfer is %s by %v, want awaiting_approval by the maker", tx.Status, tx.CreatedBy)
+	}
+
+	if _, err := orgs.Approve(org, maker, tx.ID); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("maker approving: %v, want ErrSelfApproval", err)
+	}
+	// another organization can't reach it
+	other := models.OrgMember{OrgID: payee, UserID: checker.UserID, Role: models.OrgOwner}
+	if _, err := orgs.Approve(payee, other, tx.ID); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("approving through another organization: %v, want ErrTransactionNotFound", err)
+	}
+	if b.balance(payee, "USD") != 0 {
+		t.Fatalf("paid %d before approval", b.balance(payee, "USD"))
+	}
+
+	out, err := orgs.Approve(org, checker, tx.ID)
+	if err != nil {
+		t.Fatalf("checker approving: %v", err)
+	}
+	if out.Status != models.TxnCompleted || b.balance(payee, "USD") != 200000 || b.balance(org, "USD") != 800000 {
+		t.Errorf("after approval: %s, payee %d, org %d", out.Status, b.balance(payee, "USD"), b.balance(org, "USD"))
+	}
+	if _, err := orgs.Reject(org, checker, tx.ID, "too late"); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Errorf("rejecting a completed transfer: %v, want ErrNotAwaitingApproval", err)
+	}
+}
+
+func TestOrgApproveRole(t *testing.T) {
+	for role, want := range map[models.OrgRole]bool{models.OrgOwner: true, models.OrgApprover: true, models.OrgViewer: false} {
+		if got := role.Can(models.OrgApprove); got != want {
+			t.Errorf("%s can approve = %v, want %v", role, got, want)
+		}
+	}
+}
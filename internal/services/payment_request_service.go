@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPaymentRequestNotFound = errors.New("payment request not found")
+	ErrPaymentRequestClosed   = errors.New("payment request is no longer pending")
+	ErrPaymentUnderway        = errors.New("payment request is being paid; its transfer is waiting for a decision")
+)
+
+// PaymentRequestService lets a user ask another user for money. Accepting pays
+// the request with TransferIdem keyed by the request id, so it is paid at most once.
+// When that transfer has to wait for approval or risk review, the request stays
+// pending until the transfer is decided and is paid or rejected with it.
+type PaymentRequestService struct {
+	r     repo.PaymentRequests
+	users repo.Users
+	ts    *TransactionService
+}
+
+func NewPaymentRequestService(r repo.PaymentRequests, u repo.Users, ts *TransactionService) *PaymentRequestService {
+	s := &PaymentRequestService{r: r, users: u, ts: ts}
+	ts.OnDecided(func(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) error {
+		return r.SettlePendingTx(ctx, pgtx, tx.ID, tx.Status)
+	})
+	return s
+}
+
+func (s *PaymentRequestService) Create(p models.PaymentRequest) (models.PaymentRequest, error) {
+	if err := p.Validate(); err != nil {
+		return models.PaymentRequest{}, err
+	}
+	cur, err := currencyCode(p.Currency)
+	if err != nil {
+		return models.PaymentRequest{}, err
+	}
+	p.Currency = cur
+	exists, err := s.users.Exists(context.Background(), p.PayerID)
+	if err != nil {
+		return models.PaymentRequest{}, fmt.Errorf("check payer failed: %w", err)
+	}
+	if !exists {
+		return models.PaymentRequest{}, ErrUserNotFound
+	}
+	return s.r.Create(context.Background(), p)
+}
+
+// Get returns the request if userID is its requester or payer.
+func (s *PaymentRequestService) Get(userID, id string) (models.PaymentRequest, error) {
+	p, err := s.r.GetByID(context.Background(), id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && p.RequesterID != userID && p.PayerID != userID) {
+		return models.PaymentRequest{}, ErrPaymentRequestNotFound
+	}
+	return p, err
+}
+
+func (s *PaymentRequestService) List(userID, direction string, status models.PaymentRequestStatus, limit, offset int) ([]models.PaymentRequest, error) {
+	return s.r.ListByUser(context.Background(), userID, direction, status, limit, offset)
+}
+
+// Accept pays a pending request addressed to userID. Accepting a paid request
+// again returns it unchanged; if a previous attempt moved the money but failed
+// to record it, TransferIdem hands back that transfer and the link is repaired,
+// and likewise a transfer rejected before it was recorded closes the request.
+func (s *PaymentRequestService) Accept(userID, id string) (models.PaymentRequest, error) {
+	p, err := s.Get(userID, id)
+	if err != nil {
+		return models.PaymentRequest{}, err
+	}
+	if p.PayerID != userID {
+		return models.PaymentRequest{}, ErrForbidden
+	}
+	if p.Status == models.RequestPaid {
+		return p, nil
+	}
+	if p.Status != models.RequestPending {
+		return models.PaymentRequest{}, fmt.Errorf("%w: %s", ErrPaymentRequestClosed, p.Status)
+	}
+	desc := "payment request"
+	if p.Note != nil {
+		desc = *p.Note
+	}
+	tx, err := s.ts.TransferIdem(p.PayerID, p.RequesterID, "", p.Amount, p.Currency, p.ID, models.TxnDetails{
+		Description: &desc,
+		Metadata:    map[string]any{"payment_request_id": p.ID},
+	})
+	if err != nil {
+		return models.PaymentRequest{}, err
+	}
+	ctx := context.Background()
+	switch tx.Status {
+	case models.TxnAwaitingApproval, models.TxnHeldForReview:
+		// the request is paid or rejected along with the decision on tx
+		if _, err := s.r.SetPending(ctx, p.ID, tx.ID); err != nil {
+			return models.PaymentRequest{}, err
+		}
+		if tx.Status == models.TxnHeldForReview {
+			return models.PaymentRequest{}, ErrHeldForReview
+		}
+		return models.PaymentRequest{}, ErrAwaitingApproval
+	case models.TxnRejected:
+		if _, err := s.r.Close(ctx, p.ID, models.RequestRejected); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return models.PaymentRequest{}, err
+		}
+		return models.PaymentRequest{}, fmt.Errorf("%w: the payment was rejected", ErrPaymentRequestClosed)
+	}
+	return s.r.MarkPaid(ctx, p.ID, tx.ID)
+}
+
+// Decline is the payer turning a pending request down.
+func (s *PaymentRequestService) Decline(userID, id string) (models.PaymentRequest, error) {
+	return s.close(userID, id, models.RequestDeclined)
+}
+
+// Cancel is the requester withdrawing a pending request.
+func (s *PaymentRequestService) Cancel(userID, id string) (models.PaymentRequest, error) {
+	return s.close(userID, id, models.RequestCancelled)
+}
+
+func (s *PaymentRequestService) close(userID, id string, status models.PaymentRequestStatus) (models.PaymentRequest, error) {
+	p, err := s.Get(userID, id)
+	if err != nil {
+		return models.PaymentRequest{}, err
+	}
+	owner := p.PayerID
+	if status == models.RequestCancelled {
+		owner = p.RequesterID
+	}
+	if owner != userID {
+		return models.PaymentRequest{}, ErrForbidden
+	}
+	if p.Status == status {
+		return p, nil
+	}
+	ctx := context.Background()
+	out, err := s.r.Close(ctx, id, status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if p, err = s.r.GetByID(ctx, id); err != nil {
+			return models.PaymentRequest{}, err
+		}
+		if p.Status == models.RequestPending {
+			// still pending, so its transfer is the reason
+			return models.PaymentRequest{}, ErrPaymentUnderway
+		}
+		return models.PaymentRequest{}, fmt.Errorf("%w: %s", ErrPaymentRequestClosed, p.Status)
+	}
+	return out, err
+}
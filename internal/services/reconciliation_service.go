@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrReconRunNotFound = errors.New("reconciliation run not found")
+
+type ReconciliationService struct {
+	r  repo.Reconciliation
+	ts *TransactionService
+}
+
+func NewReconciliationService(r repo.Reconciliation, ts *TransactionService) *ReconciliationService {
+	return &ReconciliationService{r: r, ts: ts}
+}
+
+// Run compares every balance with its folded transaction history and stores the
+// drifts. With adjust, each drift is corrected by an adjustment transaction that
+// brings the balance back to the expected amount.
+func (s *ReconciliationService) Run(adjust bool) (models.ReconciliationRun, error) {
+	ctx := context.Background()
+	run, err := s.r.StartRun(ctx, adjust)
+	if err != nil {
+		return models.ReconciliationRun{}, err
+	}
+
+	drifts, checked, err := s.r.Drifts(ctx)
+	if err != nil {
+		return s.finish(ctx, run, err)
+	}
+	run.BalancesChecked, run.DriftsFound = checked, len(drifts)
+	for _, d := range drifts {
+		d.RunID = run.ID
+		if adjust {
+			key := fmt.Sprintf("reconcile:%s:%s:%s", run.ID, d.UserID, d.Currency)
+			tx, err := s.ts.Adjust(d.UserID, d.Currency, -d.Drift, "reconciliation run "+run.ID, key)
+			if err != nil {
+				msg := err.Error()
+				d.AdjustmentError = &msg
+			} else {
+				d.AdjustmentTransactionID = &tx.ID
+				run.AdjustmentsMade++
+			}
+		}
+		if _, err := s.r.AddDrift(ctx, d); err != nil {
+			return s.finish(ctx, run, err)
+		}
+	}
+	if len(drifts) > 0 {
+		slog.Warn("reconciliation found drift", "run_id", run.ID, "drifts", len(drifts), "adjusted", run.AdjustmentsMade)
+	}
+	return s.finish(ctx, run, nil)
+}
+
+func (s *ReconciliationService) finish(ctx context.Context, run models.ReconciliationRun, runErr error) (models.ReconciliationRun, error) {
+	run.Status = models.ReconCompleted
+	if runErr != nil {
+		msg := runErr.Error()
+		run.Status, run.Error = models.ReconFailed, &msg
+	}
+	if err := s.r.FinishRun(ctx, run); err != nil {
+		return run, err
+	}
+	return run, runErr
+}
+
+// RunScheduled is Run for the background loop: a run already in progress on
+// another replica is not an error.
+func (s *ReconciliationService) RunScheduled(adjust bool) error {
+	_, err := s.Run(adjust)
+	if errors.Is(err, repo.ErrRunInProgress) {
+		return nil
+	}
+	return err
+}
+
+func (s *ReconciliationService) Runs(limit, offset int) ([]models.ReconciliationRun, error) {
+	return s.r.ListRuns(context.Background(), limit, offset)
+}
+
+// Drifts lists the discrepancies of runID, or of the latest finished run if runID is empty.
+func (s *ReconciliationService) Drifts(runID string, limit, offset int) (models.ReconciliationRun, []models.BalanceDrift, error) {
+	ctx := context.Background()
+	var run models.ReconciliationRun
+	var err error
+	if runID == "" {
+		run, err = s.r.LatestRun(ctx)
+	} else {
+		run, err = s.r.GetRun(ctx, runID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ReconciliationRun{}, nil, ErrReconRunNotFound
+	}
+	if err != nil {
+		return models.ReconciliationRun{}, nil, err
+	}
+	drifts, err := s.r.ListDrifts(ctx, run.ID, limit, offset)
+	return run, drifts, err
+}
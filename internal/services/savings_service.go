@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+// SavingsService pays interest on positive wallet balances. Every complete UTC
+// day accrues once per balance from its end-of-day amount; at the start of each
+// month whatever accrued before it is paid out as one interest transaction.
+type SavingsService struct {
+	r  repo.Savings
+	ts *TransactionService
+}
+
+func NewSavingsService(r repo.Savings, ts *TransactionService) *SavingsService {
+	return &SavingsService{r: r, ts: ts}
+}
+
+// Run accrues the last interestLookback complete days, oldest first so each day
+// starts from the previous day's carry, then pays out the months that ended.
+func (s *SavingsService) Run() error {
+	if err := s.Accrue(); err != nil {
+		return err
+	}
+	return s.Payout()
+}
+
+// Accrue books the missing daily accruals. Days already accrued are skipped, so
+// reruns and concurrent replicas never accrue a day twice.
+func (s *SavingsService) Accrue() error {
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	rates := map[string][]models.InterestTier{}
+	for d := interestLookback; d >= 1; d-- {
+		day := today.AddDate(0, 0, -d)
+		positions, err := s.r.Savers(ctx, day)
+		if err != nil {
+			return err
+		}
+		accrued := 0
+		for _, p := range positions {
+			tiers, ok := rates[p.Currency]
+			if !ok {
+				if tiers, err = s.r.RatesFor(ctx, p.Currency); err != nil {
+					return err
+				}
+				rates[p.Currency] = tiers
+			}
+			// unpaid interest earns too, so the rate compounds daily between payouts
+			amount, carry := models.DailyInterest(tiers, p.Balance+p.Accrued, p.Carry)
+			inserted, err := s.r.Accrue(ctx, models.InterestAccrual{
+				UserID: p.UserID, WalletID: p.WalletID, Currency: p.Currency, Day: day,
+				Balance: p.Balance, Amount: amount, Carry: carry,
+			})
+			if err != nil {
+				slog.Error("interest accrual", "user_id", p.UserID, "wallet_id", p.WalletID, "currency", p.Currency, "day", day.Format("2006-01-02"), "err", err)
+				continue
+			}
+			if inserted {
+				accrued++
+			}
+		}
+		if accrued > 0 {
+			slog.Info("interest accrued", "day", day.Format("2006-01-02"), "balances", accrued)
+		}
+	}
+	return nil
+}
+
+// Payout pays every unpaid accrual dated before the current month. An accrual
+// that arrives late for a month already paid is picked up by the next payout.
+func (s *SavingsService) Payout() error {
+	start := monthStart(time.Now().UTC())
+	period := start.AddDate(0, -1, 0)
+	due, err := s.r.Due(context.Background(), start)
+	if err != nil {
+		return err
+	}
+	paid := 0
+	for _, d := range due {
+		claim := func(ctx context.Context, pgtx pgx.Tx, txID string) (int64, error) {
+			return s.r.ClaimTx(ctx, pgtx, d.WalletID, d.Currency, start, txID)
+		}
+		_, ok, err := s.ts.PayInterest(d.UserID, d.WalletID, d.Currency, period, claim)
+		if err != nil {
+			slog.Error("interest payout", "user_id", d.UserID, "wallet_id", d.WalletID, "currency", d.Currency, "err", err)
+			continue
+		}
+		if ok {
+			paid++
+		}
+	}
+	if paid > 0 {
+		slog.Info("interest paid", "period", period.Format("2006-01"), "balances", paid)
+	}
+	return nil
+}
+
+// Accruals lists userID's daily accruals, newest first.
+func (s *SavingsService) Accruals(userID, cur string, limit, offset int) ([]models.InterestAccrual, error) {
+	if cur != "" {
+		var err error
+		if cur, err = currencyCode(cur); err != nil {
+			return nil, err
+		}
+	}
+	return s.r.ListAccruals(context.Background(), userID, cur, limit, offset)
+}
+
+//  admin
+
+func (s *SavingsService) Rates() ([]models.InterestTier, error) {
+	return s.r.ListRates(context.Background())
+}
+
+// SetRates replaces the bands of cur. New rates apply to days not accrued yet;
+// an empty list stops accrual in cur.
+func (s *SavingsService) SetRates(cur string, tiers []models.InterestTier) ([]models.InterestTier, error) {
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateInterestTiers(tiers); err != nil {
+		return nil, err
+	}
+	if len(tiers) > 100 {
+		return nil, errors.New("at most 100 tiers")
+	}
+	return s.r.ReplaceRates(context.Background(), cur, tiers)
+}
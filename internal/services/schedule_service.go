@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+const scheduleLease = 5 * time.Minute
+
+type ScheduleService struct {
+	r     repo.Schedules
+	users repo.Users
+	ts    *TransactionService
+}
+
+func NewScheduleService(r repo.Schedules, u repo.Users, ts *TransactionService) *ScheduleService {
+	return &ScheduleService{r: r, users: u, ts: ts}
+}
+
+// SchedulePatch holds the fields an owner may change; nil means unchanged.
+type SchedulePatch struct {
+	Amount *int64
+	EndAt  *time.Time
+	Status *models.ScheduleStatus // active | paused
+}
+
+func (s *ScheduleService) Create(sc models.Schedule) (models.Schedule, error) {
+	if sc.ToUserID == sc.UserID {
+		return models.Schedule{}, errors.New("cannot transfer to self")
+	}
+	if err := sc.Validate(); err != nil {
+		return models.Schedule{}, err
+	}
+	cur, err := currencyCode(sc.Currency)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	sc.Currency = cur
+	exists, err := s.users.Exists(context.Background(), sc.ToUserID)
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("check recipient failed: %w", err)
+	}
+	if !exists {
+		return models.Schedule{}, ErrRecipientNotFound
+	}
+	first, ok := sc.FirstOccurrence()
+	if !ok {
+		return models.Schedule{}, errors.New("schedule has no occurrence")
+	}
+	sc.NextRunAt = &first
+	sc.Status = models.ScheduleActive
+	return s.r.Create(sc)
+}
+
+// Get returns the schedule if userID owns it.
+func (s *ScheduleService) Get(userID, id string) (models.Schedule, error) {
+	sc, err := s.r.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && sc.UserID != userID) {
+		return models.Schedule{}, ErrScheduleNotFound
+	}
+	return sc, err
+}
+
+func (s *ScheduleService) List(userID string, limit, offset int) ([]models.Schedule, error) {
+	return s.r.ListByUser(userID, limit, offset)
+}
+
+func (s *ScheduleService) Update(userID, id string, p SchedulePatch) (models.Schedule, error) {
+	sc, err := s.Get(userID, id)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	if sc.Status != models.ScheduleActive && sc.Status != models.SchedulePaused {
+		return models.Schedule{}, fmt.Errorf("schedule is %s", sc.Status)
+	}
+	if p.Amount != nil {
+		sc.Amount = *p.Amount
+	}
+	if p.EndAt != nil {
+		sc.EndAt = p.EndAt
+	}
+	if err := sc.Validate(); err != nil {
+		return models.Schedule{}, err
+	}
+	if p.Status != nil {
+		switch *p.Status {
+		case models.SchedulePaused:
+			sc.Status = models.SchedulePaused
+		case models.ScheduleActive:
+			// occurrences missed while paused are skipped, not paid in a burst
+			if sc.Status == models.SchedulePaused && sc.NextRunAt != nil && sc.NextRunAt.Before(time.Now()) {
+				next, ok := sc.OccurrenceAfter(time.Now())
+				if !ok {
+					return models.Schedule{}, errors.New("schedule has no further occurrence")
+				}
+				sc.NextRunAt, sc.NextAttemptAt, sc.Attempt = &next, &next, 0
+			}
+			sc.Status = models.ScheduleActive
+		default:
+			return models.Schedule{}, errors.New("status must be active or paused")
+		}
+	}
+	if sc.NextRunAt != nil && sc.EndAt != nil && sc.NextRunAt.After(*sc.EndAt) {
+		sc.Status, sc.NextRunAt, sc.NextAttemptAt = models.ScheduleCompleted, nil, nil
+	}
+	return s.r.Save(context.Background(), sc)
+}
+
+func (s *ScheduleService) Cancel(userID, id string) (models.Schedule, error) {
+	sc, err := s.Get(userID, id)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	if sc.Status == models.ScheduleCompleted || sc.Status == models.ScheduleCancelled {
+		return sc, nil
+	}
+	sc.Status, sc.NextRunAt, sc.NextAttemptAt = models.ScheduleCancelled, nil, nil
+	return s.r.Save(context.Background(), sc)
+}
+
+func (s *ScheduleService) Runs(userID, id string, limit, offset int) ([]models.ScheduleRun, error) {
+	if _, err := s.Get(userID, id); err != nil {
+		return nil, err
+	}
+	return s.r.ListRuns(id, limit, offset)
+}
+
+// RunDue executes every due occurrence. Schedules are leased, so several replicas may call it.
+func (s *ScheduleService) RunDue() error {
+	ctx := context.Background()
+	for {
+		due, err := s.r.ClaimDue(ctx, 20, scheduleLease)
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		for _, sc := range due {
+			if err := s.runOccurrence(ctx, sc); err != nil {
+				slog.Error("schedule run", "schedule_id", sc.ID, "err", err)
+			}
+		}
+	}
+}
+
+// runOccurrence makes one attempt at sc.NextRunAt. The idempotency key is derived from
+// the occurrence, so an occurrence that already paid (e.g. crash before Save) is not paid again.
+func (s *ScheduleService) runOccurrence(ctx context.Context, sc models.Schedule) error {
+	occ := *sc.NextRunAt
+	key := fmt.Sprintf("schedule:%s:%d", sc.ID, occ.Unix())
+	run := models.ScheduleRun{ScheduleID: sc.ID, OccurrenceAt: occ, Attempt: sc.Attempt + 1}
+
+	desc := "standing order " + sc.ID
+	tx, err := s.ts.TransferIdem(sc.UserID, sc.ToUserID, "", sc.Amount, sc.Currency, key, models.TxnDetails{Description: &desc})
+	if err == nil && tx.Status != models.TxnCompleted {
+		err = fmt.Errorf("transfer %s is %s", tx.ID, tx.Status)
+	}
+	if err == nil {
+		run.Status, run.TransactionID = models.RunSucceeded, &tx.ID
+		s.advance(&sc, occ)
+	} else {
+		msg := err.Error()
+		run.Status, run.Error = models.RunFailed, &msg
+		if sc.Attempt < sc.MaxRetries {
+			retryAt := time.Now().Add(time.Duration(sc.RetryInterval) * time.Second)
+			sc.Attempt++
+			sc.NextAttemptAt = &retryAt
+		} else {
+			// retries exhausted: give up on this occurrence, keep the standing order
+			s.advance(&sc, occ)
+		}
+	}
+
+	if err := s.r.AddRun(ctx, run); err != nil {
+		return err
+	}
+	_, err = s.r.Save(ctx, sc)
+	return err
+}
+
+func (s *ScheduleService) advance(sc *models.Schedule, occ time.Time) {
+	sc.Attempt = 0
+	next, ok := sc.OccurrenceAfter(occ)
+	if !ok {
+		sc.Status, sc.NextRunAt, sc.NextAttemptAt = models.ScheduleCompleted, nil, nil
+		return
+	}
+	sc.NextRunAt, sc.NextAttemptAt = &next, &next
+}
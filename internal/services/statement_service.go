@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/baharkarakas/insider-backend/internal/statement"
+)
+
+var ErrInvalidPeriod = errors.New("from must be before to")
+
+// closeGrace is how long after month end a month is closed, so transactions
+// still committing at midnight are booked before the snapshot.
+const closeGrace = time.Hour
+
+type StatementService struct {
+	trx repo.Transactions
+	st  repo.Statements
+}
+
+func NewStatementService(trx repo.Transactions, st repo.Statements) *StatementService {
+	return &StatementService{trx: trx, st: st}
+}
+
+// Export streams userID's cur statement for [from, to) into sw. Nothing is written
+// before the balances are read, so an error with nothing written can still be
+// reported to the caller cleanly. ctx cancels the export, e.g. when the client leaves.
+func (s *StatementService) Export(ctx context.Context, userID, cur string, from, to time.Time, sw statement.Writer) error {
+	c, err := currency.Lookup(cur)
+	if err != nil {
+		return err
+	}
+	if !from.Before(to) {
+		return ErrInvalidPeriod
+	}
+	err = s.trx.Statement(ctx, userID, c.Code, from, to,
+		func(opening, closing int64) error {
+			return sw.Begin(statement.Header{
+				AccountID:   userID,
+				Currency:    c,
+				From:        from,
+				To:          to,
+				Opening:     opening,
+				Closing:     closing,
+				GeneratedAt: time.Now().UTC(),
+			})
+		},
+		func(l models.StatementLine) error { return sw.Line(l) },
+	)
+	if err != nil {
+		return err
+	}
+	return sw.End()
+}
+
+// CloseMonths writes the statements of every calendar month that ended at least
+// closeGrace ago and isn't closed yet, oldest first. Safe to run on every replica.
+func (s *StatementService) CloseMonths() error {
+	ctx := context.Background()
+	start, ok, err := s.st.LastClosed(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		start = start.AddDate(0, 1, 0)
+	} else {
+		first, ok, err := s.st.FirstActivity(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		start = monthStart(first)
+	}
+	now := time.Now().UTC()
+	for end := start.AddDate(0, 1, 0); !now.Before(end.Add(closeGrace)); start, end = end, end.AddDate(0, 1, 0) {
+		n, err := s.st.CloseMonth(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		slog.Info("statements closed", "period", start.Format("2006-01"), "written", n)
+	}
+	return nil
+}
+
+// Monthly lists userID's closed statements, newest first; cur may be empty for all currencies.
+func (s *StatementService) Monthly(userID, cur string, limit, offset int) ([]models.MonthlyStatement, error) {
+	if cur != "" {
+		c, err := currencyCode(cur)
+		if err != nil {
+			return nil, err
+		}
+		cur = c
+	}
+	return s.st.ListByUser(context.Background(), userID, cur, limit, offset)
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
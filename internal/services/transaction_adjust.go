@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Adjust books a correction of delta minor units onto userID's cur balance against
+// the system adjustment account. A positive delta raises the balance. idemKey
+// makes a repeated correction a no-op.
+func (s *TransactionService) Adjust(userID, cur string, delta int64, reason, idemKey string) (models.Transaction, error) {
+	if delta == 0 {
+		return models.Transaction{}, errors.New("adjustment must not be zero")
+	}
+	if tx, ok := s.existing(idemKey); ok {
+		return tx, nil
+	}
+	if err := s.getOrCreateBalance(userID, cur); err != nil {
+		return models.Transaction{}, err
+	}
+
+	tx := models.Transaction{
+		Amount:   delta,
+		Currency: cur,
+		Type:     models.TxnAdjustment,
+		Status:   models.TxnCompleted,
+		ToUserID: &userID,
+	}
+	debit := models.SystemAccountCode(models.AccountAdjustment, cur)
+	credit := models.UserAccountCode(userID, cur)
+	if delta < 0 {
+		tx.Amount, tx.ToUserID, tx.FromUserID = -delta, nil, &userID
+		debit, credit = credit, debit
+	}
+	if idemKey != "" {
+		tx.IdempotencyKey = &idemKey
+	}
+
+	ctx := context.Background()
+	var created models.Transaction
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if created, err = s.trx.CreateTx(ctx, pgtx, tx); err != nil {
+			return err
+		}
+		_, err = s.ledger.Post(ctx, pgtx, models.NewTransferEntry(created.ID, "adjustment", debit, credit, created.Amount))
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	s.auditDetails(created.ID, "created", map[string]any{"message": "adjustment created", "reason": reason})
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnAdjustment)).Inc()
+	return created, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotAwaitingApproval = errors.New("transaction is not awaiting approval")
+	ErrSelfApproval        = errors.New("a transfer cannot be approved by the user who made it")
+	ErrAwaitingApproval    = errors.New("transfer is awaiting approval")
+
+	// errNotInStatus reports that a transaction left the expected status under us.
+	errNotInStatus = errors.New("transaction status changed concurrently")
+)
+
+func (s *TransactionService) needsApproval(amount int64) bool {
+	return s.approvalThreshold > 0 && amount > s.approvalThreshold
+}
+
+// requestApproval records a transfer above the approval threshold without moving
+// any money. Limits have been checked and count it from now on; balance and fee
+// are only looked at when an admin approves it. actorID is the user who made it.
+func (s *TransactionService) requestApproval(actorID, fromID, toID string, toWallet *string, amount int64, cur, idemKey string, d models.TxnDetails) (models.Transaction, error) {
+	txModel := models.Transaction{
+		Amount:     amount,
+		Currency:   cur,
+		Type:       models.TxnTransfer,
+		Status:     models.TxnAwaitingApproval,
+		FromUserID: &fromID,
+		ToUserID:   &toID,
+		ToWalletID: toWallet,
+		CreatedBy:  &actorID,
+		TxnDetails: d,
+	}
+	if idemKey != "" {
+		txModel.IdempotencyKey = &idemKey
+	}
+	created, err := s.trx.Create(txModel)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	s.auditDetails(created.ID, "created", map[string]any{"message": "transfer awaiting approval", "threshold": s.approvalThreshold})
+	metrics.TransactionsTotal.WithLabelValues("transfer_awaiting_approval").Inc()
+	return created, nil
+}
+
+// OnDecided registers f to run inside the DB transaction in which an admin's
+// decision, on approval or in risk review, completes or rejects a transfer that
+// was waiting for it. f sees the transfer with its new status; an error undoes
+// the decision. Register before serving requests.
+func (s *TransactionService) OnDecided(f func(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) error) {
+	s.decided = append(s.decided, f)
+}
+
+func (s *TransactionService) runDecided(ctx context.Context, pgtx pgx.Tx, tx models.Transaction, status models.TransactionStatus) error {
+	tx.Status = status
+	for _, f := range s.decided {
+		if err := f(ctx, pgtx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingApprovals lists the transfers waiting for a decision, oldest first.
+func (s *TransactionService) PendingApprovals(limit, offset int) ([]models.Transaction, error) {
+	return s.trx.ListByStatus(context.Background(), models.TxnAwaitingApproval, limit, offset)
+}
+
+// awaitingApproval loads a transfer someone is about to decide on.
+func (s *TransactionService) awaitingApproval(txID string) (models.Transaction, error) {
+	tx, err := s.trx.GetByID(txID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Transaction{}, ErrTransactionNotFound
+	}
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if tx.Status != models.TxnAwaitingApproval {
+		return models.Transaction{}, ErrNotAwaitingApproval
+	}
+	return tx, nil
+}
+
+// Approve executes a transfer awaiting approval as actorID, who must not be the
+// user who made it: its creator, or its sender on transfers made before creators
+// were recorded. The balance check and fee happen now; a transfer that can
+// no longer be paid is rolled back. Admins approve any transfer, organization
+// owners and approvers their organization's (see OrgService.Approve).
+func (s *TransactionService) Approve(txID, actorID string) (models.Transaction, error) {
+	tx, err := s.awaitingApproval(txID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	maker := tx.CreatedBy
+	if maker == nil {
+		maker = tx.FromUserID
+	}
+	if maker != nil && *maker == actorID {
+		s.auditDetails(tx.ID, "approval_denied", map[string]any{"message": ErrSelfApproval.Error(), "actor_id": actorID})
+		return models.Transaction{}, ErrSelfApproval
+	}
+	if err := s.checkTxnAccounts(tx); err != nil {
+		return models.Transaction{}, err
+	}
+	quote, err := s.fees.Quote(*tx.FromUserID, models.TxnTransfer, tx.Amount, tx.Currency)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	out, err := s.applyTransfer(tx, models.TxnAwaitingApproval, quote.Fee, func(ctx context.Context, pgtx pgx.Tx) error {
+		return s.runDecided(ctx, pgtx, tx, models.TxnCompleted)
+	})
+	if errors.Is(err, errNotInStatus) {
+		return models.Transaction{}, ErrNotAwaitingApproval
+	}
+	det := map[string]any{"message": "transfer approved", "actor_id": actorID}
+	if err != nil {
+		det["error"] = err.Error()
+	}
+	s.auditDetails(tx.ID, "approved", det)
+	return out, err
+}
+
+// Reject turns down a transfer awaiting approval; nothing was moved for it.
+// Admins or, for an organization's transfer, its owners and approvers.
+func (s *TransactionService) Reject(txID, actorID, reason string) (models.Transaction, error) {
+	tx, err := s.awaitingApproval(txID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	err = s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error {
+		ok, err := s.trx.TransitionTx(context.Background(), pgtx, tx.ID, models.TxnAwaitingApproval, models.TxnRejected)
+		if err == nil && !ok {
+			return ErrNotAwaitingApproval
+		}
+		if err != nil {
+			return err
+		}
+		return s.runDecided(context.Background(), pgtx, tx, models.TxnRejected)
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	tx.Status = models.TxnRejected
+	s.auditDetails(tx.ID, "rejected", map[string]any{"message": fmt.Sprintf("%s: %s", models.TxnRejected, reason), "actor_id": actorID, "reason": reason})
+	return tx, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const MaxBatchLegs = 500
+
+var (
+	ErrBatchFailed   = errors.New("batch failed; no leg was applied")
+	ErrBatchNotFound = errors.New("batch not found")
+)
+
+// legError stops an atomic batch at the leg that could not be applied.
+type legError struct {
+	index int
+	err   error
+}
+
+func (e *legError) Error() string { return fmt.Sprintf("leg %d: %v", e.index, e.err) }
+func (e *legError) Unwrap() error { return e.err }
+
+// TransferBatch pays every leg from fromID in a single DB transaction.
+// Atomic batches apply all legs or none; best-effort batches run each leg in a
+// savepoint so a leg without funds or recipient fails alone. The batch and the
+// outcome of every leg are stored either way. A failed atomic batch is returned
+// together with an error wrapping ErrBatchFailed.
+func (s *TransactionService) TransferBatch(fromID, cur string, mode models.BatchMode, legs []models.BatchLeg) (models.Batch, error) {
+	if mode == "" {
+		mode = models.BatchAtomic
+	}
+	if mode != models.BatchAtomic && mode != models.BatchBestEffort {
+		return models.Batch{}, errors.New("mode must be atomic or best_effort")
+	}
+	if len(legs) == 0 || len(legs) > MaxBatchLegs {
+		return models.Batch{}, fmt.Errorf("a batch needs 1 to %d legs", MaxBatchLegs)
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	b := models.Batch{ID: uuid.NewString(), UserID: fromID, Currency: cur, Mode: mode}
+	for i, l := range legs {
+		if l.Amount <= 0 {
+			return models.Batch{}, fmt.Errorf("legs[%d]: amount must be > 0", i)
+		}
+		if l.ToUserID == fromID {
+			return models.Batch{}, fmt.Errorf("legs[%d]: cannot transfer to self", i)
+		}
+		if s.needsApproval(l.Amount) {
+			return models.Batch{}, fmt.Errorf("legs[%d]: amounts above %d need approval; send them as single transfers", i, s.approvalThreshold)
+		}
+		b.TotalAmount += l.Amount
+	}
+
+	if err := s.checkAccounts(fromID, ""); err != nil {
+		return models.Batch{}, err
+	}
+	ctx := context.Background()
+	amounts := make([]int64, len(legs))
+	for i, l := range legs {
+		amounts[i] = l.Amount
+	}
+	if err := s.limits.Check(ctx, fromID, models.TxnTransfer, cur, amounts...); err != nil {
+		return models.Batch{}, err
+	}
+	// a batch can't wait in the review queue, so a flagged leg fails it up front
+	for i, l := range legs {
+		to := l.ToUserID
+		res := s.screen(models.RiskCheck{UserID: fromID, Type: models.TxnTransfer, ToUserID: &to, Amount: l.Amount, Currency: cur})
+		if res.Decision != models.RiskAllow {
+			return models.Batch{}, fmt.Errorf("legs[%d]: %w", i, ErrRiskFlagged)
+		}
+	}
+	known := make(map[string]bool, len(legs))
+	for i, l := range legs {
+		if _, seen := known[l.ToUserID]; seen {
+			continue
+		}
+		exists, err := s.users.Exists(ctx, l.ToUserID)
+		if err != nil {
+			return models.Batch{}, fmt.Errorf("check recipient failed: %w", err)
+		}
+		known[l.ToUserID] = exists
+		if exists {
+			if err := s.checkAccounts("", l.ToUserID); err != nil {
+				return models.Batch{}, fmt.Errorf("legs[%d]: %w", i, err)
+			}
+			if err := s.getOrCreateBalance(l.ToUserID, cur); err != nil {
+				return models.Batch{}, err
+			}
+		}
+	}
+	if err := s.getOrCreateBalance(fromID, cur); err != nil {
+		return models.Batch{}, err
+	}
+	fee, hasFee, err := s.fees.rule(ctx, fromID, models.TxnTransfer, cur)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	feeFor := func(amount int64) int64 {
+		if !hasFee {
+			return 0
+		}
+		return fee.Compute(amount)
+	}
+
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		if err := s.limits.CheckTx(ctx, pgtx, fromID, models.TxnTransfer, cur, "", amounts...); err != nil {
+			return err
+		}
+		// rebuilt on every attempt: WithTx may retry on serialization failures
+		b.Legs = make([]models.BatchLeg, len(legs))
+		for i, l := range legs {
+			leg := models.BatchLeg{Index: i, ToUserID: l.ToUserID, Amount: l.Amount}
+			var legErr error
+			if !known[l.ToUserID] {
+				legErr = ErrRecipientNotFound
+			} else if mode == models.BatchAtomic {
+				leg.TransactionID, legErr = s.batchLeg(ctx, pgtx, b, leg, feeFor(l.Amount))
+			} else {
+				leg.TransactionID, legErr = s.batchLegSavepoint(ctx, pgtx, b, leg, feeFor(l.Amount))
+			}
+			if legErr != nil {
+				if mode == models.BatchAtomic || !isLegFailure(legErr) {
+					return &legError{index: i, err: legErr}
+				}
+				msg := legErr.Error()
+				leg.Status, leg.Error = models.LegFailed, &msg
+			} else {
+				leg.Status = models.LegSucceeded
+			}
+			b.Legs[i] = leg
+		}
+		b.Status = batchStatus(b.Legs)
+		var err error
+		b, err = s.batch.CreateTx(ctx, pgtx, b)
+		return err
+	})
+
+	var le *legError
+	if errors.As(err, &le) && mode == models.BatchAtomic && isLegFailure(le.err) {
+		return s.failAtomicBatch(ctx, b, legs, le)
+	}
+	if err != nil {
+		return models.Batch{}, err
+	}
+
+	for _, l := range b.Legs {
+		if l.TransactionID != nil {
+			s.auditDetails(*l.TransactionID, "created", map[string]any{"message": "batch transfer", "batch_id": b.ID, "leg": l.Index})
+			metrics.TransactionsTotal.WithLabelValues(string(models.TxnTransfer)).Inc()
+		}
+	}
+	return b, nil
+}
+
+// batchLeg applies one leg and its fee inside pgtx.
+func (s *TransactionService) batchLeg(ctx context.Context, pgtx pgx.Tx, b models.Batch, leg models.BatchLeg, fee int64) (*string, error) {
+	tx, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+		Amount:     leg.Amount,
+		Currency:   b.Currency,
+		Type:       models.TxnTransfer,
+		Status:     models.TxnCompleted,
+		FromUserID: &b.UserID,
+		ToUserID:   &leg.ToUserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	debit, credit := entryCodes(tx)
+	if _, err := s.ledger.Post(ctx, pgtx, models.NewTransferEntry(tx.ID, "batch transfer", debit, credit, leg.Amount)); err != nil {
+		return nil, err
+	}
+	if fee > 0 {
+		if _, err := s.chargeFee(ctx, pgtx, b.UserID, fee, tx); err != nil {
+			return nil, err
+		}
+	}
+	return &tx.ID, nil
+}
+
+// batchLegSavepoint applies one leg in a savepoint and rolls back only that leg on failure.
+func (s *TransactionService) batchLegSavepoint(ctx context.Context, pgtx pgx.Tx, b models.Batch, leg models.BatchLeg, fee int64) (*string, error) {
+	sp, err := pgtx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.batchLeg(ctx, sp, b, leg, fee)
+	if err != nil {
+		_ = sp.Rollback(ctx)
+		return nil, err
+	}
+	return id, sp.Commit(ctx)
+}
+
+// failAtomicBatch records an atomic batch that was rolled back: the offending leg
+// failed, the rest were skipped.
+func (s *TransactionService) failAtomicBatch(ctx context.Context, b models.Batch, legs []models.BatchLeg, le *legError) (models.Batch, error) {
+	b.Status = models.BatchFailed
+	b.Legs = make([]models.BatchLeg, len(legs))
+	for i, l := range legs {
+		b.Legs[i] = models.BatchLeg{Index: i, ToUserID: l.ToUserID, Amount: l.Amount, Status: models.LegSkipped}
+	}
+	msg := le.err.Error()
+	b.Legs[le.index].Status, b.Legs[le.index].Error = models.LegFailed, &msg
+
+	saved, err := s.batch.Create(ctx, b)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	metrics.TransactionsFailed.Inc()
+	return saved, fmt.Errorf("%w: %v", ErrBatchFailed, le)
+}
+
+// isLegFailure reports errors that are a property of the leg rather than of the
+// database; anything else aborts the whole batch so it can be retried.
+func isLegFailure(err error) bool {
+	return errors.Is(err, repo.ErrInsufficientFunds) || errors.Is(err, ErrRecipientNotFound)
+}
+
+func batchStatus(legs []models.BatchLeg) models.BatchStatus {
+	ok := 0
+	for _, l := range legs {
+		if l.Status == models.LegSucceeded {
+			ok++
+		}
+	}
+	switch ok {
+	case len(legs):
+		return models.BatchCompleted
+	case 0:
+		return models.BatchFailed
+	}
+	return models.BatchPartial
+}
+
+// GetBatch returns the batch if userID created it.
+func (s *TransactionService) GetBatch(userID, id string) (models.Batch, error) {
+	b, err := s.batch.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && b.UserID != userID) {
+		return models.Batch{}, ErrBatchNotFound
+	}
+	return b, err
+}
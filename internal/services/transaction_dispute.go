@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrDisputeNotFound = errors.New("dispute not found")
+	ErrNotDisputable   = errors.New("only completed transfers can be disputed")
+	ErrDisputeClosed   = errors.New("dispute can no longer change this way")
+	ErrDisputeActive   = errors.New("transaction has an active dispute")
+	ErrDisputeTooLate  = errors.New("transfers can only be disputed within the dispute window")
+)
+
+// DisputeWindow is how long after a transfer its sender may dispute it.
+const DisputeWindow = 120 * 24 * time.Hour
+
+func (s *TransactionService) auditDispute(d models.Dispute, action string, det map[string]any) {
+	_ = s.log.Create(models.AuditLog{
+		EntityType: "dispute",
+		EntityID:   &d.ID,
+		Action:     action,
+		Details:    det,
+	})
+}
+
+// checkNotDisputed keeps reversals and refunds away from a transfer whose
+// dispute is still open; the dispute settles it instead.
+func (s *TransactionService) checkNotDisputed(ctx context.Context, pgtx pgx.Tx, txID string) error {
+	active, err := s.disputes.ActiveForTransactionTx(ctx, pgtx, txID)
+	if err != nil {
+		return err
+	}
+	if active {
+		return ErrDisputeActive
+	}
+	return nil
+}
+
+// OpenDispute lets the sender of a completed transfer contest it. What is left of
+// the transfer after refunds is disputed, and as much of it as the recipient
+// still has available is held on the wallet that received it.
+func (s *TransactionService) OpenDispute(txID, actorID string, c models.DisputeClaim) (models.Dispute, error) {
+	if err := c.Validate(); err != nil {
+		return models.Dispute{}, err
+	}
+	ctx := context.Background()
+	var d models.Dispute
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		orig, err := s.lockOriginal(ctx, pgtx, txID)
+		if err != nil {
+			return err
+		}
+		if orig.FromUserID == nil || *orig.FromUserID != actorID {
+			return ErrTransactionNotFound
+		}
+		if orig.Type != models.TxnTransfer || orig.Status != models.TxnCompleted {
+			return ErrNotDisputable
+		}
+		if time.Since(orig.CreatedAt) > DisputeWindow {
+			return ErrDisputeTooLate
+		}
+		refunded, err := s.trx.SumChildrenTx(ctx, pgtx, orig.ID, models.TxnRefund)
+		if err != nil {
+			return err
+		}
+		wallet := walletKey(*orig.ToUserID, orig.ToWalletID)
+		held, err := s.bal.HoldUpToTx(ctx, pgtx, wallet, orig.Currency, orig.Amount-refunded)
+		if err != nil {
+			return err
+		}
+		d, err = s.disputes.CreateTx(ctx, pgtx, models.Dispute{
+			TransactionID: orig.ID,
+			SenderID:      actorID,
+			RecipientID:   *orig.ToUserID,
+			HoldWalletID:  wallet,
+			Amount:        orig.Amount - refunded,
+			HeldAmount:    held,
+			Currency:      orig.Currency,
+			Reason:        c.Reason,
+			Evidence:      c.Evidence,
+		})
+		return err
+	})
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	s.auditDispute(d, "opened", map[string]any{
+		"transaction_id": d.TransactionID, "actor_id": actorID, "reason": d.Reason,
+		"amount": d.Amount, "held_amount": d.HeldAmount,
+	})
+	s.auditDetails(d.TransactionID, "disputed", map[string]any{"dispute_id": d.ID, "actor_id": actorID})
+	return d, nil
+}
+
+// Dispute returns a dispute to either of its parties.
+func (s *TransactionService) Dispute(id, userID string) (models.Dispute, error) {
+	d, err := s.DisputeByID(id)
+	if err == nil && d.SenderID != userID && d.RecipientID != userID {
+		return models.Dispute{}, ErrDisputeNotFound
+	}
+	return d, err
+}
+
+// DisputeByID returns any dispute. Admin only; enforced by the router.
+func (s *TransactionService) DisputeByID(id string) (models.Dispute, error) {
+	d, err := s.disputes.GetByID(context.Background(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Dispute{}, ErrDisputeNotFound
+	}
+	return d, err
+}
+
+// Disputes lists the disputes userID filed or received.
+func (s *TransactionService) Disputes(userID string, limit, offset int) ([]models.Dispute, error) {
+	return s.disputes.ListByUser(context.Background(), userID, limit, offset)
+}
+
+// DisputeQueue lists disputes in any of statuses, or all; admin only.
+func (s *TransactionService) DisputeQueue(statuses []models.DisputeStatus, limit, offset int) ([]models.Dispute, error) {
+	return s.disputes.List(context.Background(), statuses, limit, offset)
+}
+
+// moveDispute locks the disputed transfer and then the dispute, in the same order
+// as OpenDispute, and hands both to change if the dispute may become to. change
+// runs in the same DB transaction and returns the dispute to save. A non-empty
+// partyID must be the sender or recipient; admins pass "".
+func (s *TransactionService) moveDispute(id, partyID string, to models.DisputeStatus,
+	change func(ctx context.Context, pgtx pgx.Tx, orig models.Transaction, d models.Dispute) (models.Dispute, error)) (models.Dispute, error) {
+	var d models.Dispute
+	var err error
+	if partyID == "" {
+		d, err = s.DisputeByID(id)
+	} else {
+		d, err = s.Dispute(id, partyID)
+	}
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	ctx := context.Background()
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		orig, err := s.lockOriginal(ctx, pgtx, d.TransactionID)
+		if err != nil {
+			return err
+		}
+		if d, err = s.disputes.GetForUpdateTx(ctx, pgtx, id); err != nil {
+			return err
+		}
+		if !d.Status.CanBecome(to) {
+			return ErrDisputeClosed
+		}
+		if d, err = change(ctx, pgtx, orig, d); err != nil {
+			return err
+		}
+		d.Status = to
+		d, err = s.disputes.SaveTx(ctx, pgtx, d)
+		return err
+	})
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	return d, nil
+}
+
+// RespondDispute records the recipient's side of an open dispute.
+func (s *TransactionService) RespondDispute(id, actorID, response string) (models.Dispute, error) {
+	if err := models.ValidateDisputeText("response", response); err != nil {
+		return models.Dispute{}, err
+	}
+	d, err := s.moveDispute(id, actorID, models.DisputeResponded, func(_ context.Context, _ pgx.Tx, _ models.Transaction, d models.Dispute) (models.Dispute, error) {
+		if d.RecipientID != actorID {
+			return d, ErrForbidden
+		}
+		d.Response = &response
+		return d, nil
+	})
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	s.auditDispute(d, "responded", map[string]any{"actor_id": actorID})
+	return d, nil
+}
+
+// WithdrawDispute lets the sender drop their dispute; the hold is released.
+func (s *TransactionService) WithdrawDispute(id, actorID string) (models.Dispute, error) {
+	d, err := s.moveDispute(id, actorID, models.DisputeWithdrawn, func(ctx context.Context, pgtx pgx.Tx, _ models.Transaction, d models.Dispute) (models.Dispute, error) {
+		if d.SenderID != actorID {
+			return d, ErrForbidden
+		}
+		return d, s.bal.ReleaseTx(ctx, pgtx, d.HoldWalletID, d.Currency, d.HeldAmount)
+	})
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	s.auditDispute(d, "withdrawn", map[string]any{"actor_id": actorID, "released": d.HeldAmount})
+	return d, nil
+}
+
+// ResolveDispute closes an open or responded dispute for one side. For the
+// sender the hold is released and the disputed amount is charged back to them,
+// even when that takes the recipient below zero; for the recipient only the hold
+// is released. Admin only; enforced by the router.
+func (s *TransactionService) ResolveDispute(id, actorID string, outcome models.DisputeOutcome, note string) (models.Dispute, error) {
+	if err := models.ValidateDisputeText("note", note); err != nil {
+		return models.Dispute{}, err
+	}
+	to := models.DisputeRejected
+	switch outcome {
+	case models.RuleForSender:
+		to = models.DisputeChargedBack
+	case models.RuleForRecipient:
+	default:
+		return models.Dispute{}, errors.New("outcome must be sender or recipient")
+	}
+	var chargeback models.Transaction
+	d, err := s.moveDispute(id, "", to, func(ctx context.Context, pgtx pgx.Tx, orig models.Transaction, d models.Dispute) (models.Dispute, error) {
+		if err := s.bal.ReleaseTx(ctx, pgtx, d.HoldWalletID, d.Currency, d.HeldAmount); err != nil {
+			return d, err
+		}
+		d.ResolvedBy, d.ResolutionNote = &actorID, &note
+		if to != models.DisputeChargedBack {
+			return d, nil
+		}
+		var err error
+		if chargeback, err = s.chargeBack(ctx, pgtx, orig, d); err != nil {
+			return d, err
+		}
+		d.ChargebackID = &chargeback.ID
+		return d, nil
+	})
+	if err != nil {
+		return models.Dispute{}, err
+	}
+	s.auditDispute(d, "resolved", map[string]any{
+		"outcome": outcome, "status": d.Status, "actor_id": actorID, "note": note, "released": d.HeldAmount,
+	})
+	if d.ChargebackID != nil {
+		s.auditDetails(chargeback.ID, "created", map[string]any{"message": "chargeback created", "parent_id": d.TransactionID, "dispute_id": d.ID, "actor_id": actorID})
+		s.auditDetails(d.TransactionID, "status_change", map[string]any{"message": "charged back", "chargeback_id": chargeback.ID, "dispute_id": d.ID, "actor_id": actorID})
+		metrics.TransactionsTotal.WithLabelValues(string(models.TxnChargeback)).Inc()
+	}
+	return d, nil
+}
+
+// chargeBack returns the disputed amount to the sender and marks the transfer
+// reversed. orig is locked by moveDispute and, since reversals and refunds wait
+// for the dispute, still completed.
+func (s *TransactionService) chargeBack(ctx context.Context, pgtx pgx.Tx, orig models.Transaction, d models.Dispute) (models.Transaction, error) {
+	created, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+		Amount:       d.Amount,
+		Currency:     orig.Currency,
+		Type:         models.TxnChargeback,
+		Status:       models.TxnCompleted,
+		FromUserID:   orig.ToUserID,
+		ToUserID:     orig.FromUserID,
+		FromWalletID: orig.ToWalletID,
+		ToWalletID:   orig.FromWalletID,
+		ParentID:     &orig.ID,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	debit, credit := entryCodes(created)
+	entry := models.NewTransferEntry(created.ID, "chargeback", debit, credit, d.Amount)
+	// the recipient may have spent the money since; the debt is theirs
+	entry.Unguarded = true
+	if _, err := s.ledger.Post(ctx, pgtx, entry); err != nil {
+		return models.Transaction{}, err
+	}
+	_, err = s.trx.TransitionTx(ctx, pgtx, orig.ID, models.TxnCompleted, models.TxnReversed)
+	return created, err
+}
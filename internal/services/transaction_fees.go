@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// chargeFee books fee from payerID to the revenue account inside pgtx, linked to
+// the charged transaction. Callers run it in the same DB transaction as the
+// charged transaction, so both apply or neither does.
+func (s *TransactionService) chargeFee(ctx context.Context, pgtx pgx.Tx, payerID string, fee int64, charged models.Transaction) (models.Transaction, error) {
+	desc := "fee for " + string(charged.Type) + " " + charged.ID
+	tx, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+		Amount:     fee,
+		Currency:   charged.Currency,
+		Type:       models.TxnFee,
+		Status:     models.TxnCompleted,
+		FromUserID: &payerID,
+		ParentID:   &charged.ID,
+		TxnDetails: models.TxnDetails{Description: &desc},
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	debit, credit := entryCodes(tx)
+	if _, err := s.ledger.Post(ctx, pgtx, models.NewTransferEntry(tx.ID, "fee", debit, credit, fee)); err != nil {
+		return models.Transaction{}, err
+	}
+	return tx, nil
+}
+
+// QuoteFee is the fee userID would pay on a transfer or debit of amount.
+func (s *TransactionService) QuoteFee(userID string, typ models.TransactionType, amount int64, cur string) (models.FeeQuote, error) {
+	return s.fees.Quote(userID, typ, amount, cur)
+}
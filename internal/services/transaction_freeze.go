@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrAccountFrozen = errors.New("account frozen")
+	ErrAccountClosed = errors.New("account closed")
+)
+
+// checkAccounts refuses an operation that moves money out of from or into to
+// (either may be empty) when the account state forbids it. Adjustments,
+// reversals and interest are back-office corrections and are not checked;
+// refunds are the recipient's own doing and are.
+func (s *TransactionService) checkAccounts(from, to string) error {
+	if from != "" {
+		if err := s.checkAccount(from, "", models.AccountState.CanSend); err != nil {
+			return err
+		}
+	}
+	if to != "" {
+		if err := s.checkAccount(to, "recipient ", models.AccountState.CanReceive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TransactionService) checkAccount(userID, who string, allowed func(models.AccountState) bool) error {
+	u, err := s.users.GetByID(userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// unknown users are reported by the recipient check or the foreign keys
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check account state failed: %w", err)
+	}
+	switch {
+	case allowed(u.State):
+		return nil
+	case u.State == models.AccountClosed:
+		return fmt.Errorf("%s%w", who, ErrAccountClosed)
+	default:
+		return fmt.Errorf("%s%w (%s)", who, ErrAccountFrozen, u.State)
+	}
+}
+
+// checkTxnAccounts is checkAccounts for a transaction created earlier, e.g. one
+// that waited for approval or review while its accounts were frozen.
+func (s *TransactionService) checkTxnAccounts(tx models.Transaction) error {
+	var from, to string
+	if tx.FromUserID != nil {
+		from = *tx.FromUserID
+	}
+	if tx.ToUserID != nil {
+		to = *tx.ToUserID
+	}
+	return s.checkAccounts(from, to)
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNoFXRate           = errors.New("no exchange rate for this currency pair")
+	ErrConversionTooSmall = errors.New("amount is too small to convert")
+)
+
+// fxRate finds the current rate for from->to. If only to->from is on file its
+// inverse is used. The returned string is the rate actually applied.
+func (s *TransactionService) fxRate(ctx context.Context, from, to string) (models.FXRate, *big.Rat, error) {
+	fr, err := s.fx.Latest(ctx, from, to)
+	if err == nil {
+		r, err := currency.ParseRate(fr.Rate)
+		return fr, r, err
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.FXRate{}, nil, err
+	}
+	fr, err = s.fx.Latest(ctx, to, from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.FXRate{}, nil, ErrNoFXRate
+	}
+	if err != nil {
+		return models.FXRate{}, nil, err
+	}
+	r, err := currency.ParseRate(fr.Rate)
+	if err != nil {
+		return models.FXRate{}, nil, err
+	}
+	return fr, r.Inv(r), nil
+}
+
+// Conversion is the outcome of Convert. Transfer is the transfer paying the
+// converted amount to another user, if there is one.
+type Conversion struct {
+	models.FXConversion
+	Transfer *models.Transaction `json:"transfer,omitempty"`
+}
+
+// Convert moves amount out of fromID's fromCur wallet and the converted amount
+// into their toCur wallet at the current rate. Each leg is a conversion
+// transaction that balances in its own currency against the system FX account;
+// the credit leg's parent is the debit leg.
+//
+// When toID is another user, the converted amount is then sent to them by an
+// ordinary transfer, so limits, risk screening, approval and fees apply as to any
+// transfer; if it is held or awaits approval the money waits in fromID's toCur
+// wallet. The transfer is checked before converting, but should it still fail,
+// the conversion stands and is returned with the transfer's error.
+func (s *TransactionService) Convert(fromID, toID string, amount int64, fromCur, toCur string) (Conversion, error) {
+	if amount <= 0 {
+		return Conversion{}, errors.New("amount must be > 0")
+	}
+	from, err := currency.Lookup(fromCur)
+	if err != nil {
+		return Conversion{}, fmt.Errorf("%w %q", err, fromCur)
+	}
+	to, err := currency.Lookup(toCur)
+	if err != nil {
+		return Conversion{}, fmt.Errorf("%w %q", err, toCur)
+	}
+	if from.Code == to.Code {
+		return Conversion{}, errors.New("currencies must differ")
+	}
+	if toID == "" {
+		toID = fromID
+	}
+	pays := toID != fromID
+	if pays {
+		exists, err := s.users.Exists(context.Background(), toID)
+		if err != nil {
+			return Conversion{}, fmt.Errorf("check recipient failed: %w", err)
+		}
+		if !exists {
+			return Conversion{}, ErrRecipientNotFound
+		}
+	}
+	if err := s.checkAccounts(fromID, toID); err != nil {
+		return Conversion{}, err
+	}
+
+	ctx := context.Background()
+	fr, rate, err := s.fxRate(ctx, from.Code, to.Code)
+	if err != nil {
+		return Conversion{}, err
+	}
+	converted, err := currency.Convert(amount, from, to, rate)
+	if err != nil {
+		return Conversion{}, err
+	}
+	if converted <= 0 {
+		return Conversion{}, ErrConversionTooSmall
+	}
+	if pays {
+		if err := s.checkConvertedTransfer(fromID, to.Code, converted); err != nil {
+			return Conversion{}, err
+		}
+	}
+	conv, err := s.convert(ctx, fromID, amount, from, to, fr, rate, converted)
+	if err != nil || !pays {
+		return Conversion{FXConversion: conv}, err
+	}
+	desc := "conversion " + conv.ID
+	tx, err := s.transferAs(fromID, fromID, toID, "", converted, to.Code, "", models.TxnDetails{Description: &desc})
+	if err != nil {
+		s.auditDetails(conv.DebitTransactionID, "transfer_failed", map[string]any{"conversion_id": conv.ID, "to_user_id": toID, "error": err.Error()})
+		return Conversion{FXConversion: conv}, err
+	}
+	return Conversion{FXConversion: conv, Transfer: &tx}, nil
+}
+
+// checkConvertedTransfer checks ahead of converting what would make the transfer
+// of the converted amount fail outright: the sender's limits, and whether their
+// toCur wallet will cover it with its fee.
+func (s *TransactionService) checkConvertedTransfer(fromID, cur string, amount int64) error {
+	if err := s.limits.Check(context.Background(), fromID, models.TxnTransfer, cur, amount); err != nil {
+		return err
+	}
+	quote, err := s.fees.Quote(fromID, models.TxnTransfer, amount, cur)
+	if err != nil {
+		return err
+	}
+	available := amount
+	if b, err := s.bal.Get(fromID, cur); err == nil {
+		available += b.Available
+	}
+	if available < quote.Total {
+		return errors.New("insufficient balance")
+	}
+	return nil
+}
+
+// convert books a conversion of userID's own money.
+func (s *TransactionService) convert(ctx context.Context, userID string, amount int64, from, to currency.Currency,
+	fr models.FXRate, rate *big.Rat, converted int64) (models.FXConversion, error) {
+	if err := s.getOrCreateBalance(userID, from.Code); err != nil {
+		return models.FXConversion{}, err
+	}
+	if err := s.getOrCreateBalance(userID, to.Code); err != nil {
+		return models.FXConversion{}, err
+	}
+
+	var conv models.FXConversion
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		out, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:     amount,
+			Currency:   from.Code,
+			Type:       models.TxnConversion,
+			Status:     models.TxnCompleted,
+			FromUserID: &userID,
+		})
+		if err != nil {
+			return err
+		}
+		in, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:   converted,
+			Currency: to.Code,
+			Type:     models.TxnConversion,
+			Status:   models.TxnCompleted,
+			ToUserID: &userID,
+			ParentID: &out.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := s.ledger.Post(ctx, pgtx, models.NewTransferEntry(out.ID, "conversion",
+			models.UserAccountCode(userID, from.Code), models.SystemAccountCode(models.AccountFX, from.Code), amount)); err != nil {
+			return err
+		}
+		if _, err := s.ledger.Post(ctx, pgtx, models.NewTransferEntry(in.ID, "conversion",
+			models.SystemAccountCode(models.AccountFX, to.Code), models.UserAccountCode(userID, to.Code), converted)); err != nil {
+			return err
+		}
+		conv, err = s.conv.CreateTx(ctx, pgtx, models.FXConversion{
+			UserID:              userID,
+			ToUserID:            userID,
+			FromCurrency:        from.Code,
+			ToCurrency:          to.Code,
+			FromAmount:          amount,
+			ToAmount:            converted,
+			Rate:                rate.FloatString(12),
+			FXRateID:            fr.ID,
+			DebitTransactionID:  out.ID,
+			CreditTransactionID: in.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return models.FXConversion{}, err
+	}
+	s.auditDetails(conv.DebitTransactionID, "created", map[string]any{"message": "conversion created", "conversion_id": conv.ID, "rate": conv.Rate})
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnConversion)).Inc()
+	return conv, nil
+}
+
+func (s *TransactionService) ListConversions(userID string, limit, offset int) ([]models.FXConversion, error) {
+	return s.conv.ListByUser(userID, limit, offset)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+func TestConvertToAnotherUserIsATransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64 // USD cents; 0.9 EUR per USD
+		wantStatus models.TransactionStatus
+		wantPaid   int64 // EUR cents the recipient has afterwards
+	}{
+		{"below the approval threshold", 50000, models.TxnCompleted, 45000},
+		{"just under the approval threshold", 111111, models.TxnCompleted, 99999},
+		{"above the approval threshold", 200000, models.TxnAwaitingApproval, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBank(100000)
+			from := b.users.add("6f0c1c1e-0000-4000-8000-000000000001")
+			to := b.users.add("6f0c1c1e-0000-4000-8000-000000000002")
+			b.fund(from, "USD", 1000000)
+
+			c, err := b.Convert(from, to, tt.amount, "USD", "EUR")
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if c.ToUserID != from {
+				t.Errorf("conversion credited %s, want the sender's own wallet", c.ToUserID)
+			}
+			if c.Transfer == nil {
+				t.Fatal("no transfer to the recipient")
+			}
+			tx := c.Transfer
+			if tx.Type != models.TxnTransfer || tx.Status != tt.wantStatus || tx.Currency != "EUR" || tx.Amount != c.ToAmount {
+				t.Errorf("transfer = %s %s %d %s, want transfer %s %d EUR", tx.Type, tx.Status, tx.Amount, tx.Currency, tt.wantStatus, c.ToAmount)
+			}
+			if tx.CreatedBy == nil || *tx.CreatedBy != from {
+				t.Errorf("transfer created by %v, want %s", tx.CreatedBy, from)
+			}
+			if got := b.balance(to, "EUR"); got != tt.wantPaid {
+				t.Errorf("recipient has %d EUR, want %d", got, tt.wantPaid)
+			}
+			if got := b.balance(from, "EUR"); got != c.ToAmount-tt.wantPaid {
+				t.Errorf("sender has %d EUR, want %d", got, c.ToAmount-tt.wantPaid)
+			}
+		})
+	}
+}
+
+func TestConvertOwnMoneyHasNoTransfer(t *testing.T) {
+	b := newTestBank(100000)
+	u := b.users.add("6f0c1c1e-0000-4000-8000-000000000001")
+	b.fund(u, "USD", 1000000)
+	c, err := b.Convert(u, "", 500000, "USD", "EUR")
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if c.Transfer != nil {
+		t.Errorf("own conversion made transfer %+v", c.Transfer)
+	}
+	if got := b.balance(u, "EUR"); got != 450000 {
+		t.Errorf("user has %d EUR, want 450000", got)
+	}
+}
+
+func TestConvertToAnotherUserChecksBeforeConverting(t *testing.T) {
+	b := newTestBank(0)
+	from := b.users.add("6f0c1c1e-0000-4000-8000-000000000001")
+	b.fund(from, "USD", 1000000)
+	if _, err := b.Convert(from, "6f0c1c1e-0000-4000-8000-0000000000ff", 1000, "USD", "EUR"); err != ErrRecipientNotFound {
+		t.Fatalf("Convert to an unknown user: %v, want ErrRecipientNotFound", err)
+	}
+	if got := b.balance(from, "USD"); got != 1000000 {
+		t.Errorf("sender has %d USD after a refused conversion, want 1000000", got)
+	}
+}
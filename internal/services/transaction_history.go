@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HistoryPage is one page of a user's history. NextCursor is empty on the last page.
+type HistoryPage struct {
+	Items      []models.Transaction `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// History lists userID's transactions newest first. cursor is the NextCursor of
+// the previous page, or empty for the first one; it overrides f.After.
+func (s *TransactionService) History(userID string, f models.TransactionFilter, cursor string, limit int) (HistoryPage, error) {
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		f.After = &after
+	}
+	// one extra row tells whether another page exists
+	txs, err := s.trx.Search(context.Background(), userID, f, limit+1)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	page := HistoryPage{Items: txs}
+	if len(txs) > limit {
+		page.Items = txs[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(models.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if page.Items == nil {
+		page.Items = []models.Transaction{}
+	}
+	return page, nil
+}
+
+// Cursors are opaque to clients: base64url("<created_at RFC3339Nano>|<id>").
+func encodeCursor(c models.TransactionCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (models.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.TransactionCursor{}, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return models.TransactionCursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return models.TransactionCursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return models.TransactionCursor{}, ErrInvalidCursor
+	}
+	return models.TransactionCursor{CreatedAt: t, ID: id}, nil
+}
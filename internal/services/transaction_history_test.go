@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time
+	}{
+		{"whole second", time.Date(2026, 3, 2, 10, 7, 0, 0, time.UTC)},
+		{"microseconds", time.Date(2026, 3, 2, 10, 7, 0, 123456000, time.UTC)},
+		{"nanoseconds", time.Date(2026, 3, 2, 10, 7, 0, 1, time.UTC)},
+		{"other zone", time.Date(2026, 3, 2, 13, 7, 0, 5000, time.FixedZone("UTC+3", 3*60*60))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := models.TransactionCursor{CreatedAt: tt.at, ID: "0b5c8a3e-1f2d-4c6b-9a7e-3d2f1c0b9a8e"}
+			out, err := decodeCursor(encodeCursor(in))
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if !out.CreatedAt.Equal(in.CreatedAt) || out.ID != in.ID {
+				t.Errorf("round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded", raw("2026-03-02T10:07:00Z|0b5c8a3e-1f2d-4c6b-9a7e-3d2f1c0b9a8e") + "=="},
+		{"standard alphabet", "+/"},
+		{"no separator", raw("2026-03-02T10:07:00Z")},
+		{"bad time", raw("yesterday|0b5c8a3e-1f2d-4c6b-9a7e-3d2f1c0b9a8e")},
+		{"bad id", raw("2026-03-02T10:07:00Z|42")},
+		{"empty id", raw("2026-03-02T10:07:00Z|")},
+		{"empty", raw("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
+
+// historyTxns serves Search from memory with the keyset of the postgres repo:
+// (created_at, id) strictly below the cursor, newest first.
+type historyTxns struct {
+	repo.Transactions
+	rows []models.Transaction
+}
+
+func (r historyTxns) Search(_ context.Context, _ string, f models.TransactionFilter, limit int) ([]models.Transaction, error) {
+	var out []models.Transaction
+	for _, tx := range r.rows {
+		if a := f.After; a != nil && !(tx.CreatedAt.Before(a.CreatedAt) || tx.CreatedAt.Equal(a.CreatedAt) && tx.ID < a.ID) {
+			continue
+		}
+		out = append(out, tx)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func TestHistoryPagesThroughTies(t *testing.T) {
+	t0 := time.Date(2026, 3, 2, 10, 7, 0, 250000, time.UTC)
+	row := func(id string, at time.Time) models.Transaction {
+		return models.Transaction{ID: id, CreatedAt: at}
+	}
+	// five rows share t0, so every page boundary below falls inside the tie
+	rows := []models.Transaction{
+		row("00000000-0000-4000-8000-000000000001", t0.Add(time.Second)),
+		row("00000000-0000-4000-8000-00000000000a", t0),
+		row("00000000-0000-4000-8000-00000000000c", t0),
+		row("00000000-0000-4000-8000-00000000000b", t0),
+		row("00000000-0000-4000-8000-00000000000e", t0),
+		row("00000000-0000-4000-8000-00000000000d", t0),
+		row("00000000-0000-4000-8000-0000000000ff", t0.Add(-time.Microsecond)),
+	}
+	want := []string{
+		"00000000-0000-4000-8000-000000000001",
+		"00000000-0000-4000-8000-00000000000e",
+		"00000000-0000-4000-8000-00000000000d",
+		"00000000-0000-4000-8000-00000000000c",
+		"00000000-0000-4000-8000-00000000000b",
+		"00000000-0000-4000-8000-00000000000a",
+		"00000000-0000-4000-8000-0000000000ff",
+	}
+	for _, limit := range []int{1, 2, 3, 6, 7, 8} {
+		s := &TransactionService{trx: historyTxns{rows: rows}}
+		var got []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(rows) {
+				t.Fatalf("limit %d: paging did not end", limit)
+			}
+			page, err := s.History("u", models.TransactionFilter{}, cursor, limit)
+			if err != nil {
+				t.Fatalf("limit %d: History: %v", limit, err)
+			}
+			if len(page.Items) > limit {
+				t.Fatalf("limit %d: page of %d items", limit, len(page.Items))
+			}
+			for _, tx := range page.Items {
+				got = append(got, tx.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(got) != len(want) {
+			t.Fatalf("limit %d: got %v, want %v", limit, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("limit %d: got %v, want %v", limit, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrHoldNotFound   = errors.New("hold not found")
+	ErrHoldNotActive  = errors.New("hold is no longer authorized")
+	ErrCaptureExceeds = errors.New("capture amount exceeds the held amount")
+	// a capture can't wait for approval, so such holds are refused up front
+	ErrHoldNeedsApproval = errors.New("holds for a payee above the approval threshold are not allowed; send a transfer instead")
+)
+
+const (
+	DefaultHoldTTL = 7 * 24 * time.Hour
+	MaxHoldTTL     = 30 * 24 * time.Hour
+)
+
+// Authorize reserves amount on userID's balance. The ledger balance doesn't move;
+// only the available amount drops until the hold is captured, voided or expires.
+// A hold with a payee is a transfer in waiting: it falls under the transfer limits
+// and is screened like one, and one that would need approval or review is refused.
+func (s *TransactionService) Authorize(userID string, amount int64, cur string, payeeID *string, ttl time.Duration) (models.Hold, error) {
+	if amount <= 0 {
+		return models.Hold{}, errors.New("amount must be > 0")
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultHoldTTL
+	}
+	if ttl > MaxHoldTTL {
+		return models.Hold{}, fmt.Errorf("hold cannot last longer than %s", MaxHoldTTL)
+	}
+	if payeeID != nil {
+		if *payeeID == userID {
+			return models.Hold{}, errors.New("cannot authorize to self")
+		}
+		if s.needsApproval(amount) {
+			return models.Hold{}, ErrHoldNeedsApproval
+		}
+		exists, err := s.users.Exists(context.Background(), *payeeID)
+		if err != nil {
+			return models.Hold{}, fmt.Errorf("check recipient failed: %w", err)
+		}
+		if !exists {
+			return models.Hold{}, ErrRecipientNotFound
+		}
+	}
+	var payee string
+	if payeeID != nil {
+		payee = *payeeID
+	}
+	if err := s.checkAccounts(userID, payee); err != nil {
+		return models.Hold{}, err
+	}
+	ctx := context.Background()
+	if payeeID != nil {
+		if err := s.limits.Check(ctx, userID, models.TxnTransfer, cur, amount); err != nil {
+			return models.Hold{}, err
+		}
+		// like a batch leg, a hold can't wait in the review queue
+		res := s.screen(models.RiskCheck{UserID: userID, Type: models.TxnTransfer, ToUserID: payeeID, Amount: amount, Currency: cur})
+		if res.Decision != models.RiskAllow {
+			return models.Hold{}, ErrRiskFlagged
+		}
+	}
+	if err := s.getOrCreateBalance(userID, cur); err != nil {
+		return models.Hold{}, err
+	}
+
+	var hold models.Hold
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		if payeeID != nil {
+			if err := s.limits.CheckTx(ctx, pgtx, userID, models.TxnTransfer, cur, "", amount); err != nil {
+				return err
+			}
+		}
+		auth, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:     amount,
+			Currency:   cur,
+			Type:       models.TxnAuthorization,
+			Status:     models.TxnCompleted,
+			FromUserID: &userID,
+			ToUserID:   payeeID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.bal.HoldTx(ctx, pgtx, userID, cur, amount); err != nil {
+			return err
+		}
+		hold, err = s.holds.CreateTx(ctx, pgtx, models.Hold{
+			UserID:        userID,
+			PayeeUserID:   payeeID,
+			Amount:        amount,
+			Currency:      cur,
+			Status:        models.HoldAuthorized,
+			TransactionID: auth.ID,
+			ExpiresAt:     time.Now().Add(ttl),
+		})
+		return err
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+	s.auditDetails(hold.TransactionID, "created", map[string]any{"message": "authorization created", "hold_id": hold.ID})
+	return hold, nil
+}
+
+// lockHold loads an authorized hold that actorID may settle: its owner or its payee.
+func (s *TransactionService) lockHold(ctx context.Context, pgtx pgx.Tx, holdID, actorID string) (models.Hold, error) {
+	h, err := s.holds.GetForUpdateTx(ctx, pgtx, holdID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Hold{}, ErrHoldNotFound
+	}
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if actorID != h.UserID && (h.PayeeUserID == nil || actorID != *h.PayeeUserID) {
+		return models.Hold{}, ErrForbidden
+	}
+	if h.Status != models.HoldAuthorized || !time.Now().Before(h.ExpiresAt) {
+		return models.Hold{}, ErrHoldNotActive
+	}
+	return h, nil
+}
+
+// Capture settles a hold. amount 0 captures everything; a partial capture
+// releases the remainder. Funds go to the payee, or leave the system if there is none.
+// Paying a payee is priced as a transfer; the fee is charged in the same DB transaction.
+func (s *TransactionService) Capture(holdID, actorID string, amount int64) (models.Transaction, error) {
+	ctx := context.Background()
+	var hold models.Hold
+	var created models.Transaction
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if hold, err = s.lockHold(ctx, pgtx, holdID, actorID); err != nil {
+			return err
+		}
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceeds
+		}
+		// the threshold may have been lowered since the hold was authorized
+		if hold.PayeeUserID != nil && s.needsApproval(amount) {
+			return ErrHoldNeedsApproval
+		}
+		var payee string
+		if hold.PayeeUserID != nil {
+			payee = *hold.PayeeUserID
+		}
+		if err := s.checkAccounts(hold.UserID, payee); err != nil {
+			return err
+		}
+		if hold.PayeeUserID != nil {
+			if err := s.getOrCreateBalance(*hold.PayeeUserID, hold.Currency); err != nil {
+				return err
+			}
+		}
+
+		// release first so the debit below sees the funds as available again
+		if err := s.bal.ReleaseTx(ctx, pgtx, hold.UserID, hold.Currency, hold.Amount); err != nil {
+			return err
+		}
+		if hold, err = s.holds.SettleTx(ctx, pgtx, hold.ID, models.HoldCaptured, amount); err != nil {
+			return err
+		}
+		if created, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:     amount,
+			Currency:   hold.Currency,
+			Type:       models.TxnCapture,
+			Status:     models.TxnCompleted,
+			FromUserID: &hold.UserID,
+			ToUserID:   hold.PayeeUserID,
+			ParentID:   &hold.TransactionID,
+		}); err != nil {
+			return err
+		}
+		debit, credit := entryCodes(created)
+		if _, err := s.ledger.Post(ctx, pgtx, models.NewTransferEntry(created.ID, "capture", debit, credit, amount)); err != nil {
+			return err
+		}
+		if hold.PayeeUserID == nil {
+			return nil
+		}
+		quote, err := s.fees.Quote(hold.UserID, models.TxnTransfer, amount, hold.Currency)
+		if err != nil {
+			return err
+		}
+		if quote.Fee > 0 {
+			_, err = s.chargeFee(ctx, pgtx, hold.UserID, quote.Fee, created)
+		}
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	s.auditDetails(created.ID, "created", map[string]any{"message": "capture created", "hold_id": hold.ID, "actor_id": actorID})
+	s.auditDetails(hold.TransactionID, "status_change", map[string]any{"message": "hold captured", "hold_id": hold.ID, "captured_amount": hold.CapturedAmount})
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnCapture)).Inc()
+	return created, nil
+}
+
+// Void releases an authorized hold without moving money.
+func (s *TransactionService) Void(holdID, actorID string) (models.Hold, error) {
+	ctx := context.Background()
+	var hold models.Hold
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if hold, err = s.lockHold(ctx, pgtx, holdID, actorID); err != nil {
+			return err
+		}
+		hold, err = s.release(ctx, pgtx, hold, models.HoldVoided)
+		return err
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+	s.auditDetails(hold.TransactionID, "status_change", map[string]any{"message": "hold voided", "hold_id": hold.ID, "actor_id": actorID})
+	return hold, nil
+}
+
+// ExpireHolds releases authorized holds whose expiry has passed. Safe to run on every replica.
+func (s *TransactionService) ExpireHolds() (int, error) {
+	ctx := context.Background()
+	expired, err := s.holds.ListExpired(ctx, 100)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, h := range expired {
+		err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+			cur, err := s.holds.GetForUpdateTx(ctx, pgtx, h.ID)
+			if err != nil {
+				return err
+			}
+			if cur.Status != models.HoldAuthorized {
+				return nil // settled meanwhile
+			}
+			h, err = s.release(ctx, pgtx, cur, models.HoldExpired)
+			return err
+		})
+		if err != nil {
+			return n, err
+		}
+		if h.Status == models.HoldExpired {
+			n++
+			s.auditDetails(h.TransactionID, "status_change", map[string]any{"message": "hold expired", "hold_id": h.ID})
+		}
+	}
+	return n, nil
+}
+
+// release frees the full hold and records a void transaction.
+func (s *TransactionService) release(ctx context.Context, pgtx pgx.Tx, h models.Hold, status models.HoldStatus) (models.Hold, error) {
+	if err := s.bal.ReleaseTx(ctx, pgtx, h.UserID, h.Currency, h.Amount); err != nil {
+		return models.Hold{}, err
+	}
+	if _, err := s.trx.CreateTx(ctx, pgtx, models.Transaction{
+		Amount:     h.Amount,
+		Currency:   h.Currency,
+		Type:       models.TxnVoid,
+		Status:     models.TxnCompleted,
+		FromUserID: &h.UserID,
+		ToUserID:   h.PayeeUserID,
+		ParentID:   &h.TransactionID,
+	}); err != nil {
+		return models.Hold{}, err
+	}
+	return s.holds.SettleTx(ctx, pgtx, h.ID, status, 0)
+}
+
+func (s *TransactionService) ListHolds(userID string, limit, offset int) ([]models.Hold, error) {
+	return s.holds.ListByUser(userID, limit, offset)
+}
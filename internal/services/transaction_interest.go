@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// errNothingToPay rolls back a payout whose claim came up empty or lost a race.
+var errNothingToPay = errors.New("nothing to pay")
+
+// PayInterest credits userID's wallet walletID with the savings interest of
+// period (a month) from the house interest account. claim marks the accruals being paid with the new
+// transaction's id and returns their total, in the same database transaction.
+// ok is false when there was nothing left to pay or the period was already paid.
+func (s *TransactionService) PayInterest(userID, walletID, cur string, period time.Time,
+	claim func(ctx context.Context, pgtx pgx.Tx, txID string) (int64, error)) (tx models.Transaction, ok bool, err error) {
+	month := period.UTC().Format("2006-01")
+	key := fmt.Sprintf("interest:%s:%s:%s", walletID, cur, month)
+	if existing, found := s.existing(key); found {
+		return existing, false, nil
+	}
+	desc := "interest " + month
+	ctx := context.Background()
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		id := uuid.NewString()
+		amount, err := claim(ctx, pgtx, id)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			return errNothingToPay
+		}
+		tx, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			ID:             id,
+			Amount:         amount,
+			Currency:       cur,
+			Type:           models.TxnInterest,
+			Status:         models.TxnCompleted,
+			ToUserID:       &userID,
+			ToWalletID:     walletRef(userID, walletID),
+			IdempotencyKey: &key,
+			TxnDetails:     models.TxnDetails{Description: &desc},
+		})
+		if err != nil {
+			return err
+		}
+		if tx.ID != id {
+			// another replica paid this period first
+			return errNothingToPay
+		}
+		debit, credit := entryCodes(tx)
+		_, err = s.ledger.Post(ctx, pgtx, models.NewTransferEntry(tx.ID, desc, debit, credit, amount))
+		return err
+	})
+	if errors.Is(err, errNothingToPay) {
+		return models.Transaction{}, false, nil
+	}
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+	s.audit(tx.ID, "created", desc)
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnInterest)).Inc()
+	return tx, true, nil
+}
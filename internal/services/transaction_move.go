@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrWalletNotFound = errors.New("wallet not found")
+	ErrSameWallet     = errors.New("source and destination wallet must differ")
+)
+
+// walletOf checks that walletID is one of userID's wallets. The main wallet,
+// named by an empty id or the user's own id, comes back as nil, the way
+// transactions record it.
+func (s *TransactionService) walletOf(userID, walletID string) (*string, error) {
+	if walletID == "" || walletID == userID {
+		return nil, nil
+	}
+	w, err := s.wallets.GetByID(context.Background(), walletID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && w.UserID != userID) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w.ID, nil
+}
+
+// walletRef is how walletID is recorded on a transaction: nil for the main wallet.
+func walletRef(userID, walletID string) *string {
+	if walletID == userID {
+		return nil
+	}
+	return &walletID
+}
+
+// walletKey is the balance key of a wallet recorded on a transaction.
+func walletKey(userID string, walletID *string) string {
+	if walletID == nil {
+		return userID
+	}
+	return *walletID
+}
+
+// Move shifts money between two of userID's wallets in one DB transaction. It is
+// free and not subject to limits, risk screening or approval, since the money
+// never leaves the user. Empty wallet ids are the main wallet.
+func (s *TransactionService) Move(userID, fromWalletID, toWalletID string, amount int64, cur string, d models.TxnDetails) (models.Transaction, error) {
+	if amount <= 0 {
+		return models.Transaction{}, errors.New("amount must be > 0")
+	}
+	if err := d.Validate(); err != nil {
+		return models.Transaction{}, err
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	from, err := s.walletOf(userID, fromWalletID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	to, err := s.walletOf(userID, toWalletID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if walletKey(userID, from) == walletKey(userID, to) {
+		return models.Transaction{}, ErrSameWallet
+	}
+	if err := s.checkAccounts(userID, ""); err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.getOrCreateBalance(walletKey(userID, from), cur); err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.getOrCreateBalance(walletKey(userID, to), cur); err != nil {
+		return models.Transaction{}, err
+	}
+
+	ctx := context.Background()
+	var created models.Transaction
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if created, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:       amount,
+			Currency:     cur,
+			Type:         models.TxnMove,
+			Status:       models.TxnCompleted,
+			FromUserID:   &userID,
+			ToUserID:     &userID,
+			FromWalletID: from,
+			ToWalletID:   to,
+			TxnDetails:   d,
+		}); err != nil {
+			return err
+		}
+		debit, credit := entryCodes(created)
+		_, err = s.ledger.Post(ctx, pgtx, models.NewTransferEntry(created.ID, "move", debit, credit, amount))
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	s.audit(created.ID, "created", "move between wallets")
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnMove)).Inc()
+	return created, nil
+}
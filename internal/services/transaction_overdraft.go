@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ChargeOverdraftInterest books one day of interest on the drawn credit line of
+// userID's wallet walletID.
+// The day is part of the idempotency key, so a day is charged at most once even
+// when several replicas accrue at the same time. ok is false if it was already charged.
+func (s *TransactionService) ChargeOverdraftInterest(userID, walletID, cur string, amount int64, day time.Time) (tx models.Transaction, ok bool, err error) {
+	key := fmt.Sprintf("overdraft-interest:%s:%s:%s", walletID, cur, day.UTC().Format("2006-01-02"))
+	if existing, found := s.existing(key); found {
+		return existing, false, nil
+	}
+	desc := "overdraft interest " + day.UTC().Format("2006-01-02")
+	ctx := context.Background()
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		id := uuid.NewString()
+		var err error
+		tx, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			ID:             id,
+			Amount:         amount,
+			Currency:       cur,
+			Type:           models.TxnOverdraftInterest,
+			Status:         models.TxnCompleted,
+			FromUserID:     &userID,
+			FromWalletID:   walletRef(userID, walletID),
+			IdempotencyKey: &key,
+			TxnDetails:     models.TxnDetails{Description: &desc},
+		})
+		if err != nil || tx.ID != id {
+			// another replica charged this day first
+			ok = false
+			return err
+		}
+		debit, credit := entryCodes(tx)
+		entry := models.NewTransferEntry(tx.ID, "overdraft interest", debit, credit, amount)
+		// booked even when it takes the balance past the credit line
+		entry.Unguarded = true
+		if _, err := s.ledger.Post(ctx, pgtx, entry); err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+	if ok {
+		s.audit(tx.ID, "created", desc)
+		metrics.TransactionsTotal.WithLabelValues(string(models.TxnOverdraftInterest)).Inc()
+	}
+	return tx, ok, nil
+}
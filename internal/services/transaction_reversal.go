@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotReversible = errors.New("only completed credit, debit or transfer transactions can be reversed")
+	ErrNotRefundable = errors.New("only completed transfers can be refunded")
+	ErrRefundExceeds = errors.New("refund amount exceeds the refundable amount")
+)
+
+// entryCodes returns the ledger accounts a transaction debited and credited.
+// A missing sender means money came in from outside, a missing recipient means it
+// left; fees and overdraft interest go to the revenue account.
+func entryCodes(tx models.Transaction) (debit, credit string) {
+	debit = models.SystemAccountCode(models.AccountFunding, tx.Currency)
+	credit = models.SystemAccountCode(models.AccountPayout, tx.Currency)
+	if tx.Type == models.TxnFee || tx.Type == models.TxnOverdraftInterest {
+		credit = models.SystemAccountCode(models.AccountRevenue, tx.Currency)
+	}
+	if tx.Type == models.TxnInterest {
+		debit = models.SystemAccountCode(models.AccountInterest, tx.Currency)
+	}
+	if tx.FromUserID != nil {
+		debit = models.WalletAccountCode(*tx.FromUserID, tx.FromWalletID, tx.Currency)
+	}
+	if tx.ToUserID != nil {
+		credit = models.WalletAccountCode(*tx.ToUserID, tx.ToWalletID, tx.Currency)
+	}
+	return debit, credit
+}
+
+// lockOriginal loads and locks the transaction being compensated.
+func (s *TransactionService) lockOriginal(ctx context.Context, pgtx pgx.Tx, id string) (models.Transaction, error) {
+	orig, err := s.trx.GetForUpdateTx(ctx, pgtx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Transaction{}, ErrTransactionNotFound
+	}
+	return orig, err
+}
+
+// Reverse undoes a completed transaction with a linked compensating reversal.
+// Whatever was already refunded is excluded. Admin only; enforced by the router.
+func (s *TransactionService) Reverse(txID, actorID, reason string) (models.Transaction, error) {
+	ctx := context.Background()
+	var orig, created models.Transaction
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if orig, err = s.lockOriginal(ctx, pgtx, txID); err != nil {
+			return err
+		}
+		switch orig.Type {
+		case models.TxnCredit, models.TxnDebit, models.TxnTransfer:
+		default:
+			return ErrNotReversible
+		}
+		if orig.Status != models.TxnCompleted {
+			return ErrNotReversible
+		}
+		if err := s.checkNotDisputed(ctx, pgtx, orig.ID); err != nil {
+			return err
+		}
+		refunded, err := s.trx.SumChildrenTx(ctx, pgtx, orig.ID, models.TxnRefund)
+		if err != nil {
+			return err
+		}
+
+		if created, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:       orig.Amount - refunded,
+			Currency:     orig.Currency,
+			Type:         models.TxnReversal,
+			Status:       models.TxnCompleted,
+			FromUserID:   orig.ToUserID,
+			ToUserID:     orig.FromUserID,
+			FromWalletID: orig.ToWalletID,
+			ToWalletID:   orig.FromWalletID,
+			ParentID:     &orig.ID,
+		}); err != nil {
+			return err
+		}
+		debit, credit := entryCodes(orig)
+		entry := models.NewTransferEntry(created.ID, "reversal", credit, debit, created.Amount)
+		if _, err := s.ledger.Post(ctx, pgtx, entry); err != nil {
+			return err
+		}
+		_, err = s.trx.TransitionTx(ctx, pgtx, orig.ID, models.TxnCompleted, models.TxnReversed)
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	s.auditDetails(created.ID, "created", map[string]any{"message": "reversal created", "parent_id": orig.ID, "actor_id": actorID, "reason": reason})
+	s.auditDetails(orig.ID, "status_change", map[string]any{"message": "reversed", "reversal_id": created.ID, "actor_id": actorID, "reason": reason})
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnReversal)).Inc()
+	return created, nil
+}
+
+// Refund sends part (or all) of a completed transfer back to its sender.
+// Only the recipient may refund; once the full amount is returned the transfer becomes refunded.
+func (s *TransactionService) Refund(txID, actorID string, amount int64, reason string) (models.Transaction, error) {
+	if amount <= 0 {
+		return models.Transaction{}, errors.New("amount must be > 0")
+	}
+	ctx := context.Background()
+	var orig, created models.Transaction
+	var fully bool
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		var err error
+		if orig, err = s.lockOriginal(ctx, pgtx, txID); err != nil {
+			return err
+		}
+		if orig.Type != models.TxnTransfer || orig.Status != models.TxnCompleted {
+			return ErrNotRefundable
+		}
+		if orig.ToUserID == nil || *orig.ToUserID != actorID {
+			return ErrForbidden
+		}
+		// a refund is the recipient paying out, so their state must allow sending
+		if err := s.checkAccounts(*orig.ToUserID, *orig.FromUserID); err != nil {
+			return err
+		}
+		if err := s.checkNotDisputed(ctx, pgtx, orig.ID); err != nil {
+			return err
+		}
+		refunded, err := s.trx.SumChildrenTx(ctx, pgtx, orig.ID, models.TxnRefund)
+		if err != nil {
+			return err
+		}
+		if amount > orig.Amount-refunded {
+			return ErrRefundExceeds
+		}
+
+		if created, err = s.trx.CreateTx(ctx, pgtx, models.Transaction{
+			Amount:       amount,
+			Currency:     orig.Currency,
+			Type:         models.TxnRefund,
+			Status:       models.TxnCompleted,
+			FromUserID:   orig.ToUserID,
+			ToUserID:     orig.FromUserID,
+			FromWalletID: orig.ToWalletID,
+			ToWalletID:   orig.FromWalletID,
+			ParentID:     &orig.ID,
+		}); err != nil {
+			return err
+		}
+		debit, credit := entryCodes(created)
+		entry := models.NewTransferEntry(created.ID, "refund", debit, credit, amount)
+		if _, err := s.ledger.Post(ctx, pgtx, entry); err != nil {
+			return err
+		}
+		if refunded+amount == orig.Amount {
+			fully = true
+			_, err = s.trx.TransitionTx(ctx, pgtx, orig.ID, models.TxnCompleted, models.TxnRefunded)
+		}
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	s.auditDetails(created.ID, "created", map[string]any{"message": "refund created", "parent_id": orig.ID, "actor_id": actorID, "reason": reason})
+	if fully {
+		s.auditDetails(orig.ID, "status_change", map[string]any{"message": "refunded", "refund_id": created.ID, "actor_id": actorID})
+	}
+	metrics.TransactionsTotal.WithLabelValues(string(models.TxnRefund)).Inc()
+	return created, nil
+}
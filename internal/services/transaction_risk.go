@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRiskCaseNotFound = errors.New("risk case not found")
+	ErrRiskCaseClosed   = errors.New("risk case is already decided")
+	ErrHeldForReview    = errors.New("transaction is held for review")
+	ErrRiskFlagged      = errors.New("flagged by risk screening; send it as a single transfer to have it reviewed")
+)
+
+// RiskEvaluator screens a debit or transfer before it is created.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, c models.RiskCheck) (models.RiskResult, error)
+}
+
+// screen evaluates c. An evaluator that fails holds the transaction rather than
+// letting it through unscreened.
+func (s *TransactionService) screen(c models.RiskCheck) models.RiskResult {
+	if s.risk == nil {
+		return models.RiskResult{Decision: models.RiskAllow}
+	}
+	res, err := s.risk.Evaluate(context.Background(), c)
+	if err != nil {
+		res = models.RiskResult{Decision: models.RiskAllow}
+		res.Add(models.RiskHit{Rule: "evaluator", Decision: models.RiskReview, Reason: err.Error()})
+	}
+	return res
+}
+
+// screenOrHold screens tx before it is created. Unless it is allowed, tx is
+// created held for review together with its case, moving no money, and held is true.
+func (s *TransactionService) screenOrHold(tx models.Transaction) (created models.Transaction, held bool, err error) {
+	res := s.screen(models.RiskCheck{
+		UserID: *tx.FromUserID, Type: tx.Type, ToUserID: tx.ToUserID, Amount: tx.Amount, Currency: tx.Currency,
+	})
+	if res.Decision == models.RiskAllow {
+		return models.Transaction{}, false, nil
+	}
+	tx.Status = models.TxnHeldForReview
+	ctx := context.Background()
+	opened := false
+	err = s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		tx.ID = uuid.NewString()
+		var err error
+		if created, err = s.trx.CreateTx(ctx, pgtx, tx); err != nil || created.ID != tx.ID {
+			// idempotent retry: the first attempt already created it
+			opened = false
+			return err
+		}
+		_, err = s.cases.CreateCaseTx(ctx, pgtx, models.RiskCase{Transaction: created, Decision: res.Decision, Hits: res.Hits})
+		opened = err == nil
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+	if !opened {
+		return created, true, nil
+	}
+	s.auditDetails(created.ID, "created", map[string]any{
+		"message": fmt.Sprintf("%s held for review", tx.Type), "decision": res.Decision, "hits": res.Hits,
+	})
+	metrics.TransactionsTotal.WithLabelValues(string(tx.Type) + "_held_for_review").Inc()
+	return created, true, nil
+}
+
+//  review queue (admin only; enforced by the router)
+
+func (s *TransactionService) RiskCases(status models.RiskCaseStatus, limit, offset int) ([]models.RiskCase, error) {
+	return s.cases.ListCases(context.Background(), status, limit, offset)
+}
+
+func (s *TransactionService) RiskCase(id string) (models.RiskCase, error) {
+	c, err := s.cases.GetCase(context.Background(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.RiskCase{}, ErrRiskCaseNotFound
+	}
+	return c, err
+}
+
+func (s *TransactionService) openCase(id string) (models.RiskCase, error) {
+	c, err := s.RiskCase(id)
+	if err != nil {
+		return models.RiskCase{}, err
+	}
+	if c.Status != models.CaseOpen {
+		return models.RiskCase{}, ErrRiskCaseClosed
+	}
+	return c, nil
+}
+
+// ReleaseCase lets a held transaction go ahead as the reviewer actorID decided.
+// A debit goes back to the settlement queue; a transfer above the approval
+// threshold still needs its approval, any other transfer is executed now.
+func (s *TransactionService) ReleaseCase(caseID, actorID string, note *string) (models.Transaction, error) {
+	c, err := s.openCase(caseID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	tx := c.Transaction
+	if err := s.checkTxnAccounts(tx); err != nil {
+		return models.Transaction{}, err
+	}
+	decide := func(ctx context.Context, pgtx pgx.Tx) error {
+		ok, err := s.cases.DecideCaseTx(ctx, pgtx, c.ID, models.CaseReleased, actorID, note)
+		if err == nil && !ok {
+			return errNotInStatus
+		}
+		return err
+	}
+	det := map[string]any{"message": "released by risk review", "case_id": c.ID, "actor_id": actorID}
+	if note != nil {
+		det["note"] = *note
+	}
+
+	if tx.Type == models.TxnTransfer && !s.needsApproval(tx.Amount) {
+		quote, err := s.fees.Quote(*tx.FromUserID, models.TxnTransfer, tx.Amount, tx.Currency)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+		out, err := s.applyTransfer(tx, models.TxnHeldForReview, quote.Fee, func(ctx context.Context, pgtx pgx.Tx) error {
+			if err := decide(ctx, pgtx); err != nil {
+				return err
+			}
+			return s.runDecided(ctx, pgtx, tx, models.TxnCompleted)
+		})
+		switch {
+		case errors.Is(err, errNotInStatus):
+			return models.Transaction{}, ErrRiskCaseClosed
+		case err != nil:
+			// the transfer was rolled back; the reviewer's decision still stands
+			det["error"] = err.Error()
+			_ = s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error { return decide(context.Background(), pgtx) })
+		}
+		s.auditDetails(tx.ID, "risk_released", det)
+		return out, err
+	}
+
+	next := models.TxnPending
+	if tx.Type == models.TxnTransfer {
+		next = models.TxnAwaitingApproval
+	}
+	err = s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error {
+		ctx := context.Background()
+		if err := decide(ctx, pgtx); err != nil {
+			return err
+		}
+		ok, err := s.trx.TransitionTx(ctx, pgtx, tx.ID, models.TxnHeldForReview, next)
+		if err == nil && !ok {
+			return errNotInStatus
+		}
+		return err
+	})
+	if errors.Is(err, errNotInStatus) {
+		return models.Transaction{}, ErrRiskCaseClosed
+	}
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	tx.Status = next
+	s.auditDetails(tx.ID, "risk_released", det)
+	if tx.Type == models.TxnDebit {
+		s.enqueue(tx)
+	}
+	return tx, nil
+}
+
+// RejectCase stops a held transaction for good; nothing was moved for it.
+func (s *TransactionService) RejectCase(caseID, actorID string, note *string) (models.Transaction, error) {
+	c, err := s.openCase(caseID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	err = s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error {
+		ctx := context.Background()
+		ok, err := s.cases.DecideCaseTx(ctx, pgtx, c.ID, models.CaseRejected, actorID, note)
+		if err != nil {
+			return err
+		}
+		if ok {
+			ok, err = s.trx.TransitionTx(ctx, pgtx, c.Transaction.ID, models.TxnHeldForReview, models.TxnRejected)
+		}
+		if err == nil && !ok {
+			return ErrRiskCaseClosed
+		}
+		if err != nil {
+			return err
+		}
+		return s.runDecided(ctx, pgtx, c.Transaction, models.TxnRejected)
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	tx := c.Transaction
+	tx.Status = models.TxnRejected
+	det := map[string]any{"message": "rejected by risk review", "case_id": c.ID, "actor_id": actorID}
+	if note != nil {
+		det["note"] = *note
+	}
+	s.auditDetails(tx.ID, "risk_rejected", det)
+	return tx, nil
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
+	"log/slog"
 
+	"github.com/baharkarakas/insider-backend/internal/currency"
 	"github.com/baharkarakas/insider-backend/internal/metrics"
 	"github.com/baharkarakas/insider-backend/internal/models"
 	repo "github.com/baharkarakas/insider-backend/internal/repository"
@@ -13,16 +14,39 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-var ErrRecipientNotFound = errors.New("recipient user not found")
+var (
+	ErrRecipientNotFound   = errors.New("recipient user not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrForbidden           = errors.New("not allowed")
+)
 
 
 type TransactionService struct {
 	trx   repo.Transactions
 	bal   repo.Balances
 	log   repo.AuditLogs
-	users repo.Users
-	wp    *worker.Pool
-	idem  sync.Map
+	users  repo.Users
+	ledger repo.Ledger
+	holds  repo.Holds
+	fx     repo.FXRates
+	conv   repo.FXConversions
+	batch  repo.Batches
+	wallets repo.Wallets
+	limits *LimitService
+	fees   *FeeService
+	q      *worker.Queue
+
+	// transfers above approvalThreshold wait for an admin; 0 disables approvals
+	approvalThreshold int64
+
+	// risk screens debits and transfers before they are created; nil lets everything through
+	risk  RiskEvaluator
+	cases repo.RiskCases
+
+	disputes repo.Disputes
+
+	// run when an admin's decision completes or rejects a waiting transfer; see OnDecided
+	decided []func(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) error
 }
 
 func NewTransactionService(
@@ -30,9 +54,27 @@ func NewTransactionService(
 	b repo.Balances,
 	l repo.AuditLogs,
 	u repo.Users,
-	wp *worker.Pool,
+	lg repo.Ledger,
+	h repo.Holds,
+	fx repo.FXRates,
+	conv repo.FXConversions,
+	batch repo.Batches,
+	wallets repo.Wallets,
+	limits *LimitService,
+	fees *FeeService,
+	q *worker.Queue,
+	approvalThreshold int64,
+	risk RiskEvaluator,
+	cases repo.RiskCases,
+	disputes repo.Disputes,
 ) *TransactionService {
-	return &TransactionService{trx: t, bal: b, log: l, users: u, wp: wp}
+	s := &TransactionService{trx: t, bal: b, log: l, users: u, ledger: lg, holds: h, fx: fx, conv: conv, batch: batch, wallets: wallets, limits: limits, fees: fees, q: q,
+		approvalThreshold: approvalThreshold, risk: risk, cases: cases, disputes: disputes}
+	q.Handle(string(models.TxnCredit), s.handleJob)
+	q.Handle(string(models.TxnDebit), s.handleJob)
+	q.RecoverPending(string(models.TxnCredit), string(models.TxnDebit))
+	q.OnExpired(s.jobExpired)
+	return s
 }
 
 //  helpers 
@@ -42,6 +84,10 @@ func (s *TransactionService) audit(entityID, action, details string) {
 	if details != "" {
 		det = map[string]any{"message": details}
 	}
+	s.auditDetails(entityID, action, det)
+}
+
+func (s *TransactionService) auditDetails(entityID, action string, det map[string]any) {
 	_ = s.log.Create(models.AuditLog{
 		EntityType: "transaction",
 		EntityID:   &entityID,
@@ -58,32 +104,167 @@ func (s *TransactionService) updateStatus(txID string, status models.Transaction
 	return nil
 }
 
-func (s *TransactionService) getOrCreateBalance(userID string) error {
-	_, err := s.bal.GetOrCreate(userID)
+// existing returns the transaction already created with idemKey. HTTP retries are
+// handled by the Idempotency middleware; this covers internal callers that
+// derive deterministic keys.
+func (s *TransactionService) existing(idemKey string) (models.Transaction, bool) {
+	if idemKey == "" {
+		return models.Transaction{}, false
+	}
+	tx, err := s.trx.GetByIdempotencyKey(idemKey)
+	return tx, err == nil
+}
+
+// optional is nil for an empty idempotency key.
+func optional(key string) *string {
+	if key == "" {
+		return nil
+	}
+	return &key
+}
+
+// getOrCreateBalance opens a wallet's balance in cur; a user's id is their main wallet.
+func (s *TransactionService) getOrCreateBalance(walletID, cur string) error {
+	_, err := s.bal.GetOrCreate(walletID, cur)
 	return err
 }
 
+// currencyCode validates an ISO 4217 code and returns it upper-cased.
+func currencyCode(code string) (string, error) {
+	c, err := currency.Lookup(code)
+	if err != nil {
+		return "", fmt.Errorf("%w %q", err, code)
+	}
+	return c.Code, nil
+}
+
+// settle moves tx from pending to completed and posts e in one DB transaction,
+// after checking its limits again. It reports false without posting if the
+// transaction was already settled, so a redelivered job never moves money twice.
+func (s *TransactionService) settle(tx models.Transaction, e models.JournalEntry) (bool, error) {
+	return s.settleWith(tx, e, nil)
+}
+
+// settleWith is settle with then run in the same DB transaction after posting.
+func (s *TransactionService) settleWith(tx models.Transaction, e models.JournalEntry, then func(ctx context.Context, pgtx pgx.Tx) error) (bool, error) {
+	ctx := context.Background()
+	var applied bool
+	err := s.trx.WithTx(ctx, func(pgtx pgx.Tx) error {
+		ok, err := s.trx.TransitionTx(ctx, pgtx, tx.ID, models.TxnPending, models.TxnCompleted)
+		if err != nil || !ok {
+			applied = false
+			return err
+		}
+		if err := s.recheckLimits(ctx, pgtx, tx); err != nil {
+			return err
+		}
+		if _, err := s.ledger.Post(ctx, pgtx, e); err != nil {
+			return err
+		}
+		if then != nil {
+			if err := then(ctx, pgtx); err != nil {
+				return err
+			}
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// recheckLimits repeats the limit check of tx inside pgtx, before any money
+// moves, so it takes the user's limit lock ahead of any balance row.
+func (s *TransactionService) recheckLimits(ctx context.Context, pgtx pgx.Tx, tx models.Transaction) error {
+	userID := tx.FromUserID
+	if tx.Type == models.TxnCredit {
+		userID = tx.ToUserID
+	}
+	return s.limits.CheckTx(ctx, pgtx, *userID, tx.Type, tx.Currency, tx.ID, tx.Amount)
+}
+
+// enqueue hands a pending transaction to the durable queue. If this fails the
+// queue's recovery sweep picks the transaction up later.
+func (s *TransactionService) enqueue(tx models.Transaction) {
+	if err := s.q.Enqueue(context.Background(), string(tx.Type), tx.ID); err != nil {
+		slog.Error("enqueue transaction", "id", tx.ID, "type", tx.Type, "err", err)
+	}
+}
+
+// handleJob is the worker.Handler for credit and debit jobs.
+func (s *TransactionService) handleJob(_ context.Context, j models.Job) error {
+	if j.TransactionID == nil {
+		return nil
+	}
+	tx, err := s.trx.GetByID(*j.TransactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != models.TxnPending {
+		return nil
+	}
+	switch tx.Type {
+	case models.TxnCredit:
+		err = s.processCredit(tx)
+	case models.TxnDebit:
+		err = s.processDebit(tx)
+	default:
+		return fmt.Errorf("unexpected transaction type %s", tx.Type)
+	}
+	if err != nil && j.Exhausted() {
+		metrics.TransactionsFailed.Inc()
+		_ = s.updateStatus(tx.ID, models.TxnFailed, "retries exhausted: "+err.Error())
+	}
+	return err
+}
+
+// jobExpired records a transaction the queue failed because its job's last
+// attempt was abandoned mid-run.
+func (s *TransactionService) jobExpired(_ context.Context, txID string) {
+	metrics.TransactionsFailed.Inc()
+	s.audit(txID, "status_change", fmt.Sprintf("%s: retries exhausted: lease expired on the last attempt", models.TxnFailed))
+}
+
 // CREDIT 
 
-func (s *TransactionService) Credit(userID string, amount int64) (models.Transaction, error) {
-	return s.CreditIdem(userID, amount, "")
+// Credit adds money to one of userID's wallets; an empty walletID is the main wallet.
+func (s *TransactionService) Credit(userID, walletID string, amount int64, cur string, d models.TxnDetails) (models.Transaction, error) {
+	return s.CreditIdem(userID, walletID, amount, cur, "", d)
 }
 
-func (s *TransactionService) CreditIdem(userID string, amount int64, idemKey string) (models.Transaction, error) {
+func (s *TransactionService) CreditIdem(userID, walletID string, amount int64, cur, idemKey string, d models.TxnDetails) (models.Transaction, error) {
 	if amount <= 0 {
 		return models.Transaction{}, errors.New("amount must be > 0")
 	}
-	if idemKey != "" {
-		if v, ok := s.idem.Load(idemKey); ok {
-			return s.trx.GetByID(v.(string))
-		}
+	if err := d.Validate(); err != nil {
+		return models.Transaction{}, err
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if tx, ok := s.existing(idemKey); ok {
+		return tx, nil
+	}
+	if err := s.checkAccounts("", userID); err != nil {
+		return models.Transaction{}, err
+	}
+	wallet, err := s.walletOf(userID, walletID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.limits.Check(context.Background(), userID, models.TxnCredit, cur, amount); err != nil {
+		return models.Transaction{}, err
 	}
 
 	tx := models.Transaction{
-		Amount:   amount,
-		Type:     models.TxnCredit,
-		Status:   models.TxnPending,
-		ToUserID: &userID,
+		Amount:     amount,
+		Currency:   cur,
+		Type:       models.TxnCredit,
+		Status:     models.TxnPending,
+		ToUserID:   &userID,
+		ToWalletID: wallet,
+
+		TxnDetails: d,
 	}
 	if idemKey != "" {
 		tx.IdempotencyKey = &idemKey
@@ -94,19 +275,8 @@ func (s *TransactionService) CreditIdem(userID string, amount int64, idemKey str
 		return models.Transaction{}, err
 	}
 	s.audit(created.ID, "created", "credit created")
-	if idemKey != "" {
-		s.idem.Store(idemKey, created.ID)
-	}
 
-	s.wp.Submit(func() {
-		metrics.WorkerQueueDepth.Inc()
-		defer metrics.WorkerQueueDepth.Dec()
-		if err := s.processCredit(created); err != nil {
-			metrics.TransactionsFailed.Inc()
-			return
-		}
-		metrics.TransactionsTotal.WithLabelValues("credit").Inc()
-	})
+	s.enqueue(created)
 
 	return created, nil
 }
@@ -115,44 +285,79 @@ func (s *TransactionService) processCredit(tx models.Transaction) error {
 	if tx.ToUserID == nil {
 		return s.updateStatus(tx.ID, models.TxnFailed, "missing to user")
 	}
-	if err := s.getOrCreateBalance(*tx.ToUserID); err != nil {
-		_ = s.updateStatus(tx.ID, models.TxnFailed, "getOrCreate balance failed")
+	if err := s.getOrCreateBalance(walletKey(*tx.ToUserID, tx.ToWalletID), tx.Currency); err != nil {
 		return err
 	}
-	if _, err := s.bal.UpdateAmount(*tx.ToUserID, tx.Amount); err != nil {
-		_ = s.updateStatus(tx.ID, models.TxnFailed, "credit update failed")
+	debit, credit := entryCodes(tx)
+	entry := models.NewTransferEntry(tx.ID, "credit", debit, credit, tx.Amount)
+	applied, err := s.settle(tx, entry)
+	var limitErr *LimitExceededError
+	if errors.As(err, &limitErr) {
+		// not retryable
+		metrics.TransactionsFailed.Inc()
+		return s.updateStatus(tx.ID, models.TxnFailed, limitErr.Error())
+	}
+	if err != nil {
 		return err
 	}
-	return s.updateStatus(tx.ID, models.TxnCompleted, "credit applied")
+	if applied {
+		s.audit(tx.ID, "status_change", "completed: credit applied")
+		metrics.TransactionsTotal.WithLabelValues("credit").Inc()
+	}
+	return nil
 }
 
 // DEBIT 
 
-func (s *TransactionService) Debit(userID string, amount int64) (models.Transaction, error) {
-	return s.DebitIdem(userID, amount, "")
+func (s *TransactionService) Debit(userID string, amount int64, cur string, d models.TxnDetails) (models.Transaction, error) {
+	return s.DebitIdem(userID, amount, cur, "", d)
 }
 
-func (s *TransactionService) DebitIdem(userID string, amount int64, idemKey string) (models.Transaction, error) {
+func (s *TransactionService) DebitIdem(userID string, amount int64, cur, idemKey string, d models.TxnDetails) (models.Transaction, error) {
 	if amount <= 0 {
 		return models.Transaction{}, errors.New("amount must be > 0")
 	}
-	if idemKey != "" {
-		if v, ok := s.idem.Load(idemKey); ok {
-			return s.trx.GetByID(v.(string))
-		}
+	if err := d.Validate(); err != nil {
+		return models.Transaction{}, err
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if tx, ok := s.existing(idemKey); ok {
+		return tx, nil
 	}
-	if err := s.getOrCreateBalance(userID); err != nil {
+	if err := s.checkAccounts(userID, ""); err != nil {
 		return models.Transaction{}, err
 	}
-	if b, err := s.bal.Get(userID); err == nil && b.Amount < amount {
+	if err := s.limits.Check(context.Background(), userID, models.TxnDebit, cur, amount); err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.getOrCreateBalance(userID, cur); err != nil {
+		return models.Transaction{}, err
+	}
+	if held, ok, err := s.screenOrHold(models.Transaction{
+		Amount: amount, Currency: cur, Type: models.TxnDebit, FromUserID: &userID,
+		IdempotencyKey: optional(idemKey), TxnDetails: d,
+	}); err != nil || ok {
+		return held, err
+	}
+	quote, err := s.fees.Quote(userID, models.TxnDebit, amount, cur)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if b, err := s.bal.Get(userID, cur); err == nil && b.Available < quote.Total {
 		return models.Transaction{}, errors.New("insufficient balance")
 	}
 
 	tx := models.Transaction{
 		Amount:     amount,
+		Currency:   cur,
 		Type:       models.TxnDebit,
 		Status:     models.TxnPending,
 		FromUserID: &userID,
+
+		TxnDetails: d,
 	}
 	if idemKey != "" {
 		tx.IdempotencyKey = &idemKey
@@ -163,19 +368,8 @@ func (s *TransactionService) DebitIdem(userID string, amount int64, idemKey stri
 		return models.Transaction{}, err
 	}
 	s.audit(created.ID, "created", "debit created")
-	if idemKey != "" {
-		s.idem.Store(idemKey, created.ID)
-	}
 
-	s.wp.Submit(func() {
-		metrics.WorkerQueueDepth.Inc()
-		defer metrics.WorkerQueueDepth.Dec()
-		if err := s.processDebit(created); err != nil {
-			metrics.TransactionsFailed.Inc()
-			return
-		}
-		metrics.TransactionsTotal.WithLabelValues("debit").Inc()
-	})
+	s.enqueue(created)
 
 	return created, nil
 }
@@ -184,24 +378,67 @@ func (s *TransactionService) processDebit(tx models.Transaction) error {
 	if tx.FromUserID == nil {
 		return s.updateStatus(tx.ID, models.TxnFailed, "missing from user")
 	}
-	if _, err := s.bal.UpdateAmount(*tx.FromUserID, -tx.Amount); err != nil {
-		_ = s.updateStatus(tx.ID, models.TxnFailed, "debit update failed")
+	// priced at settlement: the fee is charged in the same DB transaction as the debit
+	quote, err := s.fees.Quote(*tx.FromUserID, models.TxnDebit, tx.Amount, tx.Currency)
+	if err != nil {
+		return err
+	}
+	debit, credit := entryCodes(tx)
+	entry := models.NewTransferEntry(tx.ID, "debit", debit, credit, tx.Amount)
+	var chargeFee func(context.Context, pgx.Tx) error
+	if quote.Fee > 0 {
+		chargeFee = func(ctx context.Context, pgtx pgx.Tx) error {
+			_, err := s.chargeFee(ctx, pgtx, *tx.FromUserID, quote.Fee, tx)
+			return err
+		}
+	}
+	applied, err := s.settleWith(tx, entry, chargeFee)
+	if errors.Is(err, repo.ErrInsufficientFunds) {
+		// not retryable
+		metrics.TransactionsFailed.Inc()
+		return s.updateStatus(tx.ID, models.TxnFailed, "insufficient balance")
+	}
+	var limitErr *LimitExceededError
+	if errors.As(err, &limitErr) {
+		metrics.TransactionsFailed.Inc()
+		return s.updateStatus(tx.ID, models.TxnFailed, limitErr.Error())
+	}
+	if err != nil {
 		return err
 	}
-	return s.updateStatus(tx.ID, models.TxnCompleted, "debit applied")
+	if applied {
+		s.audit(tx.ID, "status_change", "completed: debit applied")
+		metrics.TransactionsTotal.WithLabelValues("debit").Inc()
+	}
+	return nil
 }
 
 //  TRANSFER 
 
 
-func (s *TransactionService) Transfer(fromID, toID string, amount int64) (models.Transaction, error) {
-	return s.TransferIdem(fromID, toID, amount, "")
+// Transfer sends money from fromID's main wallet to one of toID's wallets; an
+// empty toWalletID is the recipient's main wallet.
+func (s *TransactionService) Transfer(fromID, toID, toWalletID string, amount int64, cur string, d models.TxnDetails) (models.Transaction, error) {
+	return s.TransferIdem(fromID, toID, toWalletID, amount, cur, "", d)
+}
+
+func (s *TransactionService) TransferIdem(fromID, toID, toWalletID string, amount int64, cur, idemKey string, d models.TxnDetails) (models.Transaction, error) {
+	return s.transferAs(fromID, fromID, toID, toWalletID, amount, cur, idemKey, d)
 }
 
-func (s *TransactionService) TransferIdem(fromID, toID string, amount int64, idemKey string) (models.Transaction, error) {
+// transferAs is TransferIdem made by actorID, who is recorded as the transfer's
+// creator; it differs from fromID when a member sends for an organization.
+func (s *TransactionService) transferAs(actorID, fromID, toID, toWalletID string, amount int64, cur, idemKey string, d models.TxnDetails) (models.Transaction, error) {
 	if amount <= 0 {
 		return models.Transaction{}, errors.New("amount must be > 0")
 	}
+	if err := d.Validate(); err != nil {
+		return models.Transaction{}, err
+	}
+	cur, err := currencyCode(cur)
+	if err != nil {
+		return models.Transaction{}, err
+	}
 	if fromID == toID {
 		return models.Transaction{}, errors.New("cannot transfer to self")
 	}
@@ -217,30 +454,56 @@ if !exists {
 
 
 	// Idempotency
-	if idemKey != "" {
-		if v, ok := s.idem.Load(idemKey); ok {
-			return s.trx.GetByID(v.(string))
-		}
+	if tx, ok := s.existing(idemKey); ok {
+		return tx, nil
+	}
+	if err := s.checkAccounts(fromID, toID); err != nil {
+		return models.Transaction{}, err
+	}
+	toWallet, err := s.walletOf(toID, toWalletID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.limits.Check(context.Background(), fromID, models.TxnTransfer, cur, amount); err != nil {
+		return models.Transaction{}, err
 	}
 
 	// Balans kayıtları
-	if err := s.getOrCreateBalance(fromID); err != nil {
+	if err := s.getOrCreateBalance(fromID, cur); err != nil {
+		return models.Transaction{}, err
+	}
+	if err := s.getOrCreateBalance(walletKey(toID, toWallet), cur); err != nil {
 		return models.Transaction{}, err
 	}
-	if err := s.getOrCreateBalance(toID); err != nil {
+	if held, ok, err := s.screenOrHold(models.Transaction{
+		Amount: amount, Currency: cur, Type: models.TxnTransfer, FromUserID: &fromID, ToUserID: &toID, ToWalletID: toWallet,
+		IdempotencyKey: optional(idemKey), CreatedBy: &actorID, TxnDetails: d,
+	}); err != nil || ok {
+		return held, err
+	}
+	if s.needsApproval(amount) {
+		return s.requestApproval(actorID, fromID, toID, toWallet, amount, cur, idemKey, d)
+	}
+	quote, err := s.fees.Quote(fromID, models.TxnTransfer, amount, cur)
+	if err != nil {
 		return models.Transaction{}, err
 	}
-	if b, err := s.bal.Get(fromID); err == nil && b.Amount < amount {
+	if b, err := s.bal.Get(fromID, cur); err == nil && b.Available < quote.Total {
 		return models.Transaction{}, errors.New("insufficient balance")
 	}
 
 	// Pending transaction
 	txModel := models.Transaction{
 		Amount:     amount,
+		Currency:   cur,
 		Type:       models.TxnTransfer,
 		Status:     models.TxnPending,
 		FromUserID: &fromID,
 		ToUserID:   &toID,
+		ToWalletID: toWallet,
+		CreatedBy:  &actorID,
+
+		TxnDetails: d,
 	}
 	if idemKey != "" {
 		txModel.IdempotencyKey = &idemKey
@@ -251,44 +514,52 @@ if !exists {
 		return models.Transaction{}, err
 	}
 	s.audit(created.ID, "created", "transfer created")
-	if idemKey != "" {
-		s.idem.Store(idemKey, created.ID)
-	}
 
-	
-	err = s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error {
-		tag, err := pgtx.Exec(context.Background(),
-			`UPDATE balances
-             SET amount = amount - $1, last_updated_at = now()
-             WHERE user_id = $2 AND amount >= $1`,
-			amount, fromID,
-		)
+	return s.applyTransfer(created, models.TxnPending, quote.Fee, nil)
+}
+
+// applyTransfer moves the money of a created transfer and completes it, if it is
+// still in status from and still within the sender's limits; also, if set, runs
+// in the same DB transaction. When that fails it is rolled back, unless it had
+// already left from, i.e. someone else settled it.
+func (s *TransactionService) applyTransfer(created models.Transaction, from models.TransactionStatus, fee int64,
+	also func(context.Context, pgx.Tx) error) (models.Transaction, error) {
+	fromID := *created.FromUserID
+	err := s.trx.WithTx(context.Background(), func(pgtx pgx.Tx) error {
+		ok, err := s.trx.TransitionTx(context.Background(), pgtx, created.ID, from, models.TxnCompleted)
 		if err != nil {
 			return err
 		}
-		if tag.RowsAffected() == 0 {
-			return errors.New("insufficient balance")
+		if !ok {
+			return errNotInStatus
 		}
-
-		if _, err := pgtx.Exec(context.Background(),
-			`UPDATE balances
-             SET amount = amount + $1, last_updated_at = now()
-             WHERE user_id = $2`,
-			amount, toID,
-		); err != nil {
+		if err := s.recheckLimits(context.Background(), pgtx, created); err != nil {
 			return err
 		}
-
-		if _, err := pgtx.Exec(context.Background(),
-			`UPDATE transactions SET status = 'completed' WHERE id = $1`,
-			created.ID,
-		); err != nil {
+		if also != nil {
+			if err := also(context.Background(), pgtx); err != nil {
+				return err
+			}
+		}
+		debit, credit := entryCodes(created)
+		entry := models.NewTransferEntry(created.ID, "transfer", debit, credit, created.Amount)
+		if _, err := s.ledger.Post(context.Background(), pgtx, entry); err != nil {
 			return err
 		}
+		if fee > 0 {
+			if _, err := s.chargeFee(context.Background(), pgtx, fromID, fee, created); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
+	if errors.Is(err, errNotInStatus) {
+		return models.Transaction{}, err
+	}
 	if err != nil {
 		_ = s.trx.UpdateStatus(created.ID, models.TxnRolledBack)
+		// nothing moved, so a retry with the same key must be able to run again
+		_ = s.trx.ClearIdempotencyKey(created.ID)
 		s.audit(created.ID, "status_change", fmt.Sprintf("%s: %s", models.TxnRolledBack, err.Error()))
 		metrics.TransactionsFailed.Inc()
 		return models.Transaction{}, err
@@ -305,7 +576,3 @@ if !exists {
 func (s *TransactionService) GetByID(id string) (models.Transaction, error) {
 	return s.trx.GetByID(id)
 }
-
-func (s *TransactionService) ListByUser(userID string, limit, offset int) ([]models.Transaction, error) {
-	return s.trx.ListByUser(userID, limit, offset)
-}
@@ -8,14 +8,20 @@ import (
 	"github.com/baharkarakas/insider-backend/internal/config"
 	"github.com/baharkarakas/insider-backend/internal/models"
 	repo "github.com/baharkarakas/insider-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type UserService struct {
-	r repo.Users
-	c config.Config
+	r   repo.Users
+	log repo.AuditLogs
+	c   config.Config
 }
 
-func NewUserService(r repo.Users, c config.Config) *UserService { return &UserService{r: r, c: c} }
+func NewUserService(r repo.Users, l repo.AuditLogs, c config.Config) *UserService {
+	return &UserService{r: r, log: l, c: c}
+}
 
 func (s *UserService) Register(username, email, password string) (models.User, error) {
 	u := models.User{Username: strings.TrimSpace(username), Email: strings.TrimSpace(email), Role: "user"}
@@ -50,3 +56,12 @@ func (s *UserService) GetByEmailAndPassword(email, password string) (models.User
 
 	return u, nil
 }
+
+// GetByID returns ErrUserNotFound for an unknown id.
+func (s *UserService) GetByID(id string) (models.User, error) {
+	u, err := s.r.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	return u, err
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetState freezes, closes or reactivates a user's account. Every change is
+// audited against the user, including the reason and who made it.
+// Admin only; enforced by the router.
+func (s *UserService) SetState(userID, actorID string, c models.AccountStateChange) (models.User, error) {
+	if err := c.Validate(); err != nil {
+		return models.User{}, err
+	}
+	before, after, err := s.r.SetState(context.Background(), userID, c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	det := map[string]any{"from": before.State, "to": after.State, "actor_id": actorID}
+	if c.Reason != "" {
+		det["reason"] = c.Reason
+	}
+	if c.Until != nil {
+		det["until"] = *c.Until
+	}
+	_ = s.log.Create(models.AuditLog{
+		EntityType: "user",
+		EntityID:   &userID,
+		Action:     "state_change",
+		Details:    det,
+	})
+	return after, nil
+}
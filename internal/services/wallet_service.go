@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// WalletService manages a user's wallets; moving money between them is
+// TransactionService.Move.
+type WalletService struct {
+	w repo.Wallets
+}
+
+func NewWalletService(w repo.Wallets) *WalletService { return &WalletService{w: w} }
+
+// Create opens a savings or custom wallet for userID; it starts empty in every currency.
+func (s *WalletService) Create(userID string, w models.Wallet) (models.Wallet, error) {
+	if err := w.Validate(); err != nil {
+		return models.Wallet{}, err
+	}
+	w.ID, w.UserID = "", userID
+	return s.w.Create(context.Background(), w)
+}
+
+// List returns the user's wallets, main first.
+func (s *WalletService) List(userID string) ([]models.Wallet, error) {
+	return s.w.ListByUser(context.Background(), userID)
+}
@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+// camtWriter writes an ISO 20022 camt.053.001.02 bank-to-customer statement with
+// OPBD/CLBD balances. The running balance goes into AddtlNtryInf.
+type camtWriter struct {
+	w *bufio.Writer
+	h Header
+}
+
+func newCAMT053(w io.Writer) *camtWriter { return &camtWriter{w: bufio.NewWriter(w)} }
+
+func isoTime(t time.Time) string { return t.UTC().Format("2006-01-02T15:04:05Z") }
+
+// amt splits a signed amount into the unsigned value and its CRDT/DBIT indicator.
+func (c *camtWriter) amt(v int64) (string, string) {
+	if v < 0 {
+		return c.h.Currency.Format(-v), "DBIT"
+	}
+	return c.h.Currency.Format(v), "CRDT"
+}
+
+func (c *camtWriter) bal(code string, v int64, at time.Time) string {
+	a, ind := c.amt(v)
+	return fmt.Sprintf(`<Bal><Tp><CdOrPrtry><Cd>%s</Cd></CdOrPrtry></Tp><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd><Dt><DtTm>%s</DtTm></Dt></Bal>`,
+		code, c.h.Currency.Code, a, ind, isoTime(at))
+}
+
+func (c *camtWriter) Begin(h Header) error {
+	c.h = h
+	_, err := fmt.Fprintf(c.w, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt>
+<GrpHdr><MsgId>STMT%s</MsgId><CreDtTm>%s</CreDtTm></GrpHdr>
+<Stmt>
+<Id>%s</Id><CreDtTm>%s</CreDtTm>
+<FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>
+<Acct><Id><Othr><Id>%s</Id></Othr></Id><Ccy>%s</Ccy></Acct>
+%s
+%s
+`, h.GeneratedAt.UTC().Format("20060102150405"), isoTime(h.GeneratedAt),
+		esc(h.ID()), isoTime(h.GeneratedAt), isoTime(h.From), isoTime(h.To),
+		esc(h.AccountID), h.Currency.Code,
+		c.bal("OPBD", h.Opening, h.From), c.bal("CLBD", h.Closing, h.To))
+	return err
+}
+
+func (c *camtWriter) Line(l models.StatementLine) error {
+	a, ind := c.amt(l.Delta)
+	rvsl := ""
+	if l.Type == models.TxnReversal {
+		rvsl = "<RvslInd>true</RvslInd>"
+	}
+	_, err := fmt.Fprintf(c.w, `<Ntry><NtryRef>%s</NtryRef><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd>%s<Sts>BOOK</Sts>`+
+		`<BookgDt><DtTm>%s</DtTm></BookgDt><ValDt><DtTm>%s</DtTm></ValDt><AcctSvcrRef>%s</AcctSvcrRef>`+
+		`<BkTxCd><Prtry><Cd>%s</Cd></Prtry></BkTxCd>`+
+		`<NtryDtls><TxDtls><Refs><EndToEndId>%s</EndToEndId></Refs></TxDtls></NtryDtls>`+
+		`<AddtlNtryInf>%s</AddtlNtryInf></Ntry>`+"\n",
+		camtRef(l.ID), c.h.Currency.Code, a, ind, rvsl,
+		isoTime(l.BookedAt), isoTime(l.CreatedAt), camtRef(l.ID),
+		strings.ToUpper(string(l.Type)), endToEnd(l),
+		esc(c.info(l)))
+	return err
+}
+
+// camtRef fits a transaction UUID into the 35-character Max35Text references.
+func camtRef(id string) string { return esc(strings.ReplaceAll(id, "-", "")) }
+
+// endToEnd is the client's own reference when there is one.
+func endToEnd(l models.StatementLine) string {
+	if l.ExternalReference != nil {
+		return esc(truncate(*l.ExternalReference, 35))
+	}
+	return camtRef(l.ID)
+}
+
+func (c *camtWriter) info(l models.StatementLine) string {
+	s := string(l.Type)
+	if l.Description != nil {
+		s = *l.Description + "; " + s
+	}
+	if cp := l.Counterparty(); cp != "" {
+		s += " " + cp
+	}
+	return s + "; balance " + c.h.Currency.Format(l.Balance)
+}
+
+func (c *camtWriter) End() error {
+	if _, err := io.WriteString(c.w, "</Stmt>\n</BkToCstmrStmt>\n</Document>\n"); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
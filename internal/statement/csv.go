@@ -0,0 +1,56 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+// csvWriter writes one row per line, framed by opening and closing balance rows.
+// Amounts are signed decimals in the statement currency.
+type csvWriter struct {
+	w *csv.Writer
+	h Header
+}
+
+func newCSV(w io.Writer) *csvWriter { return &csvWriter{w: csv.NewWriter(w)} }
+
+func (c *csvWriter) Begin(h Header) error {
+	c.h = h
+	if err := c.w.Write([]string{"booked_at", "transaction_id", "type", "status", "counterparty", "description", "external_reference", "amount", "currency", "balance"}); err != nil {
+		return err
+	}
+	return c.w.Write([]string{h.From.UTC().Format(time.RFC3339), "", "opening_balance", "", "", "", "", "", h.Currency.Code, h.Currency.Format(h.Opening)})
+}
+
+func (c *csvWriter) Line(l models.StatementLine) error {
+	return c.w.Write([]string{
+		l.BookedAt.UTC().Format(time.RFC3339Nano),
+		l.ID,
+		string(l.Type),
+		string(l.Status),
+		l.Counterparty(),
+		deref(l.Description),
+		deref(l.ExternalReference),
+		c.h.Currency.Format(l.Delta),
+		c.h.Currency.Code,
+		c.h.Currency.Format(l.Balance),
+	})
+}
+
+func (c *csvWriter) End() error {
+	if err := c.w.Write([]string{c.h.To.UTC().Format(time.RFC3339), "", "closing_balance", "", "", "", "", "", c.h.Currency.Code, c.h.Currency.Format(c.h.Closing)}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
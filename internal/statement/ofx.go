@@ -0,0 +1,86 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+// ofxWriter writes OFX 2.2 (XML). OFX has no opening balance or per-transaction
+// balance elements, so the running balance goes into MEMO and the closing
+// balance into LEDGERBAL.
+type ofxWriter struct {
+	w *bufio.Writer
+	h Header
+}
+
+func newOFX(w io.Writer) *ofxWriter { return &ofxWriter{w: bufio.NewWriter(w)} }
+
+func ofxTime(t time.Time) string { return t.UTC().Format("20060102150405.000") + "[0:UTC]" }
+
+func (o *ofxWriter) Begin(h Header) error {
+	o.h = h
+	_, err := fmt.Fprintf(o.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>%s</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>INSIDER</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(h.GeneratedAt), esc(h.ID()), h.Currency.Code, esc(h.AccountID), ofxTime(h.From), ofxTime(h.To))
+	return err
+}
+
+func (o *ofxWriter) Line(l models.StatementLine) error {
+	typ := "CREDIT"
+	if l.Delta < 0 {
+		typ = "DEBIT"
+	}
+	switch l.Type {
+	case models.TxnTransfer:
+		typ = "XFER"
+	case models.TxnInterest:
+		typ = "INT"
+	}
+	name := l.Counterparty()
+	if name == "" {
+		name = string(l.Type)
+	}
+	memo := string(l.Type) + "; balance " + o.h.Currency.Format(l.Balance)
+	if l.Description != nil {
+		memo = *l.Description + "; " + memo
+	}
+	_, err := fmt.Fprintf(o.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		typ, ofxTime(l.BookedAt), o.h.Currency.Format(l.Delta), esc(l.ID), esc(truncate(name, 32)), esc(memo))
+	return err
+}
+
+func (o *ofxWriter) End() error {
+	if _, err := fmt.Fprintf(o.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, o.h.Currency.Format(o.h.Closing), ofxTime(o.h.To)); err != nil {
+		return err
+	}
+	return o.w.Flush()
+}
+
+func esc(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+// Package statement renders account statements for bookkeeping tools.
+// Writers stream: Begin, then one Line per balance change, then End.
+package statement
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/currency"
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatOFX     Format = "ofx"
+	FormatCAMT053 Format = "camt053"
+)
+
+var ErrUnknownFormat = errors.New("format must be one of csv, ofx, camt053")
+
+// Header describes the statement as a whole. To is exclusive.
+type Header struct {
+	AccountID   string
+	Currency    currency.Currency
+	From, To    time.Time
+	Opening     int64
+	Closing     int64
+	GeneratedAt time.Time
+}
+
+// ID identifies the statement, e.g. "USD-20250101-20250201".
+func (h Header) ID() string {
+	return h.Currency.Code + "-" + h.From.UTC().Format("20060102") + "-" + h.To.UTC().Format("20060102")
+}
+
+type Writer interface {
+	Begin(h Header) error
+	Line(l models.StatementLine) error
+	End() error
+}
+
+// New returns a writer for f that writes to w.
+func New(f Format, w io.Writer) (Writer, error) {
+	switch f {
+	case FormatCSV:
+		return newCSV(w), nil
+	case FormatOFX:
+		return newOFX(w), nil
+	case FormatCAMT053:
+		return newCAMT053(w), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "application/xml; charset=utf-8"
+	}
+}
+
+// Filename is the download name for a statement with the given ID.
+func (f Format) Filename(id string) string {
+	ext := string(f)
+	if f == FormatCAMT053 {
+		ext = "xml"
+	}
+	return "statement-" + id + "." + ext
+}
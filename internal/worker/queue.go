@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/metrics"
+	"github.com/baharkarakas/insider-backend/internal/models"
+	repo "github.com/baharkarakas/insider-backend/internal/repository"
+)
+
+// Handler processes one claimed job. A non-nil error schedules a retry.
+type Handler func(ctx context.Context, job models.Job) error
+
+// Queue runs workers over the Postgres-backed jobs table. Jobs survive restarts
+// and are claimed with SKIP LOCKED, so several replicas can share the table.
+type Queue struct {
+	jobs     repo.Jobs
+	id       string
+	n        int
+	handlers map[string]Handler
+	recover  []string
+	expired  func(ctx context.Context, txnID string)
+
+	PollInterval     time.Duration
+	Lease            time.Duration
+	RecoveryInterval time.Duration
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func NewQueue(jobs repo.Jobs, n int) *Queue {
+	host, _ := os.Hostname()
+	return &Queue{
+		jobs:             jobs,
+		id:               fmt.Sprintf("%s-%d", host, os.Getpid()),
+		n:                n,
+		handlers:         map[string]Handler{},
+		PollInterval:     time.Second,
+		Lease:            time.Minute,
+		RecoveryInterval: time.Minute,
+	}
+}
+
+// Handle registers h for jobs of the given kind. Call before Start.
+func (q *Queue) Handle(kind string, h Handler) { q.handlers[kind] = h }
+
+func (q *Queue) Enqueue(ctx context.Context, kind, txnID string) error {
+	return q.jobs.Enqueue(ctx, kind, txnID)
+}
+
+// RecoverPending makes Start re-queue pending transactions of the given kinds
+// that have no job (crash or deploy between insert and enqueue).
+func (q *Queue) RecoverPending(kinds ...string) { q.recover = append(q.recover, kinds...) }
+
+// OnExpired registers f to run for every transaction the recovery sweep failed
+// because its job's last attempt never finished. The transaction is already
+// failed; f only reports it. Call before Start.
+func (q *Queue) OnExpired(f func(ctx context.Context, txnID string)) { q.expired = f }
+
+// Start runs the recovery pass once, then the workers and a periodic recovery
+// sweep. The sweep also fails jobs that ran out of attempts while abandoned.
+func (q *Queue) Start(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+	q.recoverPending(ctx)
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		t := time.NewTicker(q.RecoveryInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				q.recoverPending(ctx)
+			}
+		}
+	}()
+
+	for i := 0; i < q.n; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.loop(ctx)
+		}()
+	}
+}
+
+// Stop waits for in-flight jobs to finish.
+func (q *Queue) Stop() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.wg.Wait()
+}
+
+func (q *Queue) recoverPending(ctx context.Context) {
+	if ids, err := q.jobs.FailExpired(ctx, q.Lease); err != nil {
+		slog.Error("job recovery: fail exhausted", "err", err)
+	} else if len(ids) > 0 {
+		slog.Warn("job recovery: failed transactions whose last attempt never finished", "count", len(ids))
+		for _, id := range ids {
+			if q.expired != nil {
+				q.expired(ctx, id)
+			}
+		}
+	}
+	if len(q.recover) == 0 {
+		return
+	}
+	n, err := q.jobs.EnqueuePending(ctx, q.recover)
+	if err != nil {
+		slog.Error("job recovery", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("job recovery: re-queued pending transactions", "count", n)
+	}
+}
+
+func (q *Queue) loop(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		jobs, err := q.jobs.Claim(ctx, q.id, 1, q.Lease)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("job claim", "err", err)
+		}
+		if len(jobs) == 0 {
+			if n, err := q.jobs.CountQueued(ctx); err == nil {
+				metrics.WorkerQueueDepth.Set(float64(n))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.PollInterval):
+			}
+			continue
+		}
+		for _, j := range jobs {
+			q.run(j)
+		}
+	}
+}
+
+// run uses a fresh context so shutdown doesn't abort a job halfway.
+func (q *Queue) run(j models.Job) {
+	ctx := context.Background()
+	h, ok := q.handlers[j.Kind]
+	if !ok {
+		_ = q.jobs.Fail(ctx, j.ID, "no handler for kind "+j.Kind, time.Now().Add(q.Lease))
+		return
+	}
+	if err := h(ctx, j); err != nil {
+		slog.Warn("job failed", "id", j.ID, "kind", j.Kind, "attempt", j.Attempts, "err", err)
+		if ferr := q.jobs.Fail(ctx, j.ID, err.Error(), time.Now().Add(backoff(j.Attempts))); ferr != nil {
+			slog.Error("job fail", "id", j.ID, "err", ferr)
+		}
+		return
+	}
+	if err := q.jobs.Complete(ctx, j.ID); err != nil {
+		slog.Error("job complete", "id", j.ID, "err", err)
+	}
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Duration(attempt*attempt) * time.Second
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baharkarakas/insider-backend/internal/models"
+)
+
+// memJobs keeps jobs and their transactions' statuses in memory with the
+// lease rules of the postgres repo, on a clock the test moves.
+type memJobs struct {
+	now  time.Time
+	jobs []*models.Job
+	txns map[string]string
+}
+
+func (m *memJobs) Enqueue(_ context.Context, kind, txnID string) error {
+	for _, j := range m.jobs {
+		if j.Kind == kind && *j.TransactionID == txnID {
+			return nil
+		}
+	}
+	m.jobs = append(m.jobs, &models.Job{ID: int64(len(m.jobs) + 1), Kind: kind, TransactionID: &txnID,
+		Status: models.JobQueued, MaxAttempts: 3, RunAt: m.now})
+	return nil
+}
+
+func (m *memJobs) EnqueuePending(ctx context.Context, types []string) (int64, error) {
+	var n int64
+	for id, status := range m.txns {
+		if status != "pending" {
+			continue
+		}
+		before := len(m.jobs)
+		if err := m.Enqueue(ctx, types[0], id); err != nil {
+			return n, err
+		}
+		n += int64(len(m.jobs) - before)
+	}
+	return n, nil
+}
+
+func (m *memJobs) expired(j *models.Job, lease time.Duration) bool {
+	return j.Status == models.JobRunning && j.LockedAt.Before(m.now.Add(-lease))
+}
+
+func (m *memJobs) Claim(_ context.Context, workerID string, limit int, lease time.Duration) ([]models.Job, error) {
+	var out []models.Job
+	for _, j := range m.jobs {
+		if len(out) == limit {
+			break
+		}
+		due := j.Status == models.JobQueued && !j.RunAt.After(m.now)
+		if !due && !(m.expired(j, lease) && j.Attempts < j.MaxAttempts) {
+			continue
+		}
+		now := m.now
+		j.Status, j.LockedAt, j.LockedBy = models.JobRunning, &now, &workerID
+		j.Attempts++
+		out = append(out, *j)
+	}
+	return out, nil
+}
+
+func (m *memJobs) FailExpired(_ context.Context, lease time.Duration) ([]string, error) {
+	var ids []string
+	for _, j := range m.jobs {
+		if !m.expired(j, lease) || j.Attempts < j.MaxAttempts {
+			continue
+		}
+		j.Status, j.LockedAt, j.LockedBy = models.JobFailed, nil, nil
+		if m.txns[*j.TransactionID] == "pending" {
+			m.txns[*j.TransactionID] = "failed"
+			ids = append(ids, *j.TransactionID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *memJobs) Complete(context.Context, int64) error { return nil }
+
+func (m *memJobs) Fail(context.Context, int64, string, time.Time) error { return nil }
+
+func (m *memJobs) CountQueued(context.Context) (int64, error) { return 0, nil }
+
+func TestRecoveryFailsTransactionWhenLastLeaseExpires(t *testing.T) {
+	ctx := context.Background()
+	m := &memJobs{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), txns: map[string]string{"t1": "pending"}}
+	q := NewQueue(m, 0)
+	q.RecoverPending("credit")
+	var reported []string
+	q.OnExpired(func(_ context.Context, id string) { reported = append(reported, id) })
+	if err := q.Enqueue(ctx, "credit", "t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// every attempt's worker dies mid-run and its lease runs out
+	for attempt := 1; attempt <= 3; attempt++ {
+		jobs, _ := m.Claim(ctx, "w", 1, q.Lease)
+		if len(jobs) != 1 || jobs[0].Attempts != attempt {
+			t.Fatalf("attempt %d: claimed %+v", attempt, jobs)
+		}
+		m.now = m.now.Add(q.Lease + time.Second)
+		q.recoverPending(ctx)
+		if attempt < 3 && (m.txns["t1"] != "pending" || len(reported) > 0) {
+			t.Fatalf("attempt %d: transaction %s, reported %v; want it left for a retry", attempt, m.txns["t1"], reported)
+		}
+	}
+
+	if m.txns["t1"] != "failed" {
+		t.Errorf("transaction is %s, want failed", m.txns["t1"])
+	}
+	if len(reported) != 1 || reported[0] != "t1" {
+		t.Errorf("reported %v, want [t1]", reported)
+	}
+	if jobs, _ := m.Claim(ctx, "w", 1, q.Lease); len(jobs) != 0 {
+		t.Errorf("claimed %+v after the job failed", jobs)
+	}
+	// later sweeps neither requeue nor report it again
+	q.recoverPending(ctx)
+	if len(m.jobs) != 1 || len(reported) != 1 {
+		t.Errorf("after another sweep: %d jobs, reported %v", len(m.jobs), reported)
+	}
+}
+
+func TestRecoveryLeavesLiveLease(t *testing.T) {
+	ctx := context.Background()
+	m := &memJobs{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), txns: map[string]string{"t1": "pending"}}
+	q := NewQueue(m, 0)
+	q.OnExpired(func(_ context.Context, id string) { t.Errorf("reported %s", id) })
+	_ = q.Enqueue(ctx, "credit", "t1")
+	m.jobs[0].MaxAttempts = 1
+	if jobs, _ := m.Claim(ctx, "w", 1, q.Lease); len(jobs) != 1 {
+		t.Fatalf("claimed %+v", jobs)
+	}
+	// exactly at the lease the worker still holds it
+	m.now = m.now.Add(q.Lease)
+	q.recoverPending(ctx)
+	if m.txns["t1"] != "pending" || m.jobs[0].Status != models.JobRunning {
+		t.Errorf("transaction %s, job %s; want pending and running", m.txns["t1"], m.jobs[0].Status)
+	}
+}